@@ -2,350 +2,230 @@ package main
 
 import (
 	"crypto"
-	"crypto/aes"
-	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
-	"crypto/rsa"
-	"crypto/x509"
-	"encoding/base64"
-	"encoding/json"
-	"encoding/pem"
 	"flag"
 	"fmt"
-	"io"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
 	"strings"
+	"time"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/hpke"
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/jws"
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/respsig"
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/rsakey"
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/xwing"
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/logging"
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/router"
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/session"
 )
 
-// AESGCMDecryptFromJS Go 端解密（解析 JS node-forge 加密的密文）
-func AESGCMDecryptFromJS(cipherB64, ivB64 string, key []byte) ([]byte, error) {
-	// 1. 调整密钥长度以匹配前端逻辑
-	keyBytes := make([]byte, len(key))
-	copy(keyBytes, key)
-
-	if len(keyBytes) < 16 {
-		// 填充到16字节
-		padding := make([]byte, 16-len(keyBytes))
-		keyBytes = append(keyBytes, padding...)
-	} else if len(keyBytes) > 32 {
-		// 截断到32字节
-		keyBytes = keyBytes[:32]
-	} else if len(keyBytes) != 16 && len(keyBytes) != 24 && len(keyBytes) != 32 {
-		// 填充到32字节
-		padding := make([]byte, 32-len(keyBytes))
-		keyBytes = append(keyBytes, padding...)
-	}
+func main() {
+	// 解析命令行参数
+	var port = flag.String("port", "8080", "服务器端口")
+	var keyFile = flag.String("rsa-key", "rsa_private_key.pem", "RSA私钥文件路径（不存在时生成并保存）")
+	var previousKeys = flag.String("rsa-previous-keys", "", "已轮换的旧RSA私钥文件，逗号分隔")
+	var rotatedAt = flag.String("rsa-rotated-at", "", "旧密钥的轮换时间（RFC 3339），配置旧密钥时必填")
+	var grace = flag.Duration("rsa-grace", rsakey.DefaultGracePeriod, "旧密钥轮换后仍可解密的宽限期")
+	var responseSigning = flag.String("response-signing", "", "响应签名方式：ed25519、rsa-pss（独立私钥）或 rsa-keyring（复用当前 RSA 解密私钥），留空不签名")
+	var responseSigningKeyFile = flag.String("response-signing-key", "response_signing.pem", "响应签名私钥文件路径：ed25519 方式为 Ed25519、rsa-pss 方式为 RSA 私钥（不存在时生成并保存）")
+	var signingKeyFile = flag.String("signing-key", "signing_ed25519.pem", "Ed25519 文档签名私钥文件路径（不存在时生成并保存）")
+	var xwingKeyFile = flag.String("xwing-key", "xwing_private_key.pem", "X-Wing（X25519 + ML-KEM-768）私钥文件路径（不存在时生成并保存）")
+	var hpkeKeyFile = flag.String("hpke-key", "hpke_x25519.pem", "HPKE 接收方 X25519 私钥文件路径（不存在时生成并保存）")
+	var ecSigningKeyFile = flag.String("ec-signing-key", "signing_ec_p256.pem", "ECDSA P-256 文档签名私钥文件路径（不存在时生成并保存）")
+	var logLevel = flag.String("log-level", "info", "日志级别：debug、info、warn、error（debug 输出处理细节，仍不记录密钥和明文）")
+	var logFormat = flag.String("log-format", "text", "日志格式：text、json")
+	var apiKeysFile = flag.String("api-keys", "", "静态 API 密钥文件（JSON 数组或 id:secret 列表），设置后启用 API 密钥认证")
+	var hmacKeysFile = flag.String("hmac-keys", "", "HMAC 签名密钥文件（格式同 -api-keys），设置后启用 HMAC-SHA256 请求签名认证")
+	var hmacSkew = flag.Duration("hmac-skew", router.DefaultClockSkew, "HMAC 签名时间戳允许的时间偏差")
+	var corsOrigins = flag.String("cors-origins", "*", "允许的跨域来源，逗号分隔：精确 origin、子域通配（https://*.example.com）或 *")
+	var corsHeaders = flag.String("cors-headers", "", "预检允许的请求头，逗号分隔，留空使用默认列表")
+	var corsExpose = flag.String("cors-expose", "", "暴露给浏览器的响应头，逗号分隔，留空使用默认列表")
+	var corsCredentials = flag.Bool("cors-credentials", false, "允许跨域请求携带凭据（不能与 * 同时使用）")
+	var corsMaxAge = flag.Duration("cors-max-age", router.DefaultCORSMaxAge, "预检结果缓存时间")
+	flag.Parse()
 
-	// 2. 解码 Base64
-	cipherTextWithTag, err := base64.StdEncoding.DecodeString(cipherB64)
+	level, err := logging.ParseLevel(*logLevel)
 	if err != nil {
-		return nil, fmt.Errorf("cipher base64 decode failed: %v", err)
+		log.Fatalf("Invalid -log-level: %v", err)
 	}
-
-	iv, err := base64.StdEncoding.DecodeString(ivB64)
+	logger, err := logging.New(os.Stderr, level, *logFormat)
 	if err != nil {
-		return nil, fmt.Errorf("iv base64 decode failed: %v", err)
+		log.Fatalf("Invalid -log-format: %v", err)
 	}
+	// 其余 log.Printf 输出也经过同一个脱敏处理器
+	slog.SetDefault(logger)
 
-	// 3. 校验调整后的密钥长度
-	if len(keyBytes) != 16 && len(keyBytes) != 24 && len(keyBytes) != 32 {
-		return nil, fmt.Errorf("密钥长度必须是16/24/32字节，调整后长度: %d", len(keyBytes))
-	}
-
-	// 4. 创建 AES 区块和 GCM 模式
-	block, err := aes.NewCipher(keyBytes)
-	if err != nil {
-		return nil, err
-	}
-	gcm, err := cipher.NewGCM(block)
+	// 轮换时间必须显式配置，否则每次重启都会重置旧密钥的宽限期
+	var retiredAt time.Time
+	if *rotatedAt != "" {
+		t, err := time.Parse(time.RFC3339, *rotatedAt)
+		if err != nil {
+			log.Fatalf("Invalid -rsa-rotated-at: %v", err)
+		}
+		retiredAt = t
+	}
+
+	// 加载RSA密钥环：当前密钥优先 RSA_PRIVATE_KEY 环境变量，其次私钥文件，首次启动时生成并持久化；
+	// 旧密钥来自 RSA_PREVIOUS_PRIVATE_KEYS 环境变量，PEM 与 JWK 格式均可
+	fmt.Println("Loading RSA key pair...")
+	keyring, err := rsakey.LoadKeyring(
+		[]rsakey.Source{
+			rsakey.Env("RSA_PRIVATE_KEY"),
+			rsakey.File{Path: *keyFile, Generate: true},
+		},
+		[]byte(os.Getenv("RSA_PREVIOUS_PRIVATE_KEYS")),
+		retiredAt,
+		*grace,
+	)
 	if err != nil {
-		return nil, fmt.Errorf("GCM 创建失败: %v", err)
+		log.Fatalf("Failed to load RSA key pair: %v", err)
 	}
-
-	// 4. 校验IV长度（GCM需要12字节）
-	if len(iv) != gcm.NonceSize() {
-		return nil, fmt.Errorf("IV长度必须是%d字节，实际是%d字节", gcm.NonceSize(), len(iv))
+	// -rsa-previous-keys 指定的旧密钥文件逐个解析，每个文件可以是 PEM、JWK 或 JWK Set
+	for _, path := range strings.Split(*previousKeys, ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("Failed to read previous RSA key: %v", err)
+		}
+		keys, err := rsakey.ParsePrivateKeys(data)
+		if err != nil {
+			log.Fatalf("Failed to parse previous RSA key %s: %v", path, err)
+		}
+		if len(keys) > 0 && retiredAt.IsZero() {
+			log.Fatalf("-rsa-rotated-at is required when previous RSA keys are configured")
+		}
+		for _, key := range keys {
+			keyring.AddRetired(key, retiredAt)
+		}
 	}
+	privateKey := keyring.Current().PrivateKey
 
-	// 5. 解密（GCM 自动拆分密文和标签）
-	plainText, err := gcm.Open(nil, iv, cipherTextWithTag, nil)
+	// 导出公钥为PEM格式
+	rsaPublicKey, err := rsakey.PublicKeyPEM(&privateKey.PublicKey)
 	if err != nil {
-		return nil, fmt.Errorf("解密失败：%v", err)
-	}
-
-	return plainText, nil
-}
-
-// AESGCMEncryptForJS Go 端加密（适配 JS node-forge 的 GCM 格式）
-func AESGCMEncryptForJS(plainText []byte, key []byte) (string, string, error) {
-	// 1. 调整密钥长度以匹配前端逻辑
-	keyBytes := make([]byte, len(key))
-	copy(keyBytes, key)
-
-	if len(keyBytes) < 16 {
-		// 填充到16字节
-		padding := make([]byte, 16-len(keyBytes))
-		keyBytes = append(keyBytes, padding...)
-	} else if len(keyBytes) > 32 {
-		// 截断到32字节
-		keyBytes = keyBytes[:32]
-	} else if len(keyBytes) != 16 && len(keyBytes) != 24 && len(keyBytes) != 32 {
-		// 填充到32字节
-		padding := make([]byte, 32-len(keyBytes))
-		keyBytes = append(keyBytes, padding...)
-	}
-
-	// 2. 校验调整后的密钥长度
-	if len(keyBytes) != 16 && len(keyBytes) != 24 && len(keyBytes) != 32 {
-		return "", "", fmt.Errorf("密钥长度必须是16/24/32字节，调整后长度: %d", len(keyBytes))
+		log.Fatalf("Failed to marshal public key: %v", err)
 	}
+	fmt.Println("RSA key pair loaded successfully!")
+	fmt.Printf("RSA Private Key Size: %d bits\n", privateKey.Size()*8)
+	fmt.Printf("RSA Key ID: %s (%d active keys)\n", keyring.Current().ID, len(keyring.Keys()))
+	fmt.Printf("RSA Public Key:\n%s\n", rsaPublicKey)
 
-	// 3. 创建 AES 区块和 GCM 模式
-	block, err := aes.NewCipher(keyBytes)
+	// 混合后量子 KEM 私钥
+	xwingKey, err := xwing.LoadFile(*xwingKeyFile, true)
 	if err != nil {
-		return "", "", err
+		log.Fatalf("Failed to load X-Wing key: %v", err)
 	}
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", "", err
-	}
-
-	// 3. 生成 12 字节 IV（GCM标准）
-	iv := make([]byte, gcm.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
-		return "", "", err
-	}
-
-	// 4. 加密（GCM 自动生成 16 字节认证标签）
-	cipherText := gcm.Seal(nil, iv, plainText, nil) // cipherText = 密文 + 标签
+	fmt.Printf("X-Wing Key ID: %s\n", xwingKey.KeyID())
 
-	// 5. 转 Base64（与 JS 格式统一）
-	return base64.StdEncoding.EncodeToString(cipherText), base64.StdEncoding.EncodeToString(iv), nil
-}
-
-// HTTP请求响应结构
-type ProcessRequest struct {
-	EncryptedData string `json:"encryptedData"` // cipherB64|ivB64
-	Key           string `json:"key"`
-}
-
-type ProcessResponse struct {
-	ProcessedData string `json:"processedData"` // cipherB64|ivB64
-}
-
-type ErrorResponse struct {
-	Error string `json:"error"`
-}
-
-// RSA 密钥对
-var rsaPrivateKey *rsa.PrivateKey
-var rsaPublicKey string
-
-// RSA解密函数
-func rsaDecrypt(encryptedData string) (string, error) {
-	// 解码Base64密文
-	encryptedBytes, err := base64.StdEncoding.DecodeString(encryptedData)
+	// HPKE 接收方私钥
+	hpkeKey, err := hpke.LoadFile(*hpkeKeyFile, true)
 	if err != nil {
-		return "", fmt.Errorf("base64 decode failed: %v", err)
+		log.Fatalf("Failed to load HPKE key: %v", err)
 	}
+	fmt.Printf("HPKE Key ID: %s\n", hpke.KeyID(hpkeKey.PublicKey()))
 
-	// 使用RSA私钥解密
-	decryptedBytes, err := rsaPrivateKey.Decrypt(nil, encryptedBytes, &rsa.OAEPOptions{
-		Hash: crypto.SHA256,
+	// 文档签名密钥：ECDSA P-256 与 Ed25519 私钥首次启动时生成并持久化，RSA 使用上面加载的当前私钥
+	ecSigningKey, err := jws.LoadPrivateKeyFile(*ecSigningKeyFile, func() (crypto.Signer, error) {
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	})
 	if err != nil {
-		return "", fmt.Errorf("RSA decryption failed: %v", err)
+		log.Fatalf("Failed to load EC signing key: %v", err)
 	}
-
-	// 将解密后的字节数组作为UTF-8字符串返回
-	return string(decryptedBytes), nil
-}
-
-func main() {
-	// 解析命令行参数
-	var port = flag.String("port", "8080", "服务器端口")
-	flag.Parse()
-
-	// 生成RSA密钥对
-	fmt.Println("Generating RSA key pair...")
-	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	signingKey, err := respsig.LoadEd25519File(*signingKeyFile, true)
 	if err != nil {
-		log.Fatalf("Failed to generate RSA key pair: %v", err)
+		log.Fatalf("Failed to load signing key: %v", err)
 	}
-	rsaPrivateKey = privateKey
-
-	// 导出公钥为PEM格式
-	publicKeyBytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	// 文档签名：当前 RSA 私钥用于 PS256 / RS256，P-256 用于 ES256，Ed25519 用于 EdDSA
+	signingKeyring, err := jws.NewKeyring(privateKey, ecSigningKey, signingKey)
 	if err != nil {
-		log.Fatalf("Failed to marshal public key: %v", err)
-	}
-	publicKeyPEM := pem.EncodeToMemory(&pem.Block{
-		Type:  "PUBLIC KEY",
-		Bytes: publicKeyBytes,
-	})
-	rsaPublicKey = string(publicKeyPEM)
-	fmt.Println("RSA key pair generated successfully!")
-	fmt.Printf("RSA Private Key Size: %d bits\n", privateKey.Size()*8)
-	fmt.Printf("RSA Public Key:\n%s\n", rsaPublicKey)
-
-	// 设置CORS中间件
-	corsMiddleware := func(next http.HandlerFunc) http.HandlerFunc {
-		return func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-
-			if r.Method == "OPTIONS" {
-				w.WriteHeader(http.StatusOK)
-				return
+		log.Fatalf("Failed to create signing keyring: %v", err)
+	}
+	fmt.Printf("Document signing algorithms: %s\n", strings.Join(signingKeyring.Algorithms(), ", "))
+
+	// 响应签名：ed25519 / rsa-pss 使用独立的私钥文件，不与文档签名共用；rsa-keyring 复用当前 RSA 解密私钥
+	var responseSigner *respsig.Signer
+	if *responseSigning != "" {
+		var key crypto.Signer = privateKey
+		if *responseSigning != respsig.ModeRSAKeyring {
+			key, err = jws.LoadPrivateKeyFile(*responseSigningKeyFile, func() (crypto.Signer, error) {
+				return respsig.GenerateKey(*responseSigning)
+			})
+			if err != nil {
+				log.Fatalf("Failed to load response signing key: %v", err)
 			}
-
-			next(w, r)
-		}
-	}
-
-	// 获取RSA公钥接口
-	http.HandleFunc("/api/rsa/public-key", corsMiddleware(func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != "GET" {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
 		}
-
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]string{
-			"publicKey": rsaPublicKey,
-		})
-	}))
-
-	// RSA解密处理接口
-	http.HandleFunc("/api/rsa/process", corsMiddleware(func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != "POST" {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
+		if responseSigner, err = respsig.NewSignerForMode(*responseSigning, key); err != nil {
+			log.Fatalf("Invalid -response-signing: %v", err)
 		}
-
-		var req struct {
-			EncryptedData string `json:"encryptedData"`
+		if *responseSigning != respsig.ModeRSAKeyring {
+			if _, err := signingKeyring.Lookup(responseSigner.KeyID); err == nil {
+				log.Fatalf("-response-signing-key must differ from the document signing keys")
+			}
 		}
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			log.Printf("JSON decode error: %v", err)
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON"})
-			return
+		if _, err := keyring.Lookup(responseSigner.KeyID); err == nil {
+			logger.Warn("Response signing uses the RSA decryption key; use -response-signing ed25519 or rsa-pss with a dedicated -response-signing-key to keep signing and decryption keys separate", "kid", responseSigner.KeyID)
 		}
+		fmt.Printf("Response signing enabled: alg=%s, kid=%s\n", responseSigner.Alg, responseSigner.KeyID)
+	}
 
-		if req.EncryptedData == "" {
-			log.Printf("Empty encrypted data provided")
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "Encrypted data is required"})
-			return
-		}
+	cors, err := router.NewCORSPolicy(router.CORSConfig{
+		AllowedOrigins:   router.SplitList(*corsOrigins),
+		AllowedHeaders:   router.SplitList(*corsHeaders),
+		ExposedHeaders:   router.SplitList(*corsExpose),
+		AllowCredentials: *corsCredentials,
+		MaxAge:           *corsMaxAge,
+	})
+	if err != nil {
+		log.Fatalf("Invalid CORS configuration: %v", err)
+	}
+	fmt.Printf("CORS allowed origins: %s\n", *corsOrigins)
 
-		// 使用RSA解密函数
-		decryptedData, err := rsaDecrypt(req.EncryptedData)
+	// 请求认证：公钥发布接口之外的接口需要 API 密钥或 HMAC 签名，都未配置时不认证
+	var auths router.Authenticators
+	if *apiKeysFile != "" {
+		keys, err := router.LoadAuthKeysFile(*apiKeysFile)
 		if err != nil {
-			log.Printf("RSA decryption failed: %v", err)
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: fmt.Sprintf("RSA decryption failed: %v", err)})
-			return
+			log.Fatalf("Failed to load API keys: %v", err)
 		}
-
-		log.Printf("RSA decryption successful! Original data: '%s'", decryptedData)
-
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]string{
-			"decryptedData": decryptedData,
-		})
-	}))
-
-	// 处理接口：接收加密内容和密钥，解密后重新加密返回
-	http.HandleFunc("/api/process", corsMiddleware(func(w http.ResponseWriter, r *http.Request) {
-
-		if r.Method != "POST" {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
-
-		var req ProcessRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			log.Printf("JSON decode error: %v", err)
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON"})
-			return
-		}
-
-		// 解析加密数据
-		parts := strings.Split(req.EncryptedData, "|")
-		if len(parts) != 2 {
-			log.Printf("Invalid encrypted data format")
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid encrypted data format"})
-			return
-		}
-
-		cipherB64 := parts[0]
-		ivB64 := parts[1]
-
-		log.Printf("Received request: cipherB64='%s', ivB64='%s', key='%s'",
-			cipherB64, ivB64, req.Key)
-
-		if req.Key == "" {
-			log.Printf("Empty key provided")
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "Key is required"})
-			return
-		}
-
-		if cipherB64 == "" || ivB64 == "" {
-			log.Printf("Empty cipher or IV provided")
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "Cipher and IV are required"})
-			return
-		}
-
-		// 解密接收到的加密内容
-		log.Printf("Starting GCM decryption")
-		decrypted, err := AESGCMDecryptFromJS(cipherB64, ivB64, []byte(req.Key))
+		auths = append(auths, router.NewAPIKeyAuth(keys))
+		fmt.Printf("API key authentication enabled: %d keys\n", len(keys))
+	}
+	if *hmacKeysFile != "" {
+		keys, err := router.LoadAuthKeysFile(*hmacKeysFile)
 		if err != nil {
-			log.Printf("Decryption failed: %v", err)
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: fmt.Sprintf("Decryption failed: %v", err)})
-			return
+			log.Fatalf("Failed to load HMAC keys: %v", err)
 		}
-
-		log.Printf("Decryption successful!")
-		log.Printf("🔓 DECRYPTED CONTENT: '%s' (length: %d bytes)", string(decrypted), len(decrypted))
-
-		// 重新加密解密后的内容
-		reCipherB64, reIVB64, err := AESGCMEncryptForJS(decrypted, []byte(req.Key))
-		if err != nil {
-			log.Printf("Re-encryption failed: %v", err)
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "Re-encryption failed"})
-			return
+		auths = append(auths, router.NewHMACAuth(keys, *hmacSkew))
+		fmt.Printf("HMAC request signing enabled: %d keys, skew=%s\n", len(keys), *hmacSkew)
+	}
+	var auth router.Authenticator
+	if len(auths) > 0 {
+		auth = auths
+		if cors.AllowsAnyOrigin() {
+			logger.Warn("CORS allows any origin while authentication is enabled; set -cors-origins to the sites that hold credentials")
 		}
+	}
 
-		log.Printf("Re-encryption successful")
-
-		// 组合成一个字符串返回
-		processedData := reCipherB64 + "|" + reIVB64
-
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(ProcessResponse{
-			ProcessedData: processedData,
-		})
-	}))
+	// 接口与 Vercel 函数共用同一个路由树；会话密钥存储在单进程部署中使用内存存储即可
+	handler := router.New(router.Deps{
+		RSAKeyring:     router.Value(keyring),
+		SigningKeyring: router.Value(signingKeyring),
+		XWingKey:       router.Value(xwingKey),
+		HPKEKey:        router.Value(hpkeKey),
+		SessionStore:   router.Value[session.Store](session.NewMemoryStore(session.DefaultTTL, session.DefaultMaxSessions)),
+		ResponseSigner: router.Value(responseSigner),
+		Auth:           router.Value(auth),
+		CORS:           cors,
+		Logger:         logger,
+	})
 
 	fmt.Printf("Server starting on :%s...\n", *port)
-	log.Fatal(http.ListenAndServe(":"+*port, nil))
+	log.Fatal(http.ListenAndServe(":"+*port, handler))
 }
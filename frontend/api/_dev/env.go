@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// loadEnvFile 读取 .env 文件并设置环境变量；已存在的环境变量优先，不会被覆盖。
+// 返回设置的变量名
+//
+// 支持的格式：
+//   - KEY=value，可选 export 前缀，# 开头的行为注释
+//   - 未加引号的值去掉首尾空白和 " #" 之后的行内注释
+//   - 单引号内按原样保留
+//   - 双引号内可以跨行（PEM 私钥），支持 \n、\r、\t、\"、\\ 转义
+func loadEnvFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	vars, err := parseEnv(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	var names []string
+	for _, v := range vars {
+		if _, ok := os.LookupEnv(v[0]); ok {
+			continue
+		}
+		os.Setenv(v[0], v[1])
+		names = append(names, v[0])
+	}
+	return names, nil
+}
+
+// parseEnv 解析 .env 内容，按出现顺序返回 [名称, 值]
+func parseEnv(data string) ([][2]string, error) {
+	var vars [][2]string
+	sc := bufio.NewScanner(strings.NewReader(data))
+	sc.Buffer(make([]byte, 64<<10), 1<<20)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text = strings.TrimPrefix(text, "export ")
+		name, value, ok := strings.Cut(text, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("line %d: expected KEY=value", line)
+		}
+		value = strings.TrimLeft(value, " \t")
+
+		switch {
+		case strings.HasPrefix(value, "'"):
+			end := strings.Index(value[1:], "'")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated single quote", line)
+			}
+			value = value[1 : end+1]
+		case strings.HasPrefix(value, `"`):
+			// 双引号值可以跨行，读到未转义的结束引号为止
+			raw := value[1:]
+			for !closedQuote(raw) {
+				if !sc.Scan() {
+					return nil, fmt.Errorf("line %d: unterminated double quote", line)
+				}
+				raw += "\n" + sc.Text()
+			}
+			value = unescape(raw[:closingQuote(raw)])
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = value[:i]
+			}
+			value = strings.TrimSpace(value)
+		}
+		vars = append(vars, [2]string{name, value})
+	}
+	return vars, sc.Err()
+}
+
+// closingQuote 第一个未转义双引号的位置，没有时返回 -1
+func closingQuote(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+func closedQuote(s string) bool {
+	return closingQuote(s) >= 0
+}
+
+// unescape 处理双引号值中的转义
+func unescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"text/template"
+)
+
+// serverTemplate 生成的本地服务器：与 Vercel Go 运行时一样，导入每个函数所在的包并直接调用其入口函数
+var serverTemplate = template.Must(template.New("server").Parse(`// Code generated by _dev; DO NOT EDIT.
+
+package main
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+{{range $i, $pkg := .Imports}}
+	f{{$i}} {{printf "%q" $pkg}}
+{{- end}}
+)
+
+// rewrites vercel.json 中的精确路径重写，函数路由优先
+var rewrites = map[string]string{
+{{- range .Rewrites}}
+	{{printf "%q" .Source}}: {{printf "%q" .Destination}},
+{{- end}}
+}
+
+func main() {
+	mux := http.NewServeMux()
+{{- range .Routes}}
+	mux.HandleFunc({{printf "%q" .Path}}, f{{index $.PkgIndex .Import}}.{{.Func}})
+{{- end}}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pattern := mux.Handler(r); pattern == "" {
+			if dest, ok := rewrites[r.URL.Path]; ok {
+				r.URL = &url.URL{Path: dest, RawQuery: r.URL.RawQuery}
+			}
+		}
+		mux.ServeHTTP(w, r)
+	})
+	log.Fatal(http.ListenAndServe(os.Args[1], handler))
+}
+`))
+
+// generateServer 生成挂载全部函数的 main 包源码
+func generateServer(routes []Route, rewrites []Rewrite) ([]byte, error) {
+	data := struct {
+		Imports  []string
+		PkgIndex map[string]int
+		Routes   []Route
+		Rewrites []Rewrite
+	}{PkgIndex: make(map[string]int), Routes: routes, Rewrites: rewrites}
+	for _, r := range routes {
+		if _, ok := data.PkgIndex[r.Import]; !ok {
+			data.PkgIndex[r.Import] = len(data.Imports)
+			data.Imports = append(data.Imports, r.Import)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := serverTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
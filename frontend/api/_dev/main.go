@@ -0,0 +1,139 @@
+// Command _dev 在本地以 Vercel 的方式运行 frontend/api 下的 Go 函数，无需部署即可走生产代码路径。
+//
+// 它按 Vercel 文件系统路由规则发现各函数文件，生成一个导入这些函数包的 main 包并编译运行，
+// 环境变量从 .env 读取，vercel.json 中的固定路径重写同样生效。在 frontend/api 目录下运行：
+//
+//	go run ./_dev -port 3000
+//
+// 以 _ 开头的目录不会被 Vercel 部署为函数，本命令也不会出现在线上。
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+)
+
+func main() {
+	var port = flag.String("port", "3000", "本地服务端口")
+	var dir = flag.String("dir", ".", "函数目录（frontend/api，需包含 go.mod）")
+	var envFile = flag.String("env", "../.env", ".env 文件路径，不存在时跳过；已设置的环境变量优先")
+	var vercelJSON = flag.String("vercel-json", "../vercel.json", "vercel.json 路径，不存在时跳过")
+	flag.Parse()
+
+	module, err := modulePath(*dir)
+	if err != nil {
+		log.Fatalf("Failed to read module path: %v", err)
+	}
+	routes, err := discoverRoutes(*dir, module)
+	if err != nil {
+		log.Fatalf("Failed to discover functions: %v", err)
+	}
+	if len(routes) == 0 {
+		log.Fatalf("No functions found in %s", *dir)
+	}
+
+	names, err := loadEnvFile(*envFile)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		fmt.Printf("No %s, using the current environment\n", *envFile)
+	case err != nil:
+		log.Fatalf("Failed to load env file: %v", err)
+	default:
+		// 只输出变量名，值中可能有私钥
+		fmt.Printf("Loaded %d variables from %s: %v\n", len(names), *envFile, names)
+	}
+
+	rewrites, skipped, err := loadRewrites(*vercelJSON)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Fatalf("Failed to load vercel.json: %v", err)
+	}
+	if skipped > 0 {
+		fmt.Printf("Skipped %d pattern rewrites in %s (only exact paths are supported)\n", skipped, *vercelJSON)
+	}
+
+	src, err := generateServer(routes, rewrites)
+	if err != nil {
+		log.Fatalf("Failed to generate server: %v", err)
+	}
+	bin, err := build(*dir, src)
+	if err != nil {
+		log.Fatalf("Build failed: %v", err)
+	}
+
+	for _, r := range routes {
+		fmt.Printf("  %-28s -> %s (%s)\n", r.Path, r.File, r.Func)
+	}
+	for _, rw := range rewrites {
+		fmt.Printf("  %-28s -> %s (rewrite)\n", rw.Source, rw.Destination)
+	}
+	fmt.Printf("Vercel dev server starting on :%s...\n", *port)
+
+	code := run(bin, ":"+*port)
+	os.RemoveAll(filepath.Dir(bin))
+	os.Exit(code)
+}
+
+// build 把生成的 main 包写入函数目录下的临时目录（以便解析模块内的导入）并编译，返回可执行文件路径
+func build(dir string, src []byte) (string, error) {
+	genDir, err := os.MkdirTemp(dir, "_devgen")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(genDir)
+	if err := os.WriteFile(filepath.Join(genDir, "main.go"), src, 0o644); err != nil {
+		return "", err
+	}
+
+	binDir, err := os.MkdirTemp("", "vercel-dev")
+	if err != nil {
+		return "", err
+	}
+	bin := filepath.Join(binDir, "server")
+	cmd := exec.Command("go", "build", "-o", bin, "./"+filepath.Base(genDir))
+	cmd.Dir = dir
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		os.RemoveAll(binDir)
+		return "", err
+	}
+	return bin, nil
+}
+
+// run 运行生成的服务器并转发中断信号，返回其退出码
+func run(bin, addr string) int {
+	cmd := exec.Command(bin, addr)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Start(); err != nil {
+		log.Printf("Failed to start server: %v", err)
+		return 1
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		for s := range sig {
+			cmd.Process.Signal(s)
+		}
+	}()
+
+	if err := cmd.Wait(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			if exitErr.Exited() {
+				return exitErr.ExitCode()
+			}
+			// 被信号终止（Ctrl+C）视为正常退出
+			return 0
+		}
+		log.Printf("Server failed: %v", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Route 一个 Vercel Go 函数：源文件、所在包的导入路径、导出的处理函数和对应的 URL
+type Route struct {
+	File   string // 相对函数目录的源文件路径
+	Import string // 包导入路径
+	Func   string // 导出的 http.HandlerFunc
+	Path   string // Vercel 路由，如 /api/rsa/process
+}
+
+// Rewrite vercel.json 中的精确路径重写
+type Rewrite struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+}
+
+// discoverRoutes 按 Vercel 文件系统路由规则扫描 dir 下的 Go 函数：
+//   - 每个 .go 文件是一个函数，路由为 /api/<相对路径去掉 .go>，index.go 对应所在目录
+//   - 以 _ 或 . 开头的文件和目录、_test.go 不是函数（_shared 等共享代码放在这里）
+//   - [id].go 这类动态段 Go 工具链无法就地编译，遇到时报错
+//   - 文件中第一个签名为 func(http.ResponseWriter, *http.Request) 的导出函数是入口
+func discoverRoutes(dir, module string) ([]Route, error) {
+	var routes []Route
+	seen := make(map[string]string)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if p != dir && (strings.HasPrefix(name, "_") || strings.HasPrefix(name, ".")) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if strings.ContainsAny(rel, "[]") {
+			return fmt.Errorf("%s: dynamic route segments are not supported", rel)
+		}
+		fn, err := handlerFunc(p)
+		if err != nil {
+			return err
+		}
+		if fn == "" {
+			return fmt.Errorf("%s: no exported func(http.ResponseWriter, *http.Request)", rel)
+		}
+
+		route := Route{File: rel, Import: module, Func: fn}
+		if pkg := path.Dir(rel); pkg != "." {
+			route.Import += "/" + pkg
+		}
+		route.Path = "/api/" + strings.TrimSuffix(rel, ".go")
+		route.Path = strings.TrimSuffix(strings.TrimSuffix(route.Path, "/index"), "/")
+		if other, ok := seen[route.Path]; ok {
+			return fmt.Errorf("%s and %s both map to %s", other, rel, route.Path)
+		}
+		seen[route.Path] = rel
+		routes = append(routes, route)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(routes, func(i, j int) bool { return routes[i].Path < routes[j].Path })
+	return routes, nil
+}
+
+// handlerFunc 解析源文件，返回第一个导出的 http.HandlerFunc 签名函数名
+func handlerFunc(file string) (string, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return "", err
+	}
+
+	// net/http 可能以别名导入
+	httpName := ""
+	for _, imp := range f.Imports {
+		if p, _ := strconv.Unquote(imp.Path.Value); p == "net/http" {
+			httpName = "http"
+			if imp.Name != nil {
+				httpName = imp.Name.Name
+			}
+		}
+	}
+	if httpName == "" {
+		return "", nil
+	}
+
+	isHTTP := func(expr ast.Expr, name string) bool {
+		sel, ok := expr.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != name {
+			return false
+		}
+		x, ok := sel.X.(*ast.Ident)
+		return ok && x.Name == httpName
+	}
+	for _, decl := range f.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok || fd.Recv != nil || !fd.Name.IsExported() || fd.Type.TypeParams != nil || fd.Type.Results != nil {
+			continue
+		}
+		var params []ast.Expr
+		for _, field := range fd.Type.Params.List {
+			n := max(len(field.Names), 1)
+			for i := 0; i < n; i++ {
+				params = append(params, field.Type)
+			}
+		}
+		if len(params) != 2 || !isHTTP(params[0], "ResponseWriter") {
+			continue
+		}
+		if star, ok := params[1].(*ast.StarExpr); ok && isHTTP(star.X, "Request") {
+			return fd.Name.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// loadRewrites 读取 vercel.json 中源路径为固定路径的重写规则；
+// 正则 / 命名参数形式的规则（如 SPA 回退）本地不需要，跳过并返回其数量
+func loadRewrites(file string) ([]Rewrite, int, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, 0, err
+	}
+	var config struct {
+		Rewrites []Rewrite `json:"rewrites"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, 0, fmt.Errorf("%s: %v", file, err)
+	}
+
+	var rewrites []Rewrite
+	skipped := 0
+	for _, rw := range config.Rewrites {
+		if strings.ContainsAny(rw.Source+rw.Destination, ":()*?+[]{}$") {
+			skipped++
+			continue
+		}
+		rewrites = append(rewrites, rw)
+	}
+	return rewrites, skipped, nil
+}
+
+// modulePath 读取 go.mod 中的模块路径
+func modulePath(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module"); ok {
+			if p := strings.Trim(strings.TrimSpace(rest), `"`); p != "" {
+				return p, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%s: module path not found", filepath.Join(dir, "go.mod"))
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDiscoverRoutes(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"process.go":          "package handler\nimport \"net/http\"\nfunc helper() {}\nfunc Handler(w http.ResponseWriter, r *http.Request) {}\n",
+		"rsa/index.go":        "package rsa\nimport h \"net/http\"\nfunc Index(w h.ResponseWriter, r *h.Request) {}\n",
+		"rsa/process.go":      "package rsa\nimport \"net/http\"\nfunc Wrong(w http.ResponseWriter) {}\nfunc Process(w http.ResponseWriter, r *http.Request) {}\n",
+		"_shared/shared.go":   "package shared\n",
+		".hidden/x.go":        "package x\n",
+		"rsa/process_test.go": "package rsa\n",
+	})
+	routes, err := discoverRoutes(dir, "example.com/api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Route{
+		{File: "process.go", Import: "example.com/api", Func: "Handler", Path: "/api/process"},
+		{File: "rsa/index.go", Import: "example.com/api/rsa", Func: "Index", Path: "/api/rsa"},
+		{File: "rsa/process.go", Import: "example.com/api/rsa", Func: "Process", Path: "/api/rsa/process"},
+	}
+	if !reflect.DeepEqual(routes, want) {
+		t.Errorf("discoverRoutes:\n got %+v\nwant %+v", routes, want)
+	}
+}
+
+func TestDiscoverRoutesErrors(t *testing.T) {
+	handler := "package p\nimport \"net/http\"\nfunc H(w http.ResponseWriter, r *http.Request) {}\n"
+	for name, files := range map[string]map[string]string{
+		"dynamic segment": {"users/[id].go": handler},
+		"no handler":      {"nohandler.go": "package p\nfunc H() {}\n"},
+		"duplicate path":  {"rsa.go": handler, "rsa/index.go": handler},
+	} {
+		if _, err := discoverRoutes(writeFiles(t, files), "example.com/api"); err == nil {
+			t.Errorf("%s: want error", name)
+		}
+	}
+}
+
+func TestLoadRewrites(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"vercel.json": `{"rewrites": [
+		{"source": "/.well-known/jwks.json", "destination": "/api/jwks"},
+		{"source": "/(.*)", "destination": "/index.html"},
+		{"source": "/u/:id", "destination": "/api/user"}
+	]}`, "bad.json": "{"})
+
+	rewrites, skipped, err := loadRewrites(filepath.Join(dir, "vercel.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Rewrite{{Source: "/.well-known/jwks.json", Destination: "/api/jwks"}}; !reflect.DeepEqual(rewrites, want) || skipped != 2 {
+		t.Errorf("loadRewrites = %+v, skipped %d", rewrites, skipped)
+	}
+	if _, _, err := loadRewrites(filepath.Join(dir, "bad.json")); err == nil || !strings.Contains(err.Error(), "bad.json") {
+		t.Errorf("invalid JSON: err = %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package shared
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/router"
+)
+
+var (
+	authenticator router.Authenticator
+	authOnce      sync.Once
+	authInitError error
+)
+
+// initAuthenticator 初始化请求认证（一次性初始化）
+//
+// API_KEYS 启用静态 API 密钥，HMAC_KEYS 启用 HMAC-SHA256 请求签名，格式均为 JSON 数组或
+// 逗号分隔的 id:secret 列表；HMAC_CLOCK_SKEW 为允许的时间偏差（默认 5m）。都未设置时不认证。
+func initAuthenticator() {
+	var auths router.Authenticators
+	if v := os.Getenv("API_KEYS"); v != "" {
+		keys, err := router.ParseAuthKeys([]byte(v))
+		if err != nil {
+			authInitError = fmt.Errorf("API_KEYS: %v", err)
+			return
+		}
+		auths = append(auths, router.NewAPIKeyAuth(keys))
+	}
+	if v := os.Getenv("HMAC_KEYS"); v != "" {
+		keys, err := router.ParseAuthKeys([]byte(v))
+		if err != nil {
+			authInitError = fmt.Errorf("HMAC_KEYS: %v", err)
+			return
+		}
+		skew := router.DefaultClockSkew
+		if v := os.Getenv("HMAC_CLOCK_SKEW"); v != "" {
+			if skew, err = time.ParseDuration(v); err != nil {
+				authInitError = fmt.Errorf("invalid HMAC_CLOCK_SKEW: %v", err)
+				return
+			}
+		}
+		auths = append(auths, router.NewHMACAuth(keys, skew))
+	}
+	if len(auths) > 0 {
+		authenticator = auths
+	}
+}
+
+// GetAuthenticator 获取请求认证；未配置时返回 nil
+func GetAuthenticator() (router.Authenticator, error) {
+	authOnce.Do(initAuthenticator)
+	if authInitError != nil {
+		return nil, authInitError
+	}
+	return authenticator, nil
+}
@@ -0,0 +1,41 @@
+package shared
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/router"
+)
+
+// loadCORSPolicy 按环境变量创建跨域策略，未设置的项使用 router.DefaultCORSConfig：
+//
+//	CORS_ALLOWED_ORIGINS    逗号分隔，如 https://app.example.com,https://*.example.com；默认 *
+//	CORS_ALLOWED_HEADERS    逗号分隔的预检允许请求头
+//	CORS_EXPOSED_HEADERS    逗号分隔的暴露响应头
+//	CORS_ALLOW_CREDENTIALS  true 时允许携带凭据（不能与 * 同时使用）
+//	CORS_MAX_AGE            预检缓存时间，如 10m
+func loadCORSPolicy() (*router.CORSPolicy, error) {
+	config := router.DefaultCORSConfig()
+	if v := os.Getenv("CORS_ALLOWED_ORIGINS"); v != "" {
+		config.AllowedOrigins = router.SplitList(v)
+	}
+	config.AllowedHeaders = router.SplitList(os.Getenv("CORS_ALLOWED_HEADERS"))
+	config.ExposedHeaders = router.SplitList(os.Getenv("CORS_EXPOSED_HEADERS"))
+	if v := os.Getenv("CORS_ALLOW_CREDENTIALS"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CORS_ALLOW_CREDENTIALS: %v", err)
+		}
+		config.AllowCredentials = b
+	}
+	if v := os.Getenv("CORS_MAX_AGE"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CORS_MAX_AGE: %v", err)
+		}
+		config.MaxAge = d
+	}
+	return router.NewCORSPolicy(config)
+}
@@ -0,0 +1,36 @@
+package shared
+
+import "testing"
+
+func TestLoadCORSPolicy(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", "")
+	p, err := loadCORSPolicy()
+	if err != nil || !p.AllowsAnyOrigin() {
+		t.Fatalf("default policy: any origin %v, err %v", p != nil && p.AllowsAnyOrigin(), err)
+	}
+
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://app.example.com, https://*.example.org")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "true")
+	t.Setenv("CORS_MAX_AGE", "1m")
+	p, err = loadCORSPolicy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.AllowsAnyOrigin() || !p.AllowOrigin("https://app.example.com:443") || !p.AllowOrigin("https://a.example.org") || p.AllowOrigin("https://evil.example") {
+		t.Error("allow-list from CORS_ALLOWED_ORIGINS not applied")
+	}
+
+	for name, env := range map[string][2]string{
+		"credentials with *": {"CORS_ALLOWED_ORIGINS", "*"},
+		"bad credentials":    {"CORS_ALLOW_CREDENTIALS", "maybe"},
+		"bad max age":        {"CORS_MAX_AGE", "soon"},
+		"bad origin":         {"CORS_ALLOWED_ORIGINS", "ftp://example.com"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(env[0], env[1])
+			if _, err := loadCORSPolicy(); err == nil {
+				t.Error("want error")
+			}
+		})
+	}
+}
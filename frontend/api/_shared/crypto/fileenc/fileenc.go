@@ -0,0 +1,130 @@
+// Package fileenc 在 stream 分段 AEAD 格式之上封装文件加密，头部携带文件名、MIME 类型和原始大小。
+//
+// 文件格式：
+//
+//	magic "AGJF"(4) || version(1) || metaLen(4, 大端) || metaJSON || stream
+//
+// 元数据以明文存放（下载前即可读取文件名），但整段头部作为 stream 的附加认证数据，
+// 被篡改时解密失败；需要隐藏文件名的场景应在上传前自行改名。
+package fileenc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/stream"
+)
+
+const (
+	// Version 当前文件格式版本
+	Version byte = 1
+	// MaxMetadataSize 元数据 JSON 长度上限
+	MaxMetadataSize = 64 << 10
+
+	magic = "AGJF"
+)
+
+var (
+	// ErrInvalidFile 不是本包生成的加密文件或版本不受支持
+	ErrInvalidFile = errors.New("fileenc: invalid encrypted file")
+	// ErrSizeMismatch 解密得到的长度与头部记录的原始大小不一致
+	ErrSizeMismatch = errors.New("fileenc: size mismatch")
+)
+
+// Metadata 随密文保存的原始文件信息
+type Metadata struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Size int64  `json:"size"`
+}
+
+// header 序列化文件头部
+func (m *Metadata) header() ([]byte, error) {
+	meta, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	if len(meta) > MaxMetadataSize {
+		return nil, fmt.Errorf("%w: metadata too large", ErrInvalidFile)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(magic)
+	buf.WriteByte(Version)
+	binary.Write(&buf, binary.BigEndian, uint32(len(meta)))
+	buf.Write(meta)
+	return buf.Bytes(), nil
+}
+
+// streamAAD 头部与调用方 aad 拼接后作为 stream 的附加认证数据
+func streamAAD(header, aad []byte) []byte {
+	return append(append([]byte{}, header...), aad...)
+}
+
+// NewWriter 写出文件头部并返回加密 Writer；写完 meta.Size 字节后必须调用 Close
+func NewWriter(w io.Writer, key, aad []byte, meta Metadata) (*stream.Writer, error) {
+	if meta.Size < 0 {
+		return nil, fmt.Errorf("%w: negative size", ErrInvalidFile)
+	}
+	header, err := meta.header()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return stream.NewWriter(w, key, streamAAD(header, aad), 0)
+}
+
+// NewReader 读取文件头部，返回元数据和解密 Reader；Reader 在数据被篡改、截断或长度与头部不符时返回错误
+func NewReader(r io.Reader, key, aad []byte) (*Metadata, io.Reader, error) {
+	prefix := make([]byte, len(magic)+1+4)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	if string(prefix[:len(magic)]) != magic {
+		return nil, nil, fmt.Errorf("%w: bad magic", ErrInvalidFile)
+	}
+	if prefix[len(magic)] != Version {
+		return nil, nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidFile, prefix[len(magic)])
+	}
+	metaLen := binary.BigEndian.Uint32(prefix[len(magic)+1:])
+	if metaLen > MaxMetadataSize {
+		return nil, nil, fmt.Errorf("%w: metadata too large", ErrInvalidFile)
+	}
+
+	meta := make([]byte, metaLen)
+	if _, err := io.ReadFull(r, meta); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	var m Metadata
+	if err := json.Unmarshal(meta, &m); err != nil {
+		return nil, nil, fmt.Errorf("%w: metadata: %v", ErrInvalidFile, err)
+	}
+
+	sr, err := stream.NewReader(r, key, streamAAD(append(prefix, meta...), aad))
+	if err != nil {
+		return nil, nil, err
+	}
+	return &m, &sizeReader{r: sr, want: m.Size}, nil
+}
+
+// sizeReader 在 EOF 时校验实际长度与头部记录的原始大小一致
+type sizeReader struct {
+	r    io.Reader
+	want int64
+	got  int64
+}
+
+func (s *sizeReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	s.got += int64(n)
+	if s.got > s.want || (err == io.EOF && s.got != s.want) {
+		return n, fmt.Errorf("%w: header says %d bytes, got %d", ErrSizeMismatch, s.want, s.got)
+	}
+	return n, err
+}
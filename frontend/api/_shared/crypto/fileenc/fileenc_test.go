@@ -0,0 +1,126 @@
+package fileenc
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/stream"
+)
+
+func testKey() []byte {
+	return bytes.Repeat([]byte{0x3c}, stream.KeySize)
+}
+
+// encrypt 按 meta 写出加密文件，plainText 长度不必与 meta.Size 一致
+func encrypt(t *testing.T, key, aad []byte, meta Metadata, plainText []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, key, aad, meta)
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	if _, err := w.Write(plainText); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func decrypt(key, aad, sealed []byte) (*Metadata, []byte, error) {
+	meta, r, err := NewReader(bytes.NewReader(sealed), key, aad)
+	if err != nil {
+		return nil, nil, err
+	}
+	plainText, err := io.ReadAll(r)
+	return meta, plainText, err
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, size := range []int{0, 1, 100 << 10} {
+		plainText := make([]byte, size)
+		rand.Read(plainText)
+		meta := Metadata{Name: "报告.pdf", Type: "application/pdf", Size: int64(size)}
+		sealed := encrypt(t, testKey(), []byte("user-42"), meta, plainText)
+
+		gotMeta, got, err := decrypt(testKey(), []byte("user-42"), sealed)
+		if err != nil {
+			t.Fatalf("size %d: decrypt: %v", size, err)
+		}
+		if *gotMeta != meta {
+			t.Errorf("size %d: metadata = %+v, want %+v", size, *gotMeta, meta)
+		}
+		if !bytes.Equal(got, plainText) {
+			t.Fatalf("size %d: round trip mismatch", size)
+		}
+	}
+}
+
+func TestTamper(t *testing.T) {
+	meta := Metadata{Name: "a.txt", Type: "text/plain", Size: 5}
+	sealed := encrypt(t, testKey(), nil, meta, []byte("hello"))
+
+	// 改写明文头部中的文件名：元数据仍可解析，但作为附加认证数据参与校验
+	renamed := bytes.Replace(sealed, []byte("a.txt"), []byte("b.txt"), 1)
+	if _, _, err := decrypt(testKey(), nil, renamed); !errors.Is(err, stream.ErrInvalidSegment) {
+		t.Errorf("renamed: err = %v, want stream.ErrInvalidSegment", err)
+	}
+
+	body := bytes.Clone(sealed)
+	body[len(body)-1] ^= 0x01
+	if _, _, err := decrypt(testKey(), nil, body); !errors.Is(err, stream.ErrInvalidSegment) {
+		t.Errorf("tampered body: err = %v, want stream.ErrInvalidSegment", err)
+	}
+	if _, _, err := decrypt(testKey(), []byte("aad"), sealed); !errors.Is(err, stream.ErrInvalidSegment) {
+		t.Errorf("wrong aad: err = %v, want stream.ErrInvalidSegment", err)
+	}
+	if _, _, err := decrypt(bytes.Repeat([]byte{1}, stream.KeySize), nil, sealed); !errors.Is(err, stream.ErrInvalidSegment) {
+		t.Errorf("wrong key: err = %v, want stream.ErrInvalidSegment", err)
+	}
+}
+
+func TestInvalidFile(t *testing.T) {
+	sealed := encrypt(t, testKey(), nil, Metadata{Name: "a", Size: 1}, []byte("x"))
+
+	badMagic := bytes.Clone(sealed)
+	badMagic[0] = 'X'
+	badVersion := bytes.Clone(sealed)
+	badVersion[len(magic)] = Version + 1
+	hugeMeta := bytes.Clone(sealed)
+	hugeMeta[len(magic)+1] = 0xff
+
+	tests := []struct {
+		name   string
+		sealed []byte
+	}{
+		{"empty", nil},
+		{"bad magic", badMagic},
+		{"bad version", badVersion},
+		{"metadata too large", hugeMeta},
+		{"truncated metadata", sealed[:len(magic)+1+4+2]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := NewReader(bytes.NewReader(tt.sealed), testKey(), nil); !errors.Is(err, ErrInvalidFile) {
+				t.Errorf("err = %v, want ErrInvalidFile", err)
+			}
+		})
+	}
+
+	if _, err := NewWriter(io.Discard, testKey(), nil, Metadata{Size: -1}); !errors.Is(err, ErrInvalidFile) {
+		t.Errorf("negative size: err = %v, want ErrInvalidFile", err)
+	}
+}
+
+func TestSizeMismatch(t *testing.T) {
+	for _, n := range []int{4, 6} {
+		sealed := encrypt(t, testKey(), nil, Metadata{Name: "a", Size: 5}, make([]byte, n))
+		if _, _, err := decrypt(testKey(), nil, sealed); !errors.Is(err, ErrSizeMismatch) {
+			t.Errorf("%d bytes for size 5: err = %v, want ErrSizeMismatch", n, err)
+		}
+	}
+}
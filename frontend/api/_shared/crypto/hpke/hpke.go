@@ -0,0 +1,398 @@
+// Package hpke 实现 RFC 9180 混合公钥加密（HPKE）的 base 与 auth 模式。
+//
+// 支持的算法：
+//
+//	KEM   DHKEM(X25519, HKDF-SHA256)  0x0020
+//	KDF   HKDF-SHA256                 0x0001
+//	AEAD  AES-128-GCM                 0x0001
+//	      AES-256-GCM                 0x0002
+//	      ChaCha20-Poly1305           0x0003
+//
+// 提供单次调用的 Seal / Open，以及可连续加密多条消息、导出密钥的 Sender / Receiver 上下文。
+// auth 模式额外用发送方的静态私钥参与 KEM，接收方据此确认消息来自持有该私钥的发送方。
+package hpke
+
+import (
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/LeeeeeeM/aes-go-js/pkg/jsaes"
+)
+
+// KEM 密钥封装算法标识
+type KEM uint16
+
+// KDF 密钥派生算法标识
+type KDF uint16
+
+// AEAD 认证加密算法标识
+type AEAD uint16
+
+const (
+	DHKEMX25519HKDFSHA256 KEM = 0x0020
+
+	HKDFSHA256 KDF = 0x0001
+
+	AES128GCM        AEAD = 0x0001
+	AES256GCM        AEAD = 0x0002
+	ChaCha20Poly1305 AEAD = 0x0003
+)
+
+// 模式标识
+const (
+	modeBase byte = 0x00
+	modeAuth byte = 0x02
+)
+
+const (
+	nSecret = 32
+	nSK     = 32
+	nH      = sha256.Size
+	nN      = 12
+	version = "HPKE-v1"
+)
+
+var (
+	// ErrUnsupportedSuite 不支持的 KEM / KDF / AEAD 组合
+	ErrUnsupportedSuite = errors.New("hpke: unsupported cipher suite")
+	// ErrInvalidEncapsulation enc 长度错误或为低阶点
+	ErrInvalidEncapsulation = errors.New("hpke: invalid encapsulated key")
+	// ErrOpen 解密失败：密钥、info、aad 或发送方公钥不匹配，或密文被篡改
+	ErrOpen = errors.New("hpke: message authentication failed")
+	// ErrMessageLimit 序号用尽，上下文不能再加解密
+	ErrMessageLimit = errors.New("hpke: message limit reached")
+)
+
+// Suite HPKE 算法组合
+type Suite struct {
+	KEM  KEM
+	KDF  KDF
+	AEAD AEAD
+}
+
+// NewSuite 创建算法组合；KEM 只支持 DHKEM(X25519, HKDF-SHA256)，KDF 只支持 HKDF-SHA256
+func NewSuite(kem KEM, kdf KDF, aead AEAD) (Suite, error) {
+	s := Suite{KEM: kem, KDF: kdf, AEAD: aead}
+	if kem != DHKEMX25519HKDFSHA256 || kdf != HKDFSHA256 || s.keySize() == 0 {
+		return Suite{}, fmt.Errorf("%w: kem=0x%04x kdf=0x%04x aead=0x%04x", ErrUnsupportedSuite, kem, kdf, aead)
+	}
+	return s, nil
+}
+
+// keySize AEAD 密钥长度 Nk，不支持的 AEAD 返回 0
+func (s Suite) keySize() int {
+	switch s.AEAD {
+	case AES128GCM:
+		return 16
+	case AES256GCM, ChaCha20Poly1305:
+		return 32
+	}
+	return 0
+}
+
+// id 套件标识 "HPKE" || kem_id || kdf_id || aead_id
+func (s Suite) id() []byte {
+	return binary.BigEndian.AppendUint16(binary.BigEndian.AppendUint16(
+		binary.BigEndian.AppendUint16([]byte("HPKE"), uint16(s.KEM)), uint16(s.KDF)), uint16(s.AEAD))
+}
+
+// kemID KEM 标识 "KEM" || kem_id
+func kemID() []byte {
+	return binary.BigEndian.AppendUint16([]byte("KEM"), uint16(DHKEMX25519HKDFSHA256))
+}
+
+// labeledExtract LabeledExtract(salt, label, ikm)
+func labeledExtract(suiteID []byte, salt []byte, label string, ikm []byte) []byte {
+	labeled := append(append(append([]byte(version), suiteID...), label...), ikm...)
+	prk, _ := hkdf.Extract(sha256.New, labeled, salt)
+	return prk
+}
+
+// labeledExpand LabeledExpand(prk, label, info, L)
+func labeledExpand(suiteID []byte, prk []byte, label string, info []byte, length int) []byte {
+	labeled := binary.BigEndian.AppendUint16(nil, uint16(length))
+	labeled = append(append(append(append(labeled, version...), suiteID...), label...), info...)
+	out, err := hkdf.Expand(sha256.New, prk, string(labeled), length)
+	if err != nil {
+		// length 由调用方控制，只在超过 255*Nh 时失败
+		panic(err)
+	}
+	return out
+}
+
+// DeriveKeyPair 由 ikm 确定性派生 X25519 密钥对（RFC 9180 7.1.3）
+func DeriveKeyPair(ikm []byte) (*ecdh.PrivateKey, error) {
+	prk := labeledExtract(kemID(), nil, "dkp_prk", ikm)
+	return ecdh.X25519().NewPrivateKey(labeledExpand(kemID(), prk, "sk", nil, nSK))
+}
+
+// GenerateKeyPair 生成随机的 X25519 密钥对
+func GenerateKeyPair() (*ecdh.PrivateKey, error) {
+	return ecdh.X25519().GenerateKey(rand.Reader)
+}
+
+// extractAndExpand DHKEM 的共享秘密派生
+func extractAndExpand(dh, kemContext []byte) []byte {
+	prk := labeledExtract(kemID(), nil, "eae_prk", dh)
+	return labeledExpand(kemID(), prk, "shared_secret", kemContext, nSecret)
+}
+
+// encap (Auth)Encap；ikmE 为 nil 时随机生成临时密钥，否则由 ikmE 派生（用于测试向量）
+func encap(pkR *ecdh.PublicKey, skS *ecdh.PrivateKey, ikmE []byte) (sharedSecret, enc []byte, err error) {
+	var skE *ecdh.PrivateKey
+	if ikmE == nil {
+		skE, err = GenerateKeyPair()
+	} else {
+		skE, err = DeriveKeyPair(ikmE)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dh, err := skE.ECDH(pkR)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidEncapsulation, err)
+	}
+	enc = skE.PublicKey().Bytes()
+	kemContext := append(append([]byte{}, enc...), pkR.Bytes()...)
+	if skS != nil {
+		dhS, err := skS.ECDH(pkR)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidEncapsulation, err)
+		}
+		dh = append(dh, dhS...)
+		kemContext = append(kemContext, skS.PublicKey().Bytes()...)
+	}
+	return extractAndExpand(dh, kemContext), enc, nil
+}
+
+// decap (Auth)Decap
+func decap(enc []byte, skR *ecdh.PrivateKey, pkS *ecdh.PublicKey) ([]byte, error) {
+	pkE, err := ecdh.X25519().NewPublicKey(enc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEncapsulation, err)
+	}
+	dh, err := skR.ECDH(pkE)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEncapsulation, err)
+	}
+	kemContext := append(append([]byte{}, enc...), skR.PublicKey().Bytes()...)
+	if pkS != nil {
+		dhS, err := skR.ECDH(pkS)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidEncapsulation, err)
+		}
+		dh = append(dh, dhS...)
+		kemContext = append(kemContext, pkS.Bytes()...)
+	}
+	return extractAndExpand(dh, kemContext), nil
+}
+
+// context 密钥调度得到的加密上下文，Sender 与 Receiver 共用
+type context struct {
+	aead           *jsaes.Cipher
+	baseNonce      []byte
+	exporterSecret []byte
+	suiteID        []byte
+	seq            uint64
+}
+
+// keySchedule RFC 9180 5.1 密钥调度（不使用 PSK）
+func (s Suite) keySchedule(mode byte, sharedSecret, info []byte) (*context, error) {
+	id := s.id()
+	pskIDHash := labeledExtract(id, nil, "psk_id_hash", nil)
+	infoHash := labeledExtract(id, nil, "info_hash", info)
+	ksContext := append(append([]byte{mode}, pskIDHash...), infoHash...)
+	secret := labeledExtract(id, sharedSecret, "secret", nil)
+
+	key := labeledExpand(id, secret, "key", ksContext, s.keySize())
+	var alg jsaes.Algorithm
+	switch s.AEAD {
+	case AES128GCM:
+		alg = jsaes.A128GCM
+	case AES256GCM:
+		alg = jsaes.A256GCM
+	case ChaCha20Poly1305:
+		alg = jsaes.C20P
+	}
+	c, err := jsaes.NewCipherWithAlgorithm(alg, key)
+	if err != nil {
+		return nil, err
+	}
+	return &context{
+		aead:           c,
+		baseNonce:      labeledExpand(id, secret, "base_nonce", ksContext, nN),
+		exporterSecret: labeledExpand(id, secret, "exp", ksContext, nH),
+		suiteID:        id,
+	}, nil
+}
+
+// nonce base_nonce XOR I2OSP(seq, Nn)
+func (c *context) nonce() []byte {
+	nonce := make([]byte, nN)
+	binary.BigEndian.PutUint64(nonce[nN-8:], c.seq)
+	for i := range nonce {
+		nonce[i] ^= c.baseNonce[i]
+	}
+	return nonce
+}
+
+// increment 序号加一，达到上限后拒绝继续使用
+func (c *context) increment() error {
+	if c.seq == ^uint64(0) {
+		return ErrMessageLimit
+	}
+	c.seq++
+	return nil
+}
+
+// Export 导出与上下文绑定的密钥材料（RFC 9180 5.3）
+func (c *context) Export(exporterContext []byte, length int) ([]byte, error) {
+	if length < 0 || length > 255*nH {
+		return nil, fmt.Errorf("hpke: invalid export length %d", length)
+	}
+	return labeledExpand(c.suiteID, c.exporterSecret, "sec", exporterContext, length), nil
+}
+
+// Sender 发送方上下文，按顺序加密多条消息
+type Sender struct {
+	*context
+}
+
+// Seal 加密一条消息
+func (s *Sender) Seal(aad, plainText []byte) ([]byte, error) {
+	if s.seq == ^uint64(0) {
+		return nil, ErrMessageLimit
+	}
+	ct, err := s.aead.SealWithAAD(s.nonce(), plainText, aad)
+	if err != nil {
+		return nil, err
+	}
+	return ct, s.increment()
+}
+
+// Receiver 接收方上下文，须按发送顺序解密
+type Receiver struct {
+	*context
+}
+
+// Open 解密一条消息；失败时序号不变
+func (r *Receiver) Open(aad, cipherText []byte) ([]byte, error) {
+	if r.seq == ^uint64(0) {
+		return nil, ErrMessageLimit
+	}
+	pt, err := r.aead.OpenWithAAD(r.nonce(), cipherText, aad)
+	if err != nil {
+		return nil, ErrOpen
+	}
+	return pt, r.increment()
+}
+
+// setupS 发送方建立上下文；skS 不为 nil 时为 auth 模式
+func (s Suite) setupS(pkR *ecdh.PublicKey, info []byte, skS *ecdh.PrivateKey, ikmE []byte) ([]byte, *Sender, error) {
+	if s.keySize() == 0 {
+		return nil, nil, ErrUnsupportedSuite
+	}
+	sharedSecret, enc, err := encap(pkR, skS, ikmE)
+	if err != nil {
+		return nil, nil, err
+	}
+	mode := modeBase
+	if skS != nil {
+		mode = modeAuth
+	}
+	ctx, err := s.keySchedule(mode, sharedSecret, info)
+	if err != nil {
+		return nil, nil, err
+	}
+	return enc, &Sender{ctx}, nil
+}
+
+// setupR 接收方建立上下文；pkS 不为 nil 时为 auth 模式
+func (s Suite) setupR(enc []byte, skR *ecdh.PrivateKey, info []byte, pkS *ecdh.PublicKey) (*Receiver, error) {
+	if s.keySize() == 0 {
+		return nil, ErrUnsupportedSuite
+	}
+	sharedSecret, err := decap(enc, skR, pkS)
+	if err != nil {
+		return nil, err
+	}
+	mode := modeBase
+	if pkS != nil {
+		mode = modeAuth
+	}
+	ctx, err := s.keySchedule(mode, sharedSecret, info)
+	if err != nil {
+		return nil, err
+	}
+	return &Receiver{ctx}, nil
+}
+
+// SetupBaseS base 模式发送方：返回封装密钥 enc 和发送方上下文
+func (s Suite) SetupBaseS(pkR *ecdh.PublicKey, info []byte) ([]byte, *Sender, error) {
+	return s.setupS(pkR, info, nil, nil)
+}
+
+// SetupBaseR base 模式接收方
+func (s Suite) SetupBaseR(enc []byte, skR *ecdh.PrivateKey, info []byte) (*Receiver, error) {
+	return s.setupR(enc, skR, info, nil)
+}
+
+// SetupAuthS auth 模式发送方：skS 为发送方静态私钥
+func (s Suite) SetupAuthS(pkR *ecdh.PublicKey, info []byte, skS *ecdh.PrivateKey) ([]byte, *Sender, error) {
+	if skS == nil {
+		return nil, nil, errors.New("hpke: sender private key is required in auth mode")
+	}
+	return s.setupS(pkR, info, skS, nil)
+}
+
+// SetupAuthR auth 模式接收方：pkS 为预期的发送方公钥
+func (s Suite) SetupAuthR(enc []byte, skR *ecdh.PrivateKey, info []byte, pkS *ecdh.PublicKey) (*Receiver, error) {
+	if pkS == nil {
+		return nil, errors.New("hpke: sender public key is required in auth mode")
+	}
+	return s.setupR(enc, skR, info, pkS)
+}
+
+// Seal base 模式单次加密，返回 enc 和密文
+func (s Suite) Seal(pkR *ecdh.PublicKey, info, aad, plainText []byte) (enc, cipherText []byte, err error) {
+	enc, sender, err := s.SetupBaseS(pkR, info)
+	if err != nil {
+		return nil, nil, err
+	}
+	cipherText, err = sender.Seal(aad, plainText)
+	return enc, cipherText, err
+}
+
+// Open base 模式单次解密
+func (s Suite) Open(enc []byte, skR *ecdh.PrivateKey, info, aad, cipherText []byte) ([]byte, error) {
+	r, err := s.SetupBaseR(enc, skR, info)
+	if err != nil {
+		return nil, err
+	}
+	return r.Open(aad, cipherText)
+}
+
+// SealAuth auth 模式单次加密
+func (s Suite) SealAuth(pkR *ecdh.PublicKey, info, aad, plainText []byte, skS *ecdh.PrivateKey) (enc, cipherText []byte, err error) {
+	enc, sender, err := s.SetupAuthS(pkR, info, skS)
+	if err != nil {
+		return nil, nil, err
+	}
+	cipherText, err = sender.Seal(aad, plainText)
+	return enc, cipherText, err
+}
+
+// OpenAuth auth 模式单次解密
+func (s Suite) OpenAuth(enc []byte, skR *ecdh.PrivateKey, info, aad, cipherText []byte, pkS *ecdh.PublicKey) ([]byte, error) {
+	r, err := s.SetupAuthR(enc, skR, info, pkS)
+	if err != nil {
+		return nil, err
+	}
+	return r.Open(aad, cipherText)
+}
@@ -0,0 +1,342 @@
+package hpke
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/LeeeeeeM/aes-go-js/pkg/jsaes"
+)
+
+func decodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// rfc9180Vector RFC 9180 附录 A.1 的 DHKEM(X25519, HKDF-SHA256), HKDF-SHA256, AES-128-GCM 向量
+type rfc9180Vector struct {
+	name           string
+	info           string
+	ikmE, ikmR     string
+	ikmS           string // 空表示 base 模式
+	skRm, pkRm     string
+	pkSm           string
+	enc            string
+	sharedSecret   string
+	key            string
+	baseNonce      string
+	exporterSecret string
+	ciphertexts    map[uint64]string // 序号 -> 密文，明文为 "Beauty is truth, truth beauty"，aad 为 "Count-<序号>"
+	exports        map[string]string // exporter_context（hex） -> 32 字节导出值
+}
+
+var rfc9180Vectors = []rfc9180Vector{
+	{
+		name:           "A.1.1 base",
+		info:           "4f6465206f6e2061204772656369616e2055726e",
+		ikmE:           "7268600d403fce431561aef583ee1613527cff655c1343f29812e66706df3234",
+		ikmR:           "6db9df30aa07dd42ee5e8181afdb977e538f5e1fec8a06223f33f7013e525037",
+		skRm:           "4612c550263fc8ad58375df3f557aac531d26850903e55a9f23f21d8534e8ac8",
+		pkRm:           "3948cfe0ad1ddb695d780e59077195da6c56506b027329794ab02bca80815c4d",
+		enc:            "37fda3567bdbd628e88668c3c8d7e97d1d1253b6d4ea6d44c150f741f1bf4431",
+		sharedSecret:   "fe0e18c9f024ce43799ae393c7e8fe8fce9d218875e8227b0187c04e7d2ea1fc",
+		key:            "4531685d41d65f03dc48f6b8302c05b0",
+		baseNonce:      "56d890e5accaaf011cff4b7d",
+		exporterSecret: "45ff1c2e220db587171952c0592d5f5ebe103f1561a2614e38f2ffd47e99e3f8",
+		ciphertexts: map[uint64]string{
+			0:   "f938558b5d72f1a23810b4be2ab4f84331acc02fc97babc53a52ae8218a355a96d8770ac83d07bea87e13c512a",
+			1:   "af2d7e9ac9ae7e270f46ba1f975be53c09f8d875bdc8535458c2494e8a6eab251c03d0c22a56b8ca42c2063b84",
+			2:   "498dfcabd92e8acedc281e85af1cb4e3e31c7dc394a1ca20e173cb72516491588d96a19ad4a683518973dcc180",
+			4:   "583bd32bc67a5994bb8ceaca813d369bca7b2a42408cddef5e22f880b631215a09fc0012bc69fccaa251c0246d",
+			255: "7175db9717964058640a3a11fb9007941a5d1757fda1a6935c805c21af32505bf106deefec4a49ac38d71c9e0a",
+			256: "957f9800542b0b8891badb026d79cc54597cb2d225b54c00c5238c25d05c30e3fbeda97d2e0e1aba483a2df9f2",
+		},
+		exports: map[string]string{
+			"":                       "3853fe2b4035195a573ffc53856e77058e15d9ea064de3e59f4961d0095250ee",
+			"00":                     "2e8f0b54673c7029649d4eb9d5e33bf1872cf76d623ff164ac185da9e88c21a5",
+			"54657374436f6e74657874": "e9e43065102c3836401bed8c3c3c75ae46be1639869391d62c61f1ec7af54931",
+		},
+	},
+	{
+		name:           "A.1.3 auth",
+		info:           "4f6465206f6e2061204772656369616e2055726e",
+		ikmE:           "6e6d8f200ea2fb20c30b003a8b4f433d2f4ed4c2658d5bc8ce2fef718059c9f7",
+		ikmR:           "f1d4a30a4cef8d6d4e3b016e6fd3799ea057db4f345472ed302a67ce1c20cdec",
+		ikmS:           "94b020ce91d73fca4649006c7e7329a67b40c55e9e93cc907d282bbbff386f58",
+		skRm:           "fdea67cf831f1ca98d8e27b1f6abeb5b7745e9d35348b80fa407ff6958f9137e",
+		pkRm:           "1632d5c2f71c2b38d0a8fcc359355200caa8b1ffdf28618080466c909cb69b2e",
+		pkSm:           "8b0c70873dc5aecb7f9ee4e62406a397b350e57012be45cf53b7105ae731790b",
+		enc:            "23fb952571a14a25e3d678140cd0e5eb47a0961bb18afcf85896e5453c312e76",
+		sharedSecret:   "2d6db4cf719dc7293fcbf3fa64690708e44e2bebc81f84608677958c0d4448a7",
+		key:            "b062cb2c4dd4bca0ad7c7a12bbc341e6",
+		baseNonce:      "a1bc314c1942ade7051ffed0",
+		exporterSecret: "ee1a093e6e1c393c162ea98fdf20560c75909653550540a2700511b65c88c6f1",
+		ciphertexts: map[uint64]string{
+			0:   "5fd92cc9d46dbf8943e72a07e42f363ed5f721212cd90bcfd072bfd9f44e06b80fd17824947496e21b680c141b",
+			1:   "d3736bb256c19bfa93d79e8f80b7971262cb7c887e35c26370cfed62254369a1b52e3d505b79dd699f002bc8ed",
+			2:   "122175cfd5678e04894e4ff8789e85dd381df48dcaf970d52057df2c9acc3b121313a2bfeaa986050f82d93645",
+			4:   "dae12318660cf963c7bcbef0f39d64de3bf178cf9e585e756654043cc5059873bc8af190b72afc43d1e0135ada",
+			255: "55d53d85fe4d9e1e97903101eab0b4865ef20cef28765a47f840ff99625b7d69dee927df1defa66a036fc58ff2",
+			256: "42fa248a0e67ccca688f2b1d13ba4ba84755acf764bd797c8f7ba3b9b1dc3330326f8d172fef6003c79ec72319",
+		},
+		exports: map[string]string{
+			"":                       "28c70088017d70c896a8420f04702c5a321d9cbf0279fba899b59e51bac72c85",
+			"00":                     "25dfc004b0892be1888c3914977aa9c9bbaf2c7471708a49e1195af48a6f29ce",
+			"54657374436f6e74657874": "5a0131813abc9a522cad678eb6bafaabc43389934adb8097d23c5ff68059eb64",
+		},
+	},
+}
+
+func TestRFC9180Vectors(t *testing.T) {
+	suite, err := NewSuite(DHKEMX25519HKDFSHA256, HKDFSHA256, AES128GCM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plainText := []byte("Beauty is truth, truth beauty")
+
+	for _, v := range rfc9180Vectors {
+		t.Run(v.name, func(t *testing.T) {
+			skR, err := DeriveKeyPair(decodeHex(t, v.ikmR))
+			if err != nil {
+				t.Fatalf("DeriveKeyPair(ikmR): %v", err)
+			}
+			if got := hex.EncodeToString(skR.Bytes()); got != v.skRm {
+				t.Errorf("skRm = %s, want %s", got, v.skRm)
+			}
+			if got := hex.EncodeToString(skR.PublicKey().Bytes()); got != v.pkRm {
+				t.Errorf("pkRm = %s, want %s", got, v.pkRm)
+			}
+			var skS *ecdh.PrivateKey
+			var pkS *ecdh.PublicKey
+			if v.ikmS != "" {
+				if skS, err = DeriveKeyPair(decodeHex(t, v.ikmS)); err != nil {
+					t.Fatalf("DeriveKeyPair(ikmS): %v", err)
+				}
+				pkS = skS.PublicKey()
+				if got := hex.EncodeToString(pkS.Bytes()); got != v.pkSm {
+					t.Errorf("pkSm = %s, want %s", got, v.pkSm)
+				}
+			}
+
+			sharedSecret, enc, err := encap(skR.PublicKey(), skS, decodeHex(t, v.ikmE))
+			if err != nil {
+				t.Fatalf("encap: %v", err)
+			}
+			if got := hex.EncodeToString(enc); got != v.enc {
+				t.Errorf("enc = %s, want %s", got, v.enc)
+			}
+			if got := hex.EncodeToString(sharedSecret); got != v.sharedSecret {
+				t.Errorf("shared_secret = %s, want %s", got, v.sharedSecret)
+			}
+
+			info := decodeHex(t, v.info)
+			enc, sender, err := suite.setupS(skR.PublicKey(), info, skS, decodeHex(t, v.ikmE))
+			if err != nil {
+				t.Fatalf("setupS: %v", err)
+			}
+			if got := hex.EncodeToString(sender.baseNonce); got != v.baseNonce {
+				t.Errorf("base_nonce = %s, want %s", got, v.baseNonce)
+			}
+			if got := hex.EncodeToString(sender.exporterSecret); got != v.exporterSecret {
+				t.Errorf("exporter_secret = %s, want %s", got, v.exporterSecret)
+			}
+			// 上下文的 AEAD 密钥不对外暴露：用向量中的 key 和 base_nonce 加密序号 0 的消息，结果须与向量一致
+			vectorKey, err := jsaes.NewCipherWithAlgorithm(jsaes.A128GCM, decodeHex(t, v.key))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ct, _ := vectorKey.SealWithAAD(decodeHex(t, v.baseNonce), plainText, []byte("Count-0")); hex.EncodeToString(ct) != v.ciphertexts[0] {
+				t.Errorf("key %s does not produce the sequence 0 ciphertext", v.key)
+			}
+
+			var receiver *Receiver
+			if pkS == nil {
+				receiver, err = suite.SetupBaseR(enc, skR, info)
+			} else {
+				receiver, err = suite.SetupAuthR(enc, skR, info, pkS)
+			}
+			if err != nil {
+				t.Fatalf("setupR: %v", err)
+			}
+
+			for seq := uint64(0); seq <= 256; seq++ {
+				aad := []byte(fmt.Sprintf("Count-%d", seq))
+				ct, err := sender.Seal(aad, plainText)
+				if err != nil {
+					t.Fatalf("seq %d: Seal: %v", seq, err)
+				}
+				if want, ok := v.ciphertexts[seq]; ok && hex.EncodeToString(ct) != want {
+					t.Errorf("seq %d: ct = %x, want %s", seq, ct, want)
+				}
+				pt, err := receiver.Open(aad, ct)
+				if err != nil {
+					t.Fatalf("seq %d: Open: %v", seq, err)
+				}
+				if !bytes.Equal(pt, plainText) {
+					t.Fatalf("seq %d: pt = %q", seq, pt)
+				}
+			}
+
+			for exporterContext, want := range v.exports {
+				for name, ctx := range map[string]*context{"sender": sender.context, "receiver": receiver.context} {
+					got, err := ctx.Export(decodeHex(t, exporterContext), 32)
+					if err != nil {
+						t.Fatalf("%s Export: %v", name, err)
+					}
+					if hex.EncodeToString(got) != want {
+						t.Errorf("%s Export(%q) = %x, want %s", name, exporterContext, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSealOpen(t *testing.T) {
+	skR, _ := GenerateKeyPair()
+	skS, _ := GenerateKeyPair()
+	for _, aead := range []AEAD{AES128GCM, AES256GCM, ChaCha20Poly1305} {
+		suite, err := NewSuite(DHKEMX25519HKDFSHA256, HKDFSHA256, aead)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		enc, ct, err := suite.Seal(skR.PublicKey(), []byte("info"), []byte("aad"), []byte("hello"))
+		if err != nil {
+			t.Fatalf("aead %d: Seal: %v", aead, err)
+		}
+		if pt, err := suite.Open(enc, skR, []byte("info"), []byte("aad"), ct); err != nil || string(pt) != "hello" {
+			t.Fatalf("aead %d: Open = %q, %v", aead, pt, err)
+		}
+		for name, open := range map[string]func() ([]byte, error){
+			"wrong info": func() ([]byte, error) { return suite.Open(enc, skR, []byte("other"), []byte("aad"), ct) },
+			"wrong aad":  func() ([]byte, error) { return suite.Open(enc, skR, []byte("info"), []byte("other"), ct) },
+			"tampered ct": func() ([]byte, error) {
+				tampered := bytes.Clone(ct)
+				tampered[0] ^= 1
+				return suite.Open(enc, skR, []byte("info"), []byte("aad"), tampered)
+			},
+			"wrong recipient": func() ([]byte, error) {
+				other, _ := GenerateKeyPair()
+				return suite.Open(enc, other, []byte("info"), []byte("aad"), ct)
+			},
+		} {
+			if _, err := open(); !errors.Is(err, ErrOpen) {
+				t.Errorf("aead %d %s: err = %v, want ErrOpen", aead, name, err)
+			}
+		}
+
+		// auth 模式：发送方公钥不符时解密失败
+		enc, ct, err = suite.SealAuth(skR.PublicKey(), nil, nil, []byte("from S"), skS)
+		if err != nil {
+			t.Fatalf("aead %d: SealAuth: %v", aead, err)
+		}
+		if pt, err := suite.OpenAuth(enc, skR, nil, nil, ct, skS.PublicKey()); err != nil || string(pt) != "from S" {
+			t.Fatalf("aead %d: OpenAuth = %q, %v", aead, pt, err)
+		}
+		impostor, _ := GenerateKeyPair()
+		if _, err := suite.OpenAuth(enc, skR, nil, nil, ct, impostor.PublicKey()); !errors.Is(err, ErrOpen) {
+			t.Errorf("aead %d wrong sender: err = %v, want ErrOpen", aead, err)
+		}
+		if _, err := suite.Open(enc, skR, nil, nil, ct); !errors.Is(err, ErrOpen) {
+			t.Errorf("aead %d auth opened as base: err = %v, want ErrOpen", aead, err)
+		}
+	}
+}
+
+func TestReceiverOrder(t *testing.T) {
+	suite, _ := NewSuite(DHKEMX25519HKDFSHA256, HKDFSHA256, AES128GCM)
+	skR, _ := GenerateKeyPair()
+	enc, sender, err := suite.SetupBaseS(skR.PublicKey(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receiver, err := suite.SetupBaseR(enc, skR, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _ := sender.Seal(nil, []byte("first"))
+	second, _ := sender.Seal(nil, []byte("second"))
+
+	// 乱序的消息解密失败且不推进序号
+	if _, err := receiver.Open(nil, second); !errors.Is(err, ErrOpen) {
+		t.Fatalf("out of order: err = %v, want ErrOpen", err)
+	}
+	for _, ct := range [][]byte{first, second} {
+		if _, err := receiver.Open(nil, ct); err != nil {
+			t.Fatalf("in order: %v", err)
+		}
+	}
+
+	sender.seq = ^uint64(0)
+	if _, err := sender.Seal(nil, []byte("x")); !errors.Is(err, ErrMessageLimit) {
+		t.Errorf("exhausted sequence: err = %v, want ErrMessageLimit", err)
+	}
+}
+
+func TestInvalidInputs(t *testing.T) {
+	if _, err := NewSuite(DHKEMX25519HKDFSHA256, HKDFSHA256, 0xffff); !errors.Is(err, ErrUnsupportedSuite) {
+		t.Errorf("export-only AEAD: err = %v, want ErrUnsupportedSuite", err)
+	}
+	if _, err := NewSuite(0x0010, HKDFSHA256, AES128GCM); !errors.Is(err, ErrUnsupportedSuite) {
+		t.Errorf("P-256 KEM: err = %v, want ErrUnsupportedSuite", err)
+	}
+
+	suite, _ := NewSuite(DHKEMX25519HKDFSHA256, HKDFSHA256, AES128GCM)
+	skR, _ := GenerateKeyPair()
+	for name, enc := range map[string][]byte{
+		"short":      make([]byte, 31),
+		"zero point": make([]byte, 32),
+	} {
+		if _, err := suite.Open(enc, skR, nil, nil, make([]byte, 16)); !errors.Is(err, ErrInvalidEncapsulation) {
+			t.Errorf("%s enc: err = %v, want ErrInvalidEncapsulation", name, err)
+		}
+	}
+
+	_, sender, _ := suite.SetupBaseS(skR.PublicKey(), nil)
+	if _, err := sender.Export(nil, 255*32+1); err == nil {
+		t.Error("oversized export: want error")
+	}
+}
+
+func TestMessage(t *testing.T) {
+	skR, _ := GenerateKeyPair()
+	skS, _ := GenerateKeyPair()
+	for _, sender := range []*ecdh.PrivateKey{nil, skS} {
+		m, err := Seal(AES256GCM, skR.PublicKey(), []byte("info"), []byte("aad"), []byte("message"), sender)
+		if err != nil {
+			t.Fatalf("Seal: %v", err)
+		}
+		if want := map[bool]string{true: "base", false: "auth"}[sender == nil]; m.Mode() != want {
+			t.Errorf("Mode() = %s, want %s", m.Mode(), want)
+		}
+		if pt, err := m.Open(skR); err != nil || string(pt) != "message" {
+			t.Fatalf("%s: Open = %q, %v", m.Mode(), pt, err)
+		}
+
+		wrongKID := *m
+		wrongKID.KID = "other"
+		if _, err := wrongKID.Open(skR); !errors.Is(err, ErrUnknownKeyID) {
+			t.Errorf("%s wrong kid: err = %v, want ErrUnknownKeyID", m.Mode(), err)
+		}
+		badEnc := *m
+		badEnc.Enc = "!!!"
+		if _, err := badEnc.Open(skR); err == nil {
+			t.Errorf("%s bad base64: want error", m.Mode())
+		}
+	}
+
+	// base 模式的消息被冒充成 auth 模式，或 auth 模式被改成 base 模式，都无法解密
+	m, _ := Seal(AES128GCM, skR.PublicKey(), nil, nil, []byte("message"), skS)
+	m.SenderPublicKey = ""
+	if _, err := m.Open(skR); !errors.Is(err, ErrOpen) {
+		t.Errorf("auth message opened as base: err = %v, want ErrOpen", err)
+	}
+}
@@ -0,0 +1,76 @@
+package hpke
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/keyfile"
+)
+
+// KeyID 接收方公钥的 SHA-256（Base64URL），用于确认发送方使用的公钥
+func KeyID(pub *ecdh.PublicKey) string {
+	sum := sha256.Sum256(pub.Bytes())
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// ParsePublicKey 解析 32 字节 X25519 公钥
+func ParsePublicKey(data []byte) (*ecdh.PublicKey, error) {
+	pub, err := ecdh.X25519().NewPublicKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("hpke: invalid X25519 public key: %v", err)
+	}
+	return pub, nil
+}
+
+// ParsePrivateKeyPEM 解析 PKCS#8 PEM 格式的 X25519 私钥
+func ParsePrivateKeyPEM(data []byte) (*ecdh.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode private key PEM")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %v", err)
+	}
+	priv, ok := key.(*ecdh.PrivateKey)
+	if !ok || priv.Curve() != ecdh.X25519() {
+		return nil, fmt.Errorf("private key is not X25519 type")
+	}
+	return priv, nil
+}
+
+// LoadFile 从文件读取 X25519 私钥；generate 为 true 时文件不存在则生成并以 0600 权限保存
+func LoadFile(path string, generate bool) (*ecdh.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return ParsePrivateKeyPEM(data)
+	}
+	if !errors.Is(err, os.ErrNotExist) || !generate {
+		return nil, fmt.Errorf("failed to read HPKE key file: %v", err)
+	}
+
+	key, err := GenerateKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal private key: %v", err)
+	}
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if data, err = keyfile.Persist(path, pemBytes); err != nil {
+		return nil, fmt.Errorf("failed to persist HPKE key file: %v", err)
+	}
+	// 并发启动时其他进程可能先保存了私钥，以文件中的为准
+	if !bytes.Equal(data, pemBytes) {
+		return ParsePrivateKeyPEM(data)
+	}
+	return key, nil
+}
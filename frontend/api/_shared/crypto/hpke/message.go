@@ -0,0 +1,94 @@
+package hpke
+
+import (
+	"crypto/ecdh"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownKeyID kid 与接收方公钥不一致
+var ErrUnknownKeyID = errors.New("hpke: unknown key id")
+
+// Message 单次 HPKE 消息的传输结构（DHKEM(X25519, HKDF-SHA256) + HKDF-SHA256），二进制字段均为标准 Base64；
+// SenderPublicKey 不为空时按 auth 模式解密
+type Message struct {
+	AEAD            AEAD   `json:"aead,omitempty"` // AEAD 标识，默认 AES-128-GCM（0x0001）
+	Enc             string `json:"enc"`
+	Ciphertext      string `json:"ciphertext"`
+	Info            string `json:"info,omitempty"`
+	AAD             string `json:"aad,omitempty"`
+	SenderPublicKey string `json:"senderPublicKey,omitempty"`
+	KID             string `json:"kid,omitempty"` // 接收方公钥的 kid
+}
+
+// Seal 以 base 模式（skS 为 nil）或 auth 模式向 pkR 加密一条消息
+func Seal(aead AEAD, pkR *ecdh.PublicKey, info, aad, plainText []byte, skS *ecdh.PrivateKey) (*Message, error) {
+	s, err := NewSuite(DHKEMX25519HKDFSHA256, HKDFSHA256, aead)
+	if err != nil {
+		return nil, err
+	}
+	var enc, ct []byte
+	if skS != nil {
+		enc, ct, err = s.SealAuth(pkR, info, aad, plainText, skS)
+	} else {
+		enc, ct, err = s.Seal(pkR, info, aad, plainText)
+	}
+	if err != nil {
+		return nil, err
+	}
+	m := &Message{
+		AEAD:       aead,
+		Enc:        base64.StdEncoding.EncodeToString(enc),
+		Ciphertext: base64.StdEncoding.EncodeToString(ct),
+		Info:       base64.StdEncoding.EncodeToString(info),
+		AAD:        base64.StdEncoding.EncodeToString(aad),
+		KID:        KeyID(pkR),
+	}
+	if skS != nil {
+		m.SenderPublicKey = base64.StdEncoding.EncodeToString(skS.PublicKey().Bytes())
+	}
+	return m, nil
+}
+
+// Open 用接收方私钥解密消息
+func (m *Message) Open(skR *ecdh.PrivateKey) ([]byte, error) {
+	if m.KID != "" && m.KID != KeyID(skR.PublicKey()) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKeyID, m.KID)
+	}
+	aead := m.AEAD
+	if aead == 0 {
+		aead = AES128GCM
+	}
+	s, err := NewSuite(DHKEMX25519HKDFSHA256, HKDFSHA256, aead)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]string{"enc": m.Enc, "ciphertext": m.Ciphertext, "info": m.Info, "aad": m.AAD, "senderPublicKey": m.SenderPublicKey}
+	decoded := map[string][]byte{}
+	for name, v := range fields {
+		b, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, fmt.Errorf("hpke: %s base64 decode failed: %v", name, err)
+		}
+		decoded[name] = b
+	}
+
+	if m.SenderPublicKey == "" {
+		return s.Open(decoded["enc"], skR, decoded["info"], decoded["aad"], decoded["ciphertext"])
+	}
+	pkS, err := ParsePublicKey(decoded["senderPublicKey"])
+	if err != nil {
+		return nil, err
+	}
+	return s.OpenAuth(decoded["enc"], skR, decoded["info"], decoded["aad"], decoded["ciphertext"], pkS)
+}
+
+// Mode 消息的 HPKE 模式名
+func (m *Message) Mode() string {
+	if m.SenderPublicKey != "" {
+		return "auth"
+	}
+	return "base"
+}
@@ -0,0 +1,96 @@
+// Package hybrid 实现 RSA-OAEP + AES-GCM 混合加密。
+//
+// 客户端随机生成 32 字节 AES-256 密钥，用服务端 RSA 公钥以 OAEP-SHA256 包裹该密钥，
+// 再用该密钥以 AES-GCM 加密任意长度的正文。服务端用同一 RSA 私钥解包密钥后解密正文，
+// 从而突破单个 RSA-OAEP 分组（2048 位密钥约 190 字节）的长度限制。
+// 解包时可通过 Payload.Padding 改用其他 OAEP 哈希或 PKCS#1 v1.5。
+package hybrid
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/rsapad"
+	"github.com/LeeeeeeM/aes-go-js/pkg/jsaes"
+)
+
+// KeySize 被包裹的内容密钥长度（AES-256）
+const KeySize = 32
+
+// ErrKeyUnwrap 内容密钥解包失败（RSA 私钥不匹配或密文被篡改）
+var ErrKeyUnwrap = errors.New("hybrid: key unwrap failed")
+
+// Payload 混合加密的传输结构，各字段均为标准 Base64
+type Payload struct {
+	EncryptedKey  string `json:"encryptedKey"`  // RSA-OAEP-SHA256 包裹的 AES-256 密钥
+	IV            string `json:"iv"`            // 12 字节 GCM IV
+	EncryptedData string `json:"encryptedData"` // 密文 + 16 字节认证标签
+	KID           string `json:"kid,omitempty"` // 包裹所用 RSA 公钥的 kid
+	// Padding 包裹内容密钥所用的填充方案，为空时为 OAEP-SHA256
+	Padding *rsapad.Padding `json:"padding,omitempty"`
+}
+
+// Encrypt 生成随机内容密钥，用 RSA 公钥包裹并用 AES-GCM 加密正文
+func Encrypt(publicKey *rsa.PublicKey, plainText, aad []byte) (*Payload, error) {
+	key := make([]byte, KeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+
+	encryptedKey, err := rsa.EncryptOAEP(crypto.SHA256.New(), rand.Reader, publicKey, key, nil)
+	if err != nil {
+		return nil, fmt.Errorf("RSA key wrap failed: %v", err)
+	}
+
+	c, err := jsaes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	cipherB64, ivB64, err := c.EncryptWithAAD(plainText, aad)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Payload{
+		EncryptedKey:  base64.StdEncoding.EncodeToString(encryptedKey),
+		IV:            ivB64,
+		EncryptedData: cipherB64,
+	}, nil
+}
+
+// UnwrapKey 用 RSA 私钥按 padding（nil 为 OAEP-SHA256）解包 Base64 编码的密文，返回原始密钥字节；
+// PKCS#1 v1.5 只能解包 KeySize 字节的密钥
+func UnwrapKey(privateKey *rsa.PrivateKey, encryptedKeyB64 string, padding *rsapad.Padding) ([]byte, error) {
+	encryptedKey, err := base64.StdEncoding.DecodeString(encryptedKeyB64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: encrypted key base64 decode failed: %v", jsaes.ErrInvalidBase64, err)
+	}
+
+	key, err := padding.UnwrapKey(privateKey, encryptedKey, KeySize)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyUnwrap, err)
+	}
+	return key, nil
+}
+
+// Decrypt 用 RSA 私钥解包内容密钥并解密正文
+func Decrypt(privateKey *rsa.PrivateKey, p *Payload, aad []byte) ([]byte, error) {
+	key, err := UnwrapKey(privateKey, p.EncryptedKey, p.Padding)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("%w: 内容密钥必须是%d字节，实际是%d字节", jsaes.ErrInvalidKeySize, KeySize, len(key))
+	}
+
+	c, err := jsaes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return c.DecryptWithAAD(p.EncryptedData, p.IV, aad)
+}
@@ -0,0 +1,117 @@
+package hybrid
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/rsapad"
+	"github.com/LeeeeeeM/aes-go-js/pkg/jsaes"
+)
+
+func generateKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestRoundTrip(t *testing.T) {
+	key := generateKey(t)
+	plainText := make([]byte, 4096) // 超过单个 RSA 分组
+	aad := []byte("tenant-1")
+
+	p, err := Encrypt(&key.PublicKey, plainText, aad)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	got, err := Decrypt(key, p, aad)
+	if err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	if len(got) != len(plainText) {
+		t.Fatalf("Decrypt returned %d bytes, want %d", len(got), len(plainText))
+	}
+
+	if _, err := Decrypt(key, p, []byte("tenant-2")); !errors.Is(err, jsaes.ErrAuthFailed) {
+		t.Errorf("wrong aad: err = %v, want ErrAuthFailed", err)
+	}
+}
+
+func TestTamper(t *testing.T) {
+	key := generateKey(t)
+	p, err := Encrypt(&key.PublicKey, []byte("hello"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flip := func(b64 string) string {
+		b, _ := base64.StdEncoding.DecodeString(b64)
+		b[len(b)/2] ^= 1
+		return base64.StdEncoding.EncodeToString(b)
+	}
+
+	badKey := *p
+	badKey.EncryptedKey = flip(p.EncryptedKey)
+	if _, err := Decrypt(key, &badKey, nil); !errors.Is(err, ErrKeyUnwrap) {
+		t.Errorf("tampered key: err = %v, want ErrKeyUnwrap", err)
+	}
+
+	badData := *p
+	badData.EncryptedData = flip(p.EncryptedData)
+	if _, err := Decrypt(key, &badData, nil); !errors.Is(err, jsaes.ErrAuthFailed) {
+		t.Errorf("tampered data: err = %v, want ErrAuthFailed", err)
+	}
+
+	if _, err := Decrypt(generateKey(t), p, nil); !errors.Is(err, ErrKeyUnwrap) {
+		t.Errorf("wrong private key: err = %v, want ErrKeyUnwrap", err)
+	}
+
+	badB64 := *p
+	badB64.EncryptedKey = "!!!"
+	if _, err := Decrypt(key, &badB64, nil); !errors.Is(err, jsaes.ErrInvalidBase64) {
+		t.Errorf("bad base64: err = %v, want ErrInvalidBase64", err)
+	}
+}
+
+func TestPKCS1v15(t *testing.T) {
+	key := generateKey(t)
+	cek := make([]byte, KeySize)
+	rand.Read(cek)
+	wrapped, err := rsa.EncryptPKCS1v15(rand.Reader, &key.PublicKey, cek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ := jsaes.NewCipher(cek)
+	cipherB64, ivB64, err := c.Encrypt([]byte("legacy client"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Payload{
+		EncryptedKey:  base64.StdEncoding.EncodeToString(wrapped),
+		IV:            ivB64,
+		EncryptedData: cipherB64,
+		Padding:       &rsapad.Padding{Scheme: rsapad.SchemePKCS1v15},
+	}
+	got, err := Decrypt(key, p, nil)
+	if err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	if string(got) != "legacy client" {
+		t.Fatalf("Decrypt = %q", got)
+	}
+
+	// 隐式拒绝：包裹密钥被篡改时不报解包错误，而是在 AEAD 认证时失败。
+	// 只改最低字节，密文仍小于模数（换用另一把私钥时密文可能大于其模数，这是公开信息，会直接报错）
+	tampered := *p
+	wrapped[len(wrapped)-1] ^= 0x01
+	tampered.EncryptedKey = base64.StdEncoding.EncodeToString(wrapped)
+	if _, err := Decrypt(key, &tampered, nil); !errors.Is(err, jsaes.ErrAuthFailed) {
+		t.Errorf("tampered wrapped key: err = %v, want ErrAuthFailed", err)
+	}
+}
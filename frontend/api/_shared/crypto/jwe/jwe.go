@@ -0,0 +1,221 @@
+// Package jwe 实现 JWE 紧凑序列化（RFC 7516），密钥管理为 RSA-OAEP-256，
+// 内容加密为 A128GCM / A256GCM（RFC 7518 §4.3、§5.3）。
+//
+// 紧凑格式为五段 Base64URL（无填充）以 "." 连接：
+//
+//	protectedHeader.encryptedKey.iv.ciphertext.tag
+//
+// 内容加密的附加认证数据是 protectedHeader 段的 ASCII 字节，因此头部不可篡改。
+// 标准 JOSE 库（jose、node-jose、go-jose 等）生成的令牌可以直接解密。
+package jwe
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/LeeeeeeM/aes-go-js/pkg/jsaes"
+)
+
+// 支持的算法
+const (
+	AlgRSAOAEP256 = "RSA-OAEP-256"
+	EncA128GCM    = "A128GCM"
+	EncA256GCM    = "A256GCM"
+)
+
+var (
+	// ErrInvalidToken 不是合法的 JWE 紧凑序列化
+	ErrInvalidToken = errors.New("jwe: invalid compact token")
+	// ErrUnsupportedAlgorithm alg / enc 不受支持，或使用了 zip、crit 等未实现的头部参数
+	ErrUnsupportedAlgorithm = errors.New("jwe: unsupported algorithm")
+	// ErrDecryptFailed 内容密钥不匹配或令牌被篡改
+	ErrDecryptFailed = errors.New("jwe: decryption failed")
+)
+
+// Header JWE 受保护头部
+type Header struct {
+	Alg  string   `json:"alg"`
+	Enc  string   `json:"enc"`
+	Kid  string   `json:"kid,omitempty"`
+	Typ  string   `json:"typ,omitempty"`
+	Cty  string   `json:"cty,omitempty"`
+	Zip  string   `json:"zip,omitempty"`
+	Crit []string `json:"crit,omitempty"`
+}
+
+// Token 解析后的 JWE 紧凑令牌
+type Token struct {
+	Header       Header
+	protected    string // 原始受保护头部段，作为 AAD
+	EncryptedKey []byte
+	IV           []byte
+	Ciphertext   []byte
+	Tag          []byte
+}
+
+// cekSize 返回 enc 对应的内容密钥长度
+func cekSize(enc string) (int, error) {
+	switch enc {
+	case EncA128GCM:
+		return 16, nil
+	case EncA256GCM:
+		return 32, nil
+	default:
+		return 0, fmt.Errorf("%w: enc %q", ErrUnsupportedAlgorithm, enc)
+	}
+}
+
+// validate 检查头部是否为本包支持的组合
+func (h *Header) validate() error {
+	if h.Alg != AlgRSAOAEP256 {
+		return fmt.Errorf("%w: alg %q", ErrUnsupportedAlgorithm, h.Alg)
+	}
+	if _, err := cekSize(h.Enc); err != nil {
+		return err
+	}
+	if h.Zip != "" {
+		return fmt.Errorf("%w: zip %q", ErrUnsupportedAlgorithm, h.Zip)
+	}
+	// RFC 7516 §4.1.13：crit 中的扩展必须被理解，本包不支持任何扩展
+	if len(h.Crit) > 0 {
+		return fmt.Errorf("%w: crit %v", ErrUnsupportedAlgorithm, h.Crit)
+	}
+	return nil
+}
+
+// Parse 解析 JWE 紧凑令牌并校验头部
+func Parse(compact string) (*Token, error) {
+	parts := strings.Split(strings.TrimSpace(compact), ".")
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("%w: expected 5 parts, got %d", ErrInvalidToken, len(parts))
+	}
+
+	var decoded [5][]byte
+	for i, p := range parts {
+		b, err := base64.RawURLEncoding.DecodeString(p)
+		if err != nil {
+			return nil, fmt.Errorf("%w: part %d base64url decode failed: %v", ErrInvalidToken, i, err)
+		}
+		decoded[i] = b
+	}
+
+	t := &Token{
+		protected:    parts[0],
+		EncryptedKey: decoded[1],
+		IV:           decoded[2],
+		Ciphertext:   decoded[3],
+		Tag:          decoded[4],
+	}
+	if err := json.Unmarshal(decoded[0], &t.Header); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidToken, err)
+	}
+	if err := t.Header.validate(); err != nil {
+		return nil, err
+	}
+	if len(t.IV) != 12 || len(t.Tag) != 16 {
+		return nil, fmt.Errorf("%w: GCM IV must be 12 bytes and tag 16 bytes", ErrInvalidToken)
+	}
+	return t, nil
+}
+
+// Decrypt 用 RSA 私钥解包内容密钥并解密正文
+//
+// 内容密钥解包失败或长度不符时改用随机密钥继续解密（RFC 7516 §11.5），
+// 使两种失败都表现为同一个 ErrDecryptFailed，避免成为 RSA 填充预言机。
+func (t *Token) Decrypt(privateKey *rsa.PrivateKey) ([]byte, error) {
+	size, err := cekSize(t.Header.Enc)
+	if err != nil {
+		return nil, err
+	}
+
+	cek, err := rsa.DecryptOAEP(sha256.New(), nil, privateKey, t.EncryptedKey, nil)
+	if err != nil || len(cek) != size {
+		cek = make([]byte, size)
+		if _, err := io.ReadFull(rand.Reader, cek); err != nil {
+			return nil, err
+		}
+	}
+
+	c, err := jsaes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	sealed := append(append([]byte{}, t.Ciphertext...), t.Tag...)
+	plainText, err := c.OpenWithAAD(t.IV, sealed, []byte(t.protected))
+	if err != nil {
+		return nil, ErrDecryptFailed
+	}
+	return plainText, nil
+}
+
+// Decrypt 解析并解密 JWE 紧凑令牌，同时返回头部
+func Decrypt(privateKey *rsa.PrivateKey, compact string) ([]byte, *Header, error) {
+	t, err := Parse(compact)
+	if err != nil {
+		return nil, nil, err
+	}
+	plainText, err := t.Decrypt(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return plainText, &t.Header, nil
+}
+
+// Encrypt 用 RSA 公钥生成 JWE 紧凑令牌；header 的 Alg 为空时取 RSA-OAEP-256，Enc 为空时取 A256GCM
+func Encrypt(publicKey *rsa.PublicKey, plainText []byte, header Header) (string, error) {
+	if header.Alg == "" {
+		header.Alg = AlgRSAOAEP256
+	}
+	if header.Enc == "" {
+		header.Enc = EncA256GCM
+	}
+	if err := header.validate(); err != nil {
+		return "", err
+	}
+	size, _ := cekSize(header.Enc)
+
+	cek := make([]byte, size)
+	if _, err := io.ReadFull(rand.Reader, cek); err != nil {
+		return "", err
+	}
+	encryptedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, publicKey, cek, nil)
+	if err != nil {
+		return "", fmt.Errorf("RSA key wrap failed: %v", err)
+	}
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	protected := base64.RawURLEncoding.EncodeToString(headerJSON)
+
+	c, err := jsaes.NewCipher(cek)
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, c.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return "", err
+	}
+	sealed, err := c.SealWithAAD(iv, plainText, []byte(protected))
+	if err != nil {
+		return "", err
+	}
+	tagStart := len(sealed) - 16
+
+	enc := base64.RawURLEncoding
+	return strings.Join([]string{
+		protected,
+		enc.EncodeToString(encryptedKey),
+		enc.EncodeToString(iv),
+		enc.EncodeToString(sealed[:tagStart]),
+		enc.EncodeToString(sealed[tagStart:]),
+	}, "."), nil
+}
@@ -0,0 +1,117 @@
+package jwe
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func generateKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestRoundTrip(t *testing.T) {
+	key := generateKey(t)
+	for _, enc := range []string{EncA128GCM, EncA256GCM} {
+		t.Run(enc, func(t *testing.T) {
+			compact, err := Encrypt(&key.PublicKey, []byte(`{"hello":"world"}`), Header{Enc: enc, Kid: "k1", Cty: "json"})
+			if err != nil {
+				t.Fatalf("Encrypt: %v", err)
+			}
+			if n := strings.Count(compact, "."); n != 4 {
+				t.Fatalf("compact token has %d dots, want 4", n)
+			}
+
+			plainText, header, err := Decrypt(key, compact)
+			if err != nil {
+				t.Fatalf("Decrypt: %v", err)
+			}
+			if string(plainText) != `{"hello":"world"}` {
+				t.Errorf("plaintext = %q", plainText)
+			}
+			if header.Alg != AlgRSAOAEP256 || header.Enc != enc || header.Kid != "k1" || header.Cty != "json" {
+				t.Errorf("header = %+v", header)
+			}
+		})
+	}
+}
+
+func TestTamper(t *testing.T) {
+	key := generateKey(t)
+	compact, err := Encrypt(&key.PublicKey, []byte("secret"), Header{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(compact, ".")
+
+	// 头部改动后仍能解析，但作为 AAD 不再匹配
+	header, _ := json.Marshal(Header{Alg: AlgRSAOAEP256, Enc: EncA256GCM, Kid: "other"})
+	tampered := map[string]int{"encrypted key": 1, "iv": 2, "ciphertext": 3, "tag": 4}
+	for name, i := range tampered {
+		t.Run(name, func(t *testing.T) {
+			b, _ := base64.RawURLEncoding.DecodeString(parts[i])
+			b[0] ^= 1
+			p := append([]string(nil), parts...)
+			p[i] = base64.RawURLEncoding.EncodeToString(b)
+			if _, _, err := Decrypt(key, strings.Join(p, ".")); !errors.Is(err, ErrDecryptFailed) {
+				t.Errorf("err = %v, want ErrDecryptFailed", err)
+			}
+		})
+	}
+	t.Run("header", func(t *testing.T) {
+		p := append([]string(nil), parts...)
+		p[0] = base64.RawURLEncoding.EncodeToString(header)
+		if _, _, err := Decrypt(key, strings.Join(p, ".")); !errors.Is(err, ErrDecryptFailed) {
+			t.Errorf("err = %v, want ErrDecryptFailed", err)
+		}
+	})
+	t.Run("wrong key", func(t *testing.T) {
+		if _, _, err := Decrypt(generateKey(t), compact); !errors.Is(err, ErrDecryptFailed) {
+			t.Errorf("err = %v, want ErrDecryptFailed", err)
+		}
+	})
+}
+
+func TestParseRejects(t *testing.T) {
+	b64 := func(v any) string {
+		data, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	iv := base64.RawURLEncoding.EncodeToString(make([]byte, 12))
+	tag := base64.RawURLEncoding.EncodeToString(make([]byte, 16))
+	token := func(header any) string {
+		return strings.Join([]string{b64(header), "AA", iv, "AA", tag}, ".")
+	}
+
+	tests := []struct {
+		name    string
+		compact string
+		want    error
+	}{
+		{"four parts", "a.b.c.d", ErrInvalidToken},
+		{"bad base64", "a.b.c.d.!!", ErrInvalidToken},
+		{"bad header json", strings.Join([]string{"bm90LWpzb24", "AA", iv, "AA", tag}, "."), ErrInvalidToken},
+		{"short iv", strings.Join([]string{b64(Header{Alg: AlgRSAOAEP256, Enc: EncA256GCM}), "AA", "AAAA", "AA", tag}, "."), ErrInvalidToken},
+		{"alg RSA1_5", token(Header{Alg: "RSA1_5", Enc: EncA256GCM}), ErrUnsupportedAlgorithm},
+		{"alg dir", token(Header{Alg: "dir", Enc: EncA256GCM}), ErrUnsupportedAlgorithm},
+		{"enc CBC", token(Header{Alg: AlgRSAOAEP256, Enc: "A128CBC-HS256"}), ErrUnsupportedAlgorithm},
+		{"zip", token(Header{Alg: AlgRSAOAEP256, Enc: EncA256GCM, Zip: "DEF"}), ErrUnsupportedAlgorithm},
+		{"crit", token(Header{Alg: AlgRSAOAEP256, Enc: EncA256GCM, Crit: []string{"exp"}}), ErrUnsupportedAlgorithm},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.compact); !errors.Is(err, tt.want) {
+				t.Errorf("Parse: err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
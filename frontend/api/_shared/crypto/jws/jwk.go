@@ -0,0 +1,150 @@
+package jws
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/rsakey"
+)
+
+// JWK 签名验证公钥的 JWK 表示（RSA、EC P-256 或 OKP/Ed25519）
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+// JWKSet JWK 集合
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicJWK 导出公钥 JWK，kid 为 RFC 7638 指纹
+func PublicJWK(pub crypto.PublicKey, alg string) (JWK, error) {
+	kid, err := KeyID(pub)
+	if err != nil {
+		return JWK{}, err
+	}
+	j := JWK{Kid: kid, Alg: alg, Use: "sig"}
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		r := rsakey.PublicJWK(k)
+		j.Kty, j.N, j.E = "RSA", r.N, r.E
+	case *ecdsa.PublicKey:
+		x, y, err := ecCoordinates(k)
+		if err != nil {
+			return JWK{}, err
+		}
+		j.Kty, j.Crv, j.X, j.Y = "EC", "P-256", x, y
+	case ed25519.PublicKey:
+		j.Kty, j.Crv, j.X = "OKP", "Ed25519", base64.RawURLEncoding.EncodeToString(k)
+	}
+	return j, nil
+}
+
+// PublicKey 解析 JWK 中的公钥
+func (j JWK) PublicKey() (crypto.PublicKey, error) {
+	switch j.Kty {
+	case "RSA":
+		return rsakey.JWK{Kty: j.Kty, N: j.N, E: j.E}.PublicKey()
+	case "EC":
+		if j.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported JWK curve: %q", j.Crv)
+		}
+		x, errX := base64.RawURLEncoding.DecodeString(j.X)
+		y, errY := base64.RawURLEncoding.DecodeString(j.Y)
+		if errX != nil || errY != nil || len(x) != 32 || len(y) != 32 {
+			return nil, fmt.Errorf("invalid EC JWK coordinates")
+		}
+		// 经由 crypto/ecdh 解析未压缩点编码，确保点在曲线上
+		point := append(append([]byte{4}, x...), y...)
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, fmt.Errorf("invalid EC JWK: %v", err)
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil || j.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 JWK")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported JWK key type: %q", j.Kty)
+	}
+}
+
+// ParsePublicJWK 解析单个公钥 JWK
+func ParsePublicJWK(data []byte) (crypto.PublicKey, error) {
+	var j JWK
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, fmt.Errorf("failed to decode JWK: %v", err)
+	}
+	return j.PublicKey()
+}
+
+// ParsePublicKeyPEM 解析 PKIX（SubjectPublicKeyInfo）PEM 格式的验证公钥
+func ParsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode public key PEM")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %v", err)
+	}
+	if len(Algorithms(pub)) == 0 {
+		return nil, fmt.Errorf("unsupported public key type %T", pub)
+	}
+	return pub, nil
+}
+
+// KeyID 计算公钥的 RFC 7638 JWK 指纹（RSA 与 rsakey.KeyID 一致）
+func KeyID(pub crypto.PublicKey) (string, error) {
+	var canonical string
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return rsakey.KeyID(k), nil
+	case *ecdsa.PublicKey:
+		x, y, err := ecCoordinates(k)
+		if err != nil {
+			return "", err
+		}
+		canonical = `{"crv":"P-256","kty":"EC","x":"` + x + `","y":"` + y + `"}`
+	case ed25519.PublicKey:
+		canonical = `{"crv":"Ed25519","kty":"OKP","x":"` + base64.RawURLEncoding.EncodeToString(k) + `"}`
+	default:
+		return "", fmt.Errorf("unsupported key type %T", pub)
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// ecCoordinates 返回 P-256 公钥的 Base64URL 坐标（定长 32 字节）
+func ecCoordinates(k *ecdsa.PublicKey) (string, string, error) {
+	if k.Curve != elliptic.P256() {
+		return "", "", fmt.Errorf("unsupported EC curve: %s", k.Curve.Params().Name)
+	}
+	pub, err := k.ECDH()
+	if err != nil {
+		return "", "", err
+	}
+	point := pub.Bytes()
+	return base64.RawURLEncoding.EncodeToString(point[1:33]), base64.RawURLEncoding.EncodeToString(point[33:]), nil
+}
@@ -0,0 +1,232 @@
+// Package jws 实现 RFC 7515 JWS Compact 序列化的签名与验证，默认输出分离载荷形式
+// （RFC 7515 附录 F）：<header>..<signature>，载荷由调用方另行传输。
+//
+// 支持的算法：
+//
+//	PS256  RSASSA-PSS SHA-256（盐长度等于哈希长度）
+//	RS256  RSASSA-PKCS1-v1_5 SHA-256
+//	ES256  ECDSA P-256 SHA-256（签名为定长 R || S）
+//	EdDSA  Ed25519（RFC 8037）
+package jws
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// 签名算法（JOSE 命名）
+const (
+	AlgPS256 = "PS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgEdDSA = "EdDSA"
+)
+
+const es256Size = 32
+
+var (
+	// ErrInvalidToken JWS 格式错误
+	ErrInvalidToken = errors.New("jws: invalid token")
+	// ErrUnsupportedAlgorithm 算法不受支持或与密钥类型不匹配
+	ErrUnsupportedAlgorithm = errors.New("jws: unsupported algorithm")
+	// ErrInvalidSignature 签名校验失败
+	ErrInvalidSignature = errors.New("jws: invalid signature")
+)
+
+// Header JWS 受保护头部
+type Header struct {
+	Alg  string   `json:"alg"`
+	Kid  string   `json:"kid,omitempty"`
+	Typ  string   `json:"typ,omitempty"`
+	Cty  string   `json:"cty,omitempty"`
+	Crit []string `json:"crit,omitempty"`
+}
+
+// Algorithms 返回 key 可用的签名算法：RSA 为 PS256 和 RS256，P-256 为 ES256，Ed25519 为 EdDSA
+func Algorithms(key crypto.PublicKey) []string {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return []string{AlgPS256, AlgRS256}
+	case *ecdsa.PublicKey:
+		if k.Curve.Params().Name == "P-256" {
+			return []string{AlgES256}
+		}
+	case ed25519.PublicKey:
+		return []string{AlgEdDSA}
+	}
+	return nil
+}
+
+// checkAlgorithm 确认 alg 适用于 key，防止算法混淆
+func checkAlgorithm(alg string, key crypto.PublicKey) error {
+	for _, a := range Algorithms(key) {
+		if a == alg {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q for key type %T", ErrUnsupportedAlgorithm, alg, key)
+}
+
+// Sign 用 key 对 payload 签名，返回分离载荷的 JWS Compact 串；header.Alg 为空时取 key 的首选算法
+func Sign(key crypto.Signer, header Header, payload []byte) (string, error) {
+	if header.Alg == "" {
+		if algs := Algorithms(key.Public()); len(algs) > 0 {
+			header.Alg = algs[0]
+		}
+	}
+	if err := checkAlgorithm(header.Alg, key.Public()); err != nil {
+		return "", err
+	}
+
+	h, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	protected := base64.RawURLEncoding.EncodeToString(h)
+	input := signingInput(protected, payload)
+
+	var sig []byte
+	switch header.Alg {
+	case AlgPS256:
+		digest := sha256.Sum256(input)
+		sig, err = key.Sign(rand.Reader, digest[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256})
+	case AlgRS256:
+		digest := sha256.Sum256(input)
+		sig, err = key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	case AlgES256:
+		digest := sha256.Sum256(input)
+		sig, err = signES256(key, digest[:])
+	case AlgEdDSA:
+		sig, err = key.Sign(nil, input, crypto.Hash(0))
+	}
+	if err != nil {
+		return "", err
+	}
+	return protected + ".." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// signES256 生成 ECDSA 签名并转换为 JWS 要求的定长 R || S 编码
+func signES256(key crypto.Signer, digest []byte) ([]byte, error) {
+	der, err := key.Sign(rand.Reader, digest, crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	var sig struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(der, &sig); err != nil {
+		return nil, fmt.Errorf("jws: malformed ECDSA signature: %v", err)
+	}
+	out := make([]byte, 2*es256Size)
+	sig.R.FillBytes(out[:es256Size])
+	sig.S.FillBytes(out[es256Size:])
+	return out, nil
+}
+
+// signingInput 构造 ASCII(BASE64URL(header) || '.' || BASE64URL(payload))
+func signingInput(protected string, payload []byte) []byte {
+	return []byte(protected + "." + base64.RawURLEncoding.EncodeToString(payload))
+}
+
+// Token 解析后的 JWS
+type Token struct {
+	Header
+	protected string
+	// Payload 内嵌载荷；分离形式时为 nil
+	Payload   []byte
+	Signature []byte
+}
+
+// Detached 载荷是否分离
+func (t *Token) Detached() bool {
+	return t.Payload == nil
+}
+
+// Parse 解析 JWS Compact 串（分离或内嵌载荷），不校验签名
+func Parse(compact string) (*Token, error) {
+	parts := strings.Split(strings.TrimSpace(compact), ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: expected 3 parts, got %d", ErrInvalidToken, len(parts))
+	}
+
+	h, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidToken, err)
+	}
+	t := &Token{protected: parts[0]}
+	if err := json.Unmarshal(h, &t.Header); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidToken, err)
+	}
+	// 不理解的关键扩展（如 RFC 7797 b64）必须拒绝
+	if len(t.Crit) > 0 {
+		return nil, fmt.Errorf("%w: unsupported crit %v", ErrInvalidToken, t.Crit)
+	}
+	if parts[1] != "" {
+		if t.Payload, err = base64.RawURLEncoding.DecodeString(parts[1]); err != nil {
+			return nil, fmt.Errorf("%w: payload: %v", ErrInvalidToken, err)
+		}
+	}
+	if t.Signature, err = base64.RawURLEncoding.DecodeString(parts[2]); err != nil || len(t.Signature) == 0 {
+		return nil, fmt.Errorf("%w: bad signature encoding", ErrInvalidToken)
+	}
+	return t, nil
+}
+
+// Verify 用公钥校验签名；分离形式时 payload 为被签名的载荷，内嵌形式时 payload 须为空或与内嵌载荷一致
+func (t *Token) Verify(pub crypto.PublicKey, payload []byte) error {
+	if !t.Detached() {
+		if payload != nil && subtle.ConstantTimeCompare(payload, t.Payload) != 1 {
+			return fmt.Errorf("%w: payload mismatch", ErrInvalidSignature)
+		}
+		payload = t.Payload
+	}
+	if err := checkAlgorithm(t.Alg, pub); err != nil {
+		return err
+	}
+
+	input := signingInput(t.protected, payload)
+	digest := sha256.Sum256(input)
+	ok := false
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		if t.Alg == AlgPS256 {
+			ok = rsa.VerifyPSS(k, crypto.SHA256, digest[:], t.Signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil
+		} else {
+			ok = rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], t.Signature) == nil
+		}
+	case *ecdsa.PublicKey:
+		if len(t.Signature) == 2*es256Size {
+			r := new(big.Int).SetBytes(t.Signature[:es256Size])
+			s := new(big.Int).SetBytes(t.Signature[es256Size:])
+			ok = ecdsa.Verify(k, digest[:], r, s)
+		}
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(k, input, t.Signature)
+	}
+	if !ok {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Verify 解析并校验 JWS，返回受保护头部
+func Verify(pub crypto.PublicKey, compact string, payload []byte) (*Header, error) {
+	t, err := Parse(compact)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.Verify(pub, payload); err != nil {
+		return nil, err
+	}
+	return &t.Header, nil
+}
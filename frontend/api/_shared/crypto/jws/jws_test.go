@@ -0,0 +1,215 @@
+package jws
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func generateKeys(t *testing.T) (*rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey) {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rsaKey, ecKey, edKey
+}
+
+func TestSignVerify(t *testing.T) {
+	rsaKey, ecKey, edKey := generateKeys(t)
+	tests := []struct {
+		alg string
+		key crypto.Signer
+	}{
+		{AlgPS256, rsaKey},
+		{AlgRS256, rsaKey},
+		{AlgES256, ecKey},
+		{AlgEdDSA, edKey},
+	}
+	payload := []byte("合同全文")
+	for _, tt := range tests {
+		t.Run(tt.alg, func(t *testing.T) {
+			compact, err := Sign(tt.key, Header{Alg: tt.alg, Kid: "k1"}, payload)
+			if err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+			if parts := strings.Split(compact, "."); len(parts) != 3 || parts[1] != "" {
+				t.Fatalf("Sign = %q, want detached payload", compact)
+			}
+			h, err := Verify(tt.key.Public(), compact, payload)
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if h.Alg != tt.alg || h.Kid != "k1" {
+				t.Errorf("header = %+v", h)
+			}
+
+			if _, err := Verify(tt.key.Public(), compact, []byte("合同全文!")); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("tampered payload: err = %v, want ErrInvalidSignature", err)
+			}
+			// 改写受保护头部（kid）会使签名失效
+			parts := strings.Split(compact, ".")
+			forged, _ := json.Marshal(Header{Alg: tt.alg, Kid: "k2"})
+			parts[0] = base64.RawURLEncoding.EncodeToString(forged)
+			if _, err := Verify(tt.key.Public(), strings.Join(parts, "."), payload); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("tampered header: err = %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+}
+
+func TestEmbeddedPayload(t *testing.T) {
+	_, _, edKey := generateKeys(t)
+	detached, err := Sign(edKey, Header{}, []byte("doc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(detached, ".")
+	embedded := parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte("doc")) + "." + parts[2]
+
+	if _, err := Verify(edKey.Public(), embedded, nil); err != nil {
+		t.Errorf("embedded without payload: %v", err)
+	}
+	if _, err := Verify(edKey.Public(), embedded, []byte("doc")); err != nil {
+		t.Errorf("embedded with matching payload: %v", err)
+	}
+	if _, err := Verify(edKey.Public(), embedded, []byte("other")); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("embedded with other payload: err = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestAlgorithmConfusion(t *testing.T) {
+	rsaKey, ecKey, edKey := generateKeys(t)
+	if _, err := Sign(edKey, Header{Alg: AlgPS256}, []byte("x")); !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Errorf("PS256 with Ed25519 key: err = %v, want ErrUnsupportedAlgorithm", err)
+	}
+
+	// PS256 签名不能被当作 RS256 校验，也不能用其他类型的公钥校验
+	compact, _ := Sign(rsaKey, Header{Alg: AlgPS256}, []byte("x"))
+	parts := strings.Split(compact, ".")
+	rs256, _ := json.Marshal(Header{Alg: AlgRS256})
+	parts[0] = base64.RawURLEncoding.EncodeToString(rs256)
+	if _, err := Verify(rsaKey.Public(), strings.Join(parts, "."), []byte("x")); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("PS256 relabelled RS256: err = %v, want ErrInvalidSignature", err)
+	}
+	if _, err := Verify(ecKey.Public(), compact, []byte("x")); !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Errorf("PS256 with EC key: err = %v, want ErrUnsupportedAlgorithm", err)
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	crit := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"EdDSA","crit":["b64"],"b64":false}`))
+	for name, compact := range map[string]string{
+		"two parts":     "a.b",
+		"bad header":    "!!..AAAA",
+		"header json":   base64.RawURLEncoding.EncodeToString([]byte("[]")) + "..AAAA",
+		"crit":          crit + "..AAAA",
+		"empty sig":     base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"EdDSA"}`)) + "..",
+		"bad payload":   base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"EdDSA"}`)) + ".!!.AAAA",
+		"bad signature": base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"EdDSA"}`)) + "..!!",
+	} {
+		if _, err := Parse(compact); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: err = %v, want ErrInvalidToken", name, err)
+		}
+	}
+}
+
+func TestKeyring(t *testing.T) {
+	rsaKey, ecKey, edKey := generateKeys(t)
+	k, err := NewKeyring(rsaKey, nil, ecKey, edKey)
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	if got := strings.Join(k.Algorithms(), ","); got != "PS256,RS256,ES256,EdDSA" {
+		t.Errorf("Algorithms() = %s", got)
+	}
+
+	for _, alg := range k.Algorithms() {
+		compact, key, err := k.Sign(alg, []byte("doc"))
+		if err != nil {
+			t.Fatalf("Sign(%s): %v", alg, err)
+		}
+		h, err := k.Verify(compact, []byte("doc"))
+		if err != nil {
+			t.Fatalf("Verify(%s): %v", alg, err)
+		}
+		if h.Kid != key.ID {
+			t.Errorf("%s: kid = %s, want %s", alg, h.Kid, key.ID)
+		}
+	}
+
+	other, _ := NewKeyring(ecKey)
+	compact, _, _ := other.Sign(AlgES256, []byte("doc"))
+	if _, err := (&Keyring{}).Verify(compact, []byte("doc")); !errors.Is(err, ErrUnknownKeyID) {
+		t.Errorf("unknown kid: err = %v, want ErrUnknownKeyID", err)
+	}
+	if _, _, err := other.Sign(AlgEdDSA, []byte("doc")); !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Errorf("missing algorithm: err = %v, want ErrUnsupportedAlgorithm", err)
+	}
+
+	set, err := k.JWKS()
+	if err != nil {
+		t.Fatalf("JWKS: %v", err)
+	}
+	if len(set.Keys) != 3 {
+		t.Fatalf("JWKS has %d keys, want 3", len(set.Keys))
+	}
+	for _, j := range set.Keys {
+		if j.Use != "sig" {
+			t.Errorf("%s: use = %q, want sig", j.Kid, j.Use)
+		}
+		pub, err := j.PublicKey()
+		if err != nil {
+			t.Fatalf("%s: PublicKey: %v", j.Kid, err)
+		}
+		if kid, _ := KeyID(pub); kid != j.Kid {
+			t.Errorf("kid %s does not match key thumbprint %s", j.Kid, kid)
+		}
+	}
+}
+
+func TestLoadPrivateKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "signing.pem")
+	gen := func() (crypto.Signer, error) {
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	}
+	if _, err := LoadPrivateKeyFile(path, nil); err == nil {
+		t.Fatal("missing file without generator: want error")
+	}
+	key, err := LoadPrivateKeyFile(path, gen)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("persisted key: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("persisted key mode %v, want 0600", info.Mode().Perm())
+	}
+	again, err := LoadPrivateKeyFile(path, gen)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if !key.(*ecdsa.PrivateKey).Equal(again) {
+		t.Error("reloaded key differs from the generated one")
+	}
+}
@@ -0,0 +1,180 @@
+package jws
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/keyfile"
+)
+
+// ErrUnknownKeyID JWS 头部中的 kid 不在签名密钥环中
+var ErrUnknownKeyID = errors.New("jws: unknown key id")
+
+// Key 签名密钥环中的一把密钥
+type Key struct {
+	ID         string
+	Signer     crypto.Signer
+	Algorithms []string
+}
+
+// Keyring 服务端签名密钥环，每种算法使用第一把支持它的密钥
+type Keyring struct {
+	keys []*Key
+}
+
+// NewKeyring 用一组私钥创建签名密钥环，nil 会被跳过（对应算法不可用）
+func NewKeyring(signers ...crypto.Signer) (*Keyring, error) {
+	k := &Keyring{}
+	for _, s := range signers {
+		if s == nil {
+			continue
+		}
+		algs := Algorithms(s.Public())
+		if len(algs) == 0 {
+			return nil, fmt.Errorf("%w: key type %T", ErrUnsupportedAlgorithm, s)
+		}
+		kid, err := KeyID(s.Public())
+		if err != nil {
+			return nil, err
+		}
+		k.keys = append(k.keys, &Key{ID: kid, Signer: s, Algorithms: algs})
+	}
+	return k, nil
+}
+
+// Algorithms 返回密钥环支持的全部算法
+func (k *Keyring) Algorithms() []string {
+	var algs []string
+	for _, key := range k.keys {
+		algs = append(algs, key.Algorithms...)
+	}
+	return algs
+}
+
+// Key 返回用于 alg 的签名密钥
+func (k *Keyring) Key(alg string) (*Key, error) {
+	for _, key := range k.keys {
+		if checkAlgorithm(alg, key.Signer.Public()) == nil {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no signing key for %q", ErrUnsupportedAlgorithm, alg)
+}
+
+// Lookup 按 kid 查找密钥
+func (k *Keyring) Lookup(kid string) (*Key, error) {
+	for _, key := range k.keys {
+		if key.ID == kid {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownKeyID, kid)
+}
+
+// Sign 用 alg 对应的密钥签名，返回分离载荷的 JWS 和所用密钥
+func (k *Keyring) Sign(alg string, payload []byte) (string, *Key, error) {
+	key, err := k.Key(alg)
+	if err != nil {
+		return "", nil, err
+	}
+	compact, err := Sign(key.Signer, Header{Alg: alg, Kid: key.ID}, payload)
+	if err != nil {
+		return "", nil, err
+	}
+	return compact, key, nil
+}
+
+// Verify 按 JWS 头部的 kid 选择服务端公钥校验签名
+func (k *Keyring) Verify(compact string, payload []byte) (*Header, error) {
+	t, err := Parse(compact)
+	if err != nil {
+		return nil, err
+	}
+	key, err := k.Lookup(t.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.Verify(key.Signer.Public(), payload); err != nil {
+		return nil, err
+	}
+	return &t.Header, nil
+}
+
+// JWKS 导出全部验证公钥；只支持一种算法的密钥带上 alg
+func (k *Keyring) JWKS() (JWKSet, error) {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range k.keys {
+		alg := ""
+		if len(key.Algorithms) == 1 {
+			alg = key.Algorithms[0]
+		}
+		j, err := PublicJWK(key.Signer.Public(), alg)
+		if err != nil {
+			return JWKSet{}, err
+		}
+		set.Keys = append(set.Keys, j)
+	}
+	return set, nil
+}
+
+// ParsePrivateKeyPEM 解析 PKCS#8（或 SEC 1 "EC PRIVATE KEY"）PEM 格式的签名私钥
+func ParsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode private key PEM")
+	}
+	if block.Type == "EC PRIVATE KEY" {
+		key, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key: %v", err)
+		}
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %v", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok || len(Algorithms(signer.Public())) == 0 {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	if ec, ok := key.(*ecdsa.PrivateKey); ok && ec.Curve.Params().Name != "P-256" {
+		return nil, fmt.Errorf("unsupported EC curve: %s", ec.Curve.Params().Name)
+	}
+	return signer, nil
+}
+
+// LoadPrivateKeyFile 从文件读取 PKCS#8 PEM 私钥；文件不存在且 generate 不为 nil 时生成并以 0600 权限保存
+func LoadPrivateKeyFile(path string, generate func() (crypto.Signer, error)) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return ParsePrivateKeyPEM(data)
+	}
+	if !errors.Is(err, os.ErrNotExist) || generate == nil {
+		return nil, fmt.Errorf("failed to read signing key file: %v", err)
+	}
+
+	key, err := generate()
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal private key: %v", err)
+	}
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if data, err = keyfile.Persist(path, pemBytes); err != nil {
+		return nil, fmt.Errorf("failed to persist signing key file: %v", err)
+	}
+	// 并发启动时其他进程可能先保存了私钥，以文件中的为准
+	if !bytes.Equal(data, pemBytes) {
+		return ParsePrivateKeyPEM(data)
+	}
+	return key, nil
+}
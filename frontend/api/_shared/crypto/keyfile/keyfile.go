@@ -0,0 +1,57 @@
+// Package keyfile 持久化首次启动时生成的私钥文件。
+//
+// 私钥先写入同目录的临时文件并 fsync，再以硬链接放到目标路径：写入失败不会留下截断的密钥文件，
+// 并发启动的多个进程中只有一个能创建目标文件，其余进程读取并使用胜出者的私钥。
+package keyfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// Persist 以 0600 权限把 pemBytes 保存到 path（目录不存在时以 0700 创建），返回最终位于 path 的内容：
+// path 已存在时不覆盖，返回已有文件的内容，调用方应使用返回值而不是自己生成的私钥
+func Persist(path string, pemBytes []byte) ([]byte, error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	// CreateTemp 以 0600 权限创建文件
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(pemBytes)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 与 rename 不同，link 在目标已存在时失败，不会覆盖其他进程刚保存的私钥
+	if err := os.Link(tmp.Name(), path); err != nil {
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		return os.ReadFile(path)
+	}
+	syncDir(dir)
+	return pemBytes, nil
+}
+
+// syncDir 尽量把目录项落盘，断电后新建的密钥文件不会丢失；不支持目录 fsync 的平台忽略错误
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
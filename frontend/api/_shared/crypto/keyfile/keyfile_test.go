@@ -0,0 +1,84 @@
+package keyfile
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestPersist(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "keys", "key.pem")
+
+	got, err := Persist(path, []byte("first"))
+	if err != nil {
+		t.Fatalf("Persist: %v", err)
+	}
+	if string(got) != "first" {
+		t.Fatalf("Persist = %q, want first", got)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("mode = %v, want 0600", perm)
+	}
+
+	// 已存在的文件不被覆盖，返回其内容
+	if got, err = Persist(path, []byte("second")); err != nil {
+		t.Fatalf("Persist existing: %v", err)
+	}
+	if string(got) != "first" {
+		t.Fatalf("Persist existing = %q, want first", got)
+	}
+	assertNoTempFiles(t, filepath.Dir(path))
+}
+
+func TestPersistConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "key.pem")
+
+	const n = 16
+	results := make([][]byte, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = Persist(path, []byte(fmt.Sprintf("key-%d", i)))
+		}()
+	}
+	wg.Wait()
+
+	onDisk, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range n {
+		if errs[i] != nil {
+			t.Fatalf("Persist %d: %v", i, errs[i])
+		}
+		if !bytes.Equal(results[i], onDisk) {
+			t.Errorf("Persist %d = %q, want the key on disk %q", i, results[i], onDisk)
+		}
+	}
+	assertNoTempFiles(t, dir)
+}
+
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Name() != "key.pem" {
+			t.Errorf("unexpected file left behind: %s", e.Name())
+		}
+	}
+}
@@ -0,0 +1,434 @@
+// Package respsig 为 HTTP 响应体签名，客户端可据此确认响应来自本服务且未被篡改。
+//
+// 签名放在 X-Signature 响应头中：
+//
+//	X-Signature: alg=EdDSA; kid=<kid>; ts=<unix 秒>; sig=<Base64URL 签名>
+//
+// 被签名的消息把响应绑定到请求：
+//
+//	"aes-go-js-response-v2\n" || ts || "\n" || 状态码 || "\n" || 请求方法 || "\n" ||
+//	转义后的请求路径 || "\n" || 请求 id（X-Request-Id） || "\n" || 响应体
+//
+// 时间戳让客户端可以拒绝过旧的（被重放的）响应，状态码、方法、路径和请求 id
+// 防止把一个接口的响应替换成另一个请求的响应。支持 Ed25519（EdDSA）和
+// RSA-PSS SHA-256（PS256，盐长度等于哈希长度）。
+package respsig
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/jws"
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/rsakey"
+)
+
+// 签名算法（JOSE 命名）
+const (
+	AlgEdDSA = "EdDSA"
+	AlgPS256 = "PS256"
+)
+
+// 签名方式（backend -response-signing / Vercel RESPONSE_SIGNING）。
+// ModeRSAKeyring 以 PS256 复用 RSA 密钥环的当前解密私钥，不需要单独的签名私钥
+const (
+	ModeEd25519    = "ed25519"
+	ModeRSAPSS     = "rsa-pss"
+	ModeRSAKeyring = "rsa-keyring"
+)
+
+// Header 携带签名的响应头
+const Header = "X-Signature"
+
+// RequestIDHeader 参与签名的请求 id 响应头，与路由中间件写出的头一致
+const RequestIDHeader = "X-Request-Id"
+
+const context = "aes-go-js-response-v2\n"
+
+var (
+	// ErrInvalidSignature 签名头格式错误或签名校验失败
+	ErrInvalidSignature = errors.New("respsig: invalid signature")
+	// ErrExpired 签名时间戳超出允许范围
+	ErrExpired = errors.New("respsig: signature expired")
+	// ErrUnknownKey 签名头中的 kid 不在验证密钥集合中
+	ErrUnknownKey = errors.New("respsig: unknown key id")
+)
+
+// Signer 持有签名私钥
+type Signer struct {
+	Alg   string
+	KeyID string
+	key   crypto.Signer
+}
+
+// NewSigner 按私钥类型创建 Signer：ed25519.PrivateKey 使用 EdDSA，*rsa.PrivateKey 使用 PS256
+func NewSigner(key crypto.Signer) (*Signer, error) {
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		return &Signer{Alg: AlgEdDSA, KeyID: Ed25519KeyID(k.Public().(ed25519.PublicKey)), key: k}, nil
+	case *rsa.PrivateKey:
+		return &Signer{Alg: AlgPS256, KeyID: rsakey.KeyID(&k.PublicKey), key: k}, nil
+	default:
+		return nil, fmt.Errorf("respsig: unsupported key type %T", key)
+	}
+}
+
+// Response 被签名的响应内容及其所属请求
+type Response struct {
+	Status    int
+	Method    string
+	Path      string // 转义后的路径（url.URL.EscapedPath），不含查询串
+	RequestID string
+	Body      []byte
+}
+
+// NewSignerForMode 创建签名方式为 mode 的 Signer，并确认私钥类型与之相符
+// （ed25519 需要 Ed25519 私钥，rsa-pss 与 rsa-keyring 需要 RSA 私钥）
+func NewSignerForMode(mode string, key crypto.Signer) (*Signer, error) {
+	want, ok := map[string]string{ModeEd25519: AlgEdDSA, ModeRSAPSS: AlgPS256, ModeRSAKeyring: AlgPS256}[mode]
+	if !ok {
+		return nil, fmt.Errorf("respsig: unsupported signing mode %q", mode)
+	}
+	s, err := NewSigner(key)
+	if err != nil {
+		return nil, err
+	}
+	if s.Alg != want {
+		return nil, fmt.Errorf("respsig: %s signing requires a %s key, got %T", mode, want, key)
+	}
+	return s, nil
+}
+
+// GenerateKey 生成签名方式为 mode 的私钥：Ed25519 或 rsakey.DefaultBits 位 RSA
+func GenerateKey(mode string) (crypto.Signer, error) {
+	switch mode {
+	case ModeEd25519:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	case ModeRSAPSS:
+		return rsa.GenerateKey(rand.Reader, rsakey.DefaultBits)
+	default:
+		return nil, fmt.Errorf("respsig: unsupported signing mode %q", mode)
+	}
+}
+
+// message 构造被签名的消息；除响应体外各字段都不含换行
+func message(ts int64, resp Response) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(context)
+	for _, field := range []string{
+		strconv.FormatInt(ts, 10), strconv.Itoa(resp.Status), resp.Method, resp.Path, resp.RequestID,
+	} {
+		buf.WriteString(field)
+		buf.WriteByte('\n')
+	}
+	buf.Write(resp.Body)
+	return buf.Bytes()
+}
+
+// Sign 对响应签名，返回 X-Signature 头的值
+func (s *Signer) Sign(resp Response, t time.Time) (string, error) {
+	ts := t.Unix()
+	msg := message(ts, resp)
+
+	var sig []byte
+	var err error
+	switch s.Alg {
+	case AlgEdDSA:
+		sig, err = s.key.Sign(nil, msg, crypto.Hash(0))
+	case AlgPS256:
+		digest := sha256.Sum256(msg)
+		sig, err = s.key.Sign(rand.Reader, digest[:], &rsa.PSSOptions{
+			SaltLength: rsa.PSSSaltLengthEqualsHash,
+			Hash:       crypto.SHA256,
+		})
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("alg=%s; kid=%s; ts=%d; sig=%s", s.Alg, s.KeyID, ts, base64.RawURLEncoding.EncodeToString(sig)), nil
+}
+
+// PublicJWK 导出验证公钥的 JWK
+func (s *Signer) PublicJWK() JWK {
+	switch k := s.key.Public().(type) {
+	case ed25519.PublicKey:
+		return JWK{Kty: "OKP", Kid: s.KeyID, Alg: s.Alg, Use: "sig", Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(k)}
+	case *rsa.PublicKey:
+		j := rsakey.PublicJWK(k)
+		return JWK{Kty: "RSA", Kid: s.KeyID, Alg: s.Alg, Use: "sig", N: j.N, E: j.E}
+	}
+	return JWK{}
+}
+
+// bufferedResponse 缓冲响应体，待处理函数返回后统一签名输出
+type bufferedResponse struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) WriteHeader(code int) {
+	if b.status == 0 {
+		b.status = code
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(p)
+}
+
+// Middleware 为 next 的响应签名；s 为 nil 时（未启用签名）原样调用 next。
+// 响应会被完整缓冲，只应用于 JSON 等小响应。浏览器脚本读取签名需要跨域策略暴露 X-Signature 头
+func (s *Signer) Middleware(logger *slog.Logger, next http.HandlerFunc) http.HandlerFunc {
+	if s == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		b := &bufferedResponse{ResponseWriter: w}
+		next(b, r)
+		if b.status == 0 {
+			b.status = http.StatusOK
+		}
+
+		resp := Response{
+			Status:    b.status,
+			Method:    r.Method,
+			Path:      r.URL.EscapedPath(),
+			RequestID: w.Header().Get(RequestIDHeader),
+			Body:      b.body.Bytes(),
+		}
+		if v, err := s.Sign(resp, time.Now()); err != nil {
+			logger.Error("Response signing failed", "error", err)
+		} else {
+			w.Header().Set(Header, v)
+		}
+		w.WriteHeader(b.status)
+		w.Write(b.body.Bytes())
+	}
+}
+
+// SignatureHeader 解析后的 X-Signature 头
+type SignatureHeader struct {
+	Alg       string
+	KeyID     string
+	Timestamp time.Time
+	Signature []byte
+}
+
+// ParseHeader 解析 X-Signature 头的值
+func ParseHeader(v string) (*SignatureHeader, error) {
+	fields := map[string]string{}
+	for _, part := range strings.Split(v, ";") {
+		k, val, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: malformed header", ErrInvalidSignature)
+		}
+		fields[k] = val
+	}
+
+	ts, err := strconv.ParseInt(fields["ts"], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad timestamp", ErrInvalidSignature)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(fields["sig"])
+	if err != nil || len(sig) == 0 {
+		return nil, fmt.Errorf("%w: bad signature encoding", ErrInvalidSignature)
+	}
+	return &SignatureHeader{
+		Alg:       fields["alg"],
+		KeyID:     fields["kid"],
+		Timestamp: time.Unix(ts, 0),
+		Signature: sig,
+	}, nil
+}
+
+// Verify 用公钥校验响应签名；maxAge 大于 0 时拒绝早于 maxAge 或明显来自未来的签名
+func Verify(publicKey crypto.PublicKey, header string, resp Response, maxAge time.Duration) error {
+	h, err := ParseHeader(header)
+	if err != nil {
+		return err
+	}
+	return h.verify(publicKey, resp, maxAge)
+}
+
+func (h *SignatureHeader) verify(publicKey crypto.PublicKey, resp Response, maxAge time.Duration) error {
+	if maxAge > 0 {
+		age := time.Since(h.Timestamp)
+		if age > maxAge || age < -time.Minute {
+			return fmt.Errorf("%w: signed at %s", ErrExpired, h.Timestamp.Format(time.RFC3339))
+		}
+	}
+
+	msg := message(h.Timestamp.Unix(), resp)
+	switch k := publicKey.(type) {
+	case ed25519.PublicKey:
+		if h.Alg != AlgEdDSA || !ed25519.Verify(k, msg, h.Signature) {
+			return ErrInvalidSignature
+		}
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(msg)
+		if h.Alg != AlgPS256 {
+			return ErrInvalidSignature
+		}
+		if err := rsa.VerifyPSS(k, crypto.SHA256, digest[:], h.Signature, &rsa.PSSOptions{
+			SaltLength: rsa.PSSSaltLengthEqualsHash,
+		}); err != nil {
+			return ErrInvalidSignature
+		}
+	default:
+		return fmt.Errorf("respsig: unsupported key type %T", publicKey)
+	}
+	return nil
+}
+
+// JWK 签名验证公钥的 JWK 表示（RSA 或 OKP/Ed25519）
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+// PublicKey 解析 JWK 中的公钥
+func (j JWK) PublicKey() (crypto.PublicKey, error) {
+	switch j.Kty {
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil || j.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("respsig: invalid Ed25519 JWK")
+		}
+		return ed25519.PublicKey(x), nil
+	case "RSA":
+		return rsakey.JWK{Kty: j.Kty, N: j.N, E: j.E}.PublicKey()
+	default:
+		return nil, fmt.Errorf("respsig: unsupported JWK key type %q", j.Kty)
+	}
+}
+
+// KeySet 按 kid 索引的验证公钥集合
+type KeySet map[string]crypto.PublicKey
+
+// ParseKeySet 解析 /api/signing-key 返回的 JWK Set
+func ParseKeySet(data []byte) (KeySet, error) {
+	var set struct {
+		Keys []JWK `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("respsig: failed to decode JWK set: %v", err)
+	}
+	ks := KeySet{}
+	for _, j := range set.Keys {
+		pub, err := j.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+		ks[j.Kid] = pub
+	}
+	return ks, nil
+}
+
+// Verify 按签名头中的 kid 选择公钥并校验响应
+func (ks KeySet) Verify(header string, resp Response, maxAge time.Duration) error {
+	h, err := ParseHeader(header)
+	if err != nil {
+		return err
+	}
+	pub, ok := ks[h.KeyID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownKey, h.KeyID)
+	}
+	return h.verify(pub, resp, maxAge)
+}
+
+// VerifyResponse 读取并校验 HTTP 响应，返回响应体；方法和路径取自 resp.Request。
+// 响应体读取后 resp.Body 已关闭
+func (ks KeySet) VerifyResponse(resp *http.Response, maxAge time.Duration) ([]byte, error) {
+	defer resp.Body.Close()
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(resp.Body); err != nil {
+		return nil, err
+	}
+	header := resp.Header.Get(Header)
+	if header == "" {
+		return nil, fmt.Errorf("%w: missing %s header", ErrInvalidSignature, Header)
+	}
+	if resp.Request == nil {
+		return nil, fmt.Errorf("%w: response has no request", ErrInvalidSignature)
+	}
+	if err := ks.Verify(header, Response{
+		Status:    resp.StatusCode,
+		Method:    resp.Request.Method,
+		Path:      resp.Request.URL.EscapedPath(),
+		RequestID: resp.Header.Get(RequestIDHeader),
+		Body:      buf.Bytes(),
+	}, maxAge); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Ed25519KeyID 计算 Ed25519 公钥的 RFC 7638 / RFC 8037 JWK 指纹
+func Ed25519KeyID(pub ed25519.PublicKey) string {
+	x := base64.RawURLEncoding.EncodeToString(pub)
+	sum := sha256.Sum256([]byte(`{"crv":"Ed25519","kty":"OKP","x":"` + x + `"}`))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// ParseEd25519PrivateKeyPEM 解析 PKCS#8 PEM 格式的 Ed25519 私钥
+func ParseEd25519PrivateKeyPEM(data []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode private key PEM")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %v", err)
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not Ed25519 type")
+	}
+	return edKey, nil
+}
+
+// LoadEd25519File 从文件读取 Ed25519 私钥；generate 为 true 时文件不存在则生成并以 0600 权限保存
+func LoadEd25519File(path string, generate bool) (ed25519.PrivateKey, error) {
+	var gen func() (crypto.Signer, error)
+	if generate {
+		gen = func() (crypto.Signer, error) {
+			_, key, err := ed25519.GenerateKey(rand.Reader)
+			return key, err
+		}
+	}
+	key, err := jws.LoadPrivateKeyFile(path, gen)
+	if err != nil {
+		return nil, err
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not Ed25519 type")
+	}
+	return edKey, nil
+}
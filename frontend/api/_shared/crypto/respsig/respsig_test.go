@@ -0,0 +1,176 @@
+package respsig
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newSigners(t *testing.T) []*Signer {
+	t.Helper()
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var signers []*Signer
+	for _, key := range []crypto.Signer{edKey, rsaKey} {
+		s, err := NewSigner(key)
+		if err != nil {
+			t.Fatalf("NewSigner(%T): %v", key, err)
+		}
+		signers = append(signers, s)
+	}
+	return signers
+}
+
+func TestSignVerify(t *testing.T) {
+	resp := Response{Status: 200, Method: "POST", Path: "/api/process", RequestID: "req-1", Body: []byte(`{"ok":true}`)}
+
+	for _, s := range newSigners(t) {
+		t.Run(s.Alg, func(t *testing.T) {
+			header, err := s.Sign(resp, time.Now())
+			if err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+			pub := s.key.Public()
+			if err := Verify(pub, header, resp, time.Minute); err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+
+			tampered := map[string]Response{
+				"status":     {Status: 500, Method: resp.Method, Path: resp.Path, RequestID: resp.RequestID, Body: resp.Body},
+				"method":     {Status: resp.Status, Method: "GET", Path: resp.Path, RequestID: resp.RequestID, Body: resp.Body},
+				"path":       {Status: resp.Status, Method: resp.Method, Path: "/api/rsa/process", RequestID: resp.RequestID, Body: resp.Body},
+				"request id": {Status: resp.Status, Method: resp.Method, Path: resp.Path, RequestID: "req-2", Body: resp.Body},
+				"body":       {Status: resp.Status, Method: resp.Method, Path: resp.Path, RequestID: resp.RequestID, Body: []byte(`{"ok":false}`)},
+				// 字段边界移动：不能把 path 的尾部挪到 request id 中
+				"boundary": {Status: resp.Status, Method: resp.Method, Path: "/api/", RequestID: "process\nreq-1", Body: resp.Body},
+			}
+			for name, r := range tampered {
+				if err := Verify(pub, header, r, time.Minute); !errors.Is(err, ErrInvalidSignature) {
+					t.Errorf("tampered %s: err = %v, want ErrInvalidSignature", name, err)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	signers := newSigners(t)
+	ed, rs := signers[0], signers[1]
+	resp := Response{Status: 200, Method: "POST", Path: "/api/process", Body: []byte("{}")}
+
+	old, _ := ed.Sign(resp, time.Now().Add(-time.Hour))
+	if err := Verify(ed.key.Public(), old, resp, 5*time.Minute); !errors.Is(err, ErrExpired) {
+		t.Errorf("old signature: err = %v, want ErrExpired", err)
+	}
+	future, _ := ed.Sign(resp, time.Now().Add(time.Hour))
+	if err := Verify(ed.key.Public(), future, resp, 5*time.Minute); !errors.Is(err, ErrExpired) {
+		t.Errorf("future signature: err = %v, want ErrExpired", err)
+	}
+
+	header, _ := ed.Sign(resp, time.Now())
+	if err := Verify(rs.key.Public(), header, resp, 0); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("wrong key type: err = %v, want ErrInvalidSignature", err)
+	}
+	for _, bad := range []string{"", "alg=EdDSA", "alg=EdDSA; ts=x; sig=AA", "alg=EdDSA; ts=1; sig=!!"} {
+		if err := Verify(ed.key.Public(), bad, resp, 0); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("header %q: err = %v, want ErrInvalidSignature", bad, err)
+		}
+	}
+}
+
+func TestKeySetVerifyResponse(t *testing.T) {
+	signers := newSigners(t)
+	var keys []JWK
+	for _, s := range signers {
+		keys = append(keys, s.PublicJWK())
+	}
+	jwks, _ := json.Marshal(map[string][]JWK{"keys": keys})
+	ks, err := ParseKeySet(jwks)
+	if err != nil {
+		t.Fatalf("ParseKeySet: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, s := range signers {
+		t.Run(s.Alg, func(t *testing.T) {
+			srv := httptest.NewServer(s.Middleware(logger, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(RequestIDHeader, "req-42")
+				w.WriteHeader(http.StatusTeapot)
+				w.Write([]byte("short and stout"))
+			}))
+			defer srv.Close()
+
+			resp, err := http.Post(srv.URL+"/api/process", "text/plain", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, err := ks.VerifyResponse(resp, time.Minute)
+			if err != nil {
+				t.Fatalf("VerifyResponse: %v", err)
+			}
+			if string(body) != "short and stout" || resp.StatusCode != http.StatusTeapot {
+				t.Errorf("got %d %q", resp.StatusCode, body)
+			}
+
+			// 把响应挪到另一个路径的请求上
+			resp, err = http.Post(srv.URL+"/api/process", "text/plain", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Request.URL.Path = "/api/rsa/process"
+			if _, err := ks.VerifyResponse(resp, time.Minute); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("replayed on another path: err = %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+
+	if err := (KeySet{}).Verify("alg=EdDSA; kid=nope; ts=1; sig=AA", Response{}, 0); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("unknown kid: err = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestNewSignerForMode(t *testing.T) {
+	for _, mode := range []string{ModeEd25519, ModeRSAPSS} {
+		key, err := GenerateKey(mode)
+		if err != nil {
+			t.Fatalf("GenerateKey(%s): %v", mode, err)
+		}
+		if _, err := NewSignerForMode(mode, key); err != nil {
+			t.Errorf("NewSignerForMode(%s): %v", mode, err)
+		}
+	}
+
+	signers := newSigners(t)
+	if _, err := NewSignerForMode(ModeRSAPSS, signers[0].key); err == nil {
+		t.Error("rsa-pss with Ed25519 key: want error")
+	}
+	if _, err := NewSignerForMode(ModeEd25519, signers[1].key); err == nil {
+		t.Error("ed25519 with RSA key: want error")
+	}
+	if s, err := NewSignerForMode(ModeRSAKeyring, signers[1].key); err != nil {
+		t.Errorf("rsa-keyring with RSA key: %v", err)
+	} else if s.Alg != AlgPS256 {
+		t.Errorf("rsa-keyring alg = %s, want PS256", s.Alg)
+	}
+	if _, err := NewSignerForMode(ModeRSAKeyring, signers[0].key); err == nil {
+		t.Error("rsa-keyring with Ed25519 key: want error")
+	}
+	if _, err := NewSignerForMode("hmac", signers[0].key); err == nil {
+		t.Error("unknown mode: want error")
+	}
+}
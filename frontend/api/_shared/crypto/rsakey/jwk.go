@@ -0,0 +1,199 @@
+package rsakey
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// JWK 算法与用途（RFC 7518 / RFC 7517）
+const (
+	JWKAlgRSAOAEP256 = "RSA-OAEP-256"
+	JWKUseEnc        = "enc"
+)
+
+// JWK RSA 公钥或私钥的 JSON Web Key 表示（RFC 7517 / RFC 7518 §6.3），大整数为 Base64URL 无填充
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+
+	// 私钥成员，公钥 JWK 中不出现
+	D  string `json:"d,omitempty"`
+	P  string `json:"p,omitempty"`
+	Q  string `json:"q,omitempty"`
+	DP string `json:"dp,omitempty"`
+	DQ string `json:"dq,omitempty"`
+	QI string `json:"qi,omitempty"`
+}
+
+// JWKSet JWK 集合（RFC 7517 §5）
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicJWK 以 RSA-OAEP-256 加密用途导出公钥 JWK，kid 为 RFC 7638 指纹
+func PublicJWK(pub *rsa.PublicKey) JWK {
+	return JWK{
+		Kty: "RSA",
+		Kid: KeyID(pub),
+		Alg: JWKAlgRSAOAEP256,
+		Use: JWKUseEnc,
+		N:   encodeBigInt(pub.N),
+		E:   encodeBigInt(big.NewInt(int64(pub.E))),
+	}
+}
+
+// PrivateJWK 导出私钥 JWK（包含 CRT 参数）
+func PrivateJWK(key *rsa.PrivateKey) JWK {
+	j := PublicJWK(&key.PublicKey)
+	j.D = encodeBigInt(key.D)
+	if len(key.Primes) == 2 {
+		key.Precompute()
+		j.P = encodeBigInt(key.Primes[0])
+		j.Q = encodeBigInt(key.Primes[1])
+		j.DP = encodeBigInt(key.Precomputed.Dp)
+		j.DQ = encodeBigInt(key.Precomputed.Dq)
+		j.QI = encodeBigInt(key.Precomputed.Qinv)
+	}
+	return j
+}
+
+// JWKS 导出密钥环中当前密钥和宽限期内旧密钥的公钥集合
+func (k *Keyring) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range k.Keys() {
+		set.Keys = append(set.Keys, PublicJWK(&key.PrivateKey.PublicKey))
+	}
+	return set
+}
+
+// PublicKey 解析 JWK 中的 RSA 公钥
+func (j JWK) PublicKey() (*rsa.PublicKey, error) {
+	if j.Kty != "RSA" {
+		return nil, fmt.Errorf("unsupported JWK key type: %q", j.Kty)
+	}
+	n, err := decodeBigInt("n", j.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := decodeBigInt("e", j.E)
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsInt64() || e.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("JWK exponent is too large")
+	}
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+// PrivateKey 解析 JWK 中的 RSA 私钥（需要 d、p、q；多素数私钥不支持）
+func (j JWK) PrivateKey() (*rsa.PrivateKey, error) {
+	pub, err := j.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	if j.D == "" {
+		return nil, fmt.Errorf("JWK is not a private key")
+	}
+	d, err := decodeBigInt("d", j.D)
+	if err != nil {
+		return nil, err
+	}
+	p, err := decodeBigInt("p", j.P)
+	if err != nil {
+		return nil, err
+	}
+	q, err := decodeBigInt("q", j.Q)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &rsa.PrivateKey{PublicKey: *pub, D: d, Primes: []*big.Int{p, q}}
+	if err := key.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid JWK private key: %v", err)
+	}
+	key.Precompute()
+	return key, nil
+}
+
+// ParsePublicKeyJWK 解析单个 RSA 公钥 JWK
+func ParsePublicKeyJWK(data []byte) (*rsa.PublicKey, error) {
+	var j JWK
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, fmt.Errorf("failed to decode JWK: %v", err)
+	}
+	return j.PublicKey()
+}
+
+// ParsePrivateKeyJWK 解析单个 RSA 私钥 JWK
+func ParsePrivateKeyJWK(data []byte) (*rsa.PrivateKey, error) {
+	var j JWK
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, fmt.Errorf("failed to decode JWK: %v", err)
+	}
+	return j.PrivateKey()
+}
+
+// ParsePrivateKey 解析 JWK（JSON）或 PEM 格式的 RSA 私钥
+func ParsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	if isJSON(data) {
+		return ParsePrivateKeyJWK(data)
+	}
+	return ParsePrivateKeyPEM(data)
+}
+
+// ParsePrivateKeys 解析私钥列表：JWK Set、单个 JWK 或包含多个 PEM 块的文本
+func ParsePrivateKeys(data []byte) ([]*rsa.PrivateKey, error) {
+	if !isJSON(data) {
+		return ParsePrivateKeysPEM(data)
+	}
+
+	var set JWKSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWK set: %v", err)
+	}
+	if set.Keys == nil {
+		key, err := ParsePrivateKeyJWK(data)
+		if err != nil {
+			return nil, err
+		}
+		return []*rsa.PrivateKey{key}, nil
+	}
+
+	keys := make([]*rsa.PrivateKey, 0, len(set.Keys))
+	for _, j := range set.Keys {
+		key, err := j.PrivateKey()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// isJSON 去掉前导空白后是否以 '{' 开头
+func isJSON(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("{"))
+}
+
+func encodeBigInt(v *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(v.Bytes())
+}
+
+func decodeBigInt(name, v string) (*big.Int, error) {
+	if v == "" {
+		return nil, fmt.Errorf("JWK member %q is missing", name)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(v)
+	if err != nil {
+		return nil, fmt.Errorf("JWK member %q base64url decode failed: %v", name, err)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,130 @@
+package rsakey
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 7638 §3.1 示例公钥及其指纹
+const (
+	rfc7638N          = "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw"
+	rfc7638Thumbprint = "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"
+)
+
+func TestKeyIDRFC7638(t *testing.T) {
+	pub, err := JWK{Kty: "RSA", N: rfc7638N, E: "AQAB"}.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := KeyID(pub); got != rfc7638Thumbprint {
+		t.Errorf("KeyID = %s, want %s", got, rfc7638Thumbprint)
+	}
+}
+
+func TestPrivateJWKRoundTrip(t *testing.T) {
+	key := generateKey(t)
+	data, err := json.Marshal(PrivateJWK(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ParsePrivateKey(data)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey(JWK): %v", err)
+	}
+	if !key.Equal(got) {
+		t.Error("JWK round trip changed the private key")
+	}
+
+	pub, err := json.Marshal(PublicJWK(&key.PublicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(pub), `"d"`) {
+		t.Error("public JWK contains private members")
+	}
+	gotPub, err := ParsePublicKeyJWK(pub)
+	if err != nil {
+		t.Fatalf("ParsePublicKeyJWK: %v", err)
+	}
+	if !key.PublicKey.Equal(gotPub) {
+		t.Error("public JWK round trip changed the key")
+	}
+	if _, err := ParsePrivateKeyJWK(pub); err == nil {
+		t.Error("ParsePrivateKeyJWK accepted a public JWK")
+	}
+}
+
+func TestJWKRejects(t *testing.T) {
+	key := generateKey(t)
+
+	wrongType := PrivateJWK(key)
+	wrongType.Kty = "EC"
+	if _, err := wrongType.PrivateKey(); err == nil {
+		t.Error("accepted kty EC")
+	}
+
+	missing := PrivateJWK(key)
+	missing.Q = ""
+	if _, err := missing.PrivateKey(); err == nil {
+		t.Error("accepted JWK without q")
+	}
+
+	// d 与 n 不匹配时 Validate 失败
+	mismatched := PrivateJWK(key)
+	mismatched.D = encodeBigInt(new(big.Int).Add(key.D, big.NewInt(2)))
+	if _, err := mismatched.PrivateKey(); err == nil {
+		t.Error("accepted inconsistent private key")
+	}
+
+	badB64 := PrivateJWK(key)
+	badB64.N = "***"
+	if _, err := badB64.PublicKey(); err == nil {
+		t.Error("accepted invalid base64url modulus")
+	}
+}
+
+func TestParsePrivateKeysJWKSet(t *testing.T) {
+	a, b := generateKey(t), generateKey(t)
+	data, _ := json.Marshal(JWKSet{Keys: []JWK{PrivateJWK(a), PrivateJWK(b)}})
+
+	keys, err := ParsePrivateKeys(data)
+	if err != nil {
+		t.Fatalf("ParsePrivateKeys: %v", err)
+	}
+	if len(keys) != 2 || !keys[0].Equal(a) || !keys[1].Equal(b) {
+		t.Fatalf("ParsePrivateKeys returned %d keys in the wrong order", len(keys))
+	}
+
+	pemA, _ := MarshalPrivateKeyPEM(a)
+	pemB, _ := MarshalPrivateKeyPEM(b)
+	keys, err = ParsePrivateKeys(append(pemA, pemB...))
+	if err != nil || len(keys) != 2 {
+		t.Fatalf("ParsePrivateKeys(PEM) = %d keys, %v", len(keys), err)
+	}
+}
+
+func TestKeyringJWKS(t *testing.T) {
+	current, old, expired := generateKey(t), generateKey(t), generateKey(t)
+	k := NewKeyring(current, time.Hour)
+	k.AddRetired(old, time.Now())
+	k.AddRetired(expired, time.Now().Add(-2*time.Hour))
+
+	set := k.JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("JWKS has %d keys, want 2", len(set.Keys))
+	}
+	if set.Keys[0].Kid != KeyID(&current.PublicKey) || set.Keys[1].Kid != KeyID(&old.PublicKey) {
+		t.Error("JWKS keys are not current-first")
+	}
+	for _, j := range set.Keys {
+		if j.D != "" {
+			t.Error("JWKS leaks private members")
+		}
+		if j.Use != JWKUseEnc || j.Alg != JWKAlgRSAOAEP256 {
+			t.Errorf("JWKS key use/alg = %s/%s", j.Use, j.Alg)
+		}
+	}
+}
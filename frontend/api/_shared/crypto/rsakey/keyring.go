@@ -0,0 +1,209 @@
+package rsakey
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// DefaultGracePeriod 轮换后旧密钥仍可用于解密的默认时长
+const DefaultGracePeriod = 7 * 24 * time.Hour
+
+var (
+	// ErrUnknownKeyID 密钥环中没有该 kid
+	ErrUnknownKeyID = errors.New("rsakey: unknown key id")
+	// ErrKeyRetired kid 对应的旧密钥已超过宽限期
+	ErrKeyRetired = errors.New("rsakey: key retired")
+	// ErrRotatedAtRequired 配置了旧密钥但没有给出轮换时间
+	ErrRotatedAtRequired = errors.New("rsakey: rotation time is required when previous keys are configured")
+)
+
+// Key 密钥环中的一把私钥
+type Key struct {
+	ID         string // RFC 7638 JWK 指纹
+	PrivateKey *rsa.PrivateKey
+	RetiredAt  time.Time // 当前密钥为零值
+}
+
+// Keyring 持有一把当前密钥和若干把已轮换下来的旧密钥
+//
+// 加密方只应使用当前密钥；解密时按请求中的 kid 选择私钥，
+// 旧密钥在轮换后的宽限期内仍可用于解密，保证轮换时在途客户端不受影响。
+type Keyring struct {
+	mu       sync.RWMutex
+	current  *Key
+	previous []*Key // 从新到旧
+	grace    time.Duration
+}
+
+// NewKeyring 以 current 为当前密钥创建密钥环；grace 非正数时使用默认宽限期
+func NewKeyring(current *rsa.PrivateKey, grace time.Duration) *Keyring {
+	if grace <= 0 {
+		grace = DefaultGracePeriod
+	}
+	return &Keyring{
+		current: &Key{ID: KeyID(&current.PublicKey), PrivateKey: current},
+		grace:   grace,
+	}
+}
+
+// AddRetired 加入一把在 retiredAt 轮换下来的旧密钥
+func (k *Keyring) AddRetired(key *rsa.PrivateKey, retiredAt time.Time) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	id := KeyID(&key.PublicKey)
+	if id == k.current.ID {
+		return
+	}
+	for _, p := range k.previous {
+		if p.ID == id {
+			return
+		}
+	}
+	k.previous = append(k.previous, &Key{ID: id, PrivateKey: key, RetiredAt: retiredAt})
+}
+
+// Rotate 把 next 设为当前密钥，原当前密钥从此刻起进入宽限期
+func (k *Keyring) Rotate(next *rsa.PrivateKey) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	// 复制一份旧密钥再设置 RetiredAt，避免修改调用方通过 Current 拿到的对象
+	old := &Key{ID: k.current.ID, PrivateKey: k.current.PrivateKey, RetiredAt: time.Now()}
+	k.current = &Key{ID: KeyID(&next.PublicKey), PrivateKey: next}
+	k.previous = append([]*Key{old}, k.previous...)
+}
+
+// Current 返回当前密钥
+func (k *Keyring) Current() *Key {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.current
+}
+
+// Keys 返回当前密钥和仍在宽限期内的旧密钥，当前密钥在前
+func (k *Keyring) Keys() []*Key {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	keys := []*Key{k.current}
+	for _, p := range k.previous {
+		if k.inGrace(p) {
+			keys = append(keys, p)
+		}
+	}
+	return keys
+}
+
+// Lookup 按 kid 查找可用于解密的私钥
+func (k *Keyring) Lookup(kid string) (*Key, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if kid == k.current.ID {
+		return k.current, nil
+	}
+	for _, p := range k.previous {
+		if p.ID == kid {
+			if !k.inGrace(p) {
+				return nil, fmt.Errorf("%w: %s", ErrKeyRetired, kid)
+			}
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownKeyID, kid)
+}
+
+// Decrypt 用 kid 对应的私钥调用 decrypt；kid 为空时（旧客户端）
+// 尝试当前密钥和宽限期内的所有旧密钥，返回第一个成功的结果
+//
+// 无论哪把密钥成功都会尝试全部密钥，不提前返回，响应时间不会泄露是哪把密钥解密失败。
+// 隐式拒绝的 PKCS#1 v1.5 解密总是"成功"，无法据此选择密钥，调用方必须要求 kid。
+func (k *Keyring) Decrypt(kid string, decrypt func(*rsa.PrivateKey) ([]byte, error)) ([]byte, error) {
+	if kid != "" {
+		key, err := k.Lookup(kid)
+		if err != nil {
+			return nil, err
+		}
+		return decrypt(key.PrivateKey)
+	}
+
+	var out []byte
+	var firstErr error
+	found := false
+	for _, key := range k.Keys() {
+		plain, err := decrypt(key.PrivateKey)
+		if err == nil && !found {
+			out, found = plain, true
+		} else if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if !found {
+		return nil, firstErr
+	}
+	return out, nil
+}
+
+// inGrace 旧密钥是否仍在宽限期内（调用方持有锁）
+func (k *Keyring) inGrace(key *Key) bool {
+	return time.Since(key.RetiredAt) < k.grace
+}
+
+// KeyID 计算公钥的 RFC 7638 JWK 指纹（SHA-256，Base64URL 无填充），用作 kid
+func KeyID(pub *rsa.PublicKey) string {
+	e := encodeBigInt(big.NewInt(int64(pub.E)))
+	n := encodeBigInt(pub.N)
+	// 成员按字典序排列、无空白，这是 RFC 7638 规定的规范形式
+	sum := sha256.Sum256([]byte(`{"e":"` + e + `","kty":"RSA","n":"` + n + `"}`))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// ParsePrivateKeysPEM 解析包含多个 PEM 块的私钥列表（用于配置旧密钥）
+func ParsePrivateKeysPEM(data []byte) ([]*rsa.PrivateKey, error) {
+	var keys []*rsa.PrivateKey
+	for {
+		block, rest := pem.Decode(data)
+		if block == nil {
+			break
+		}
+		key, err := ParsePrivateKeyPEM(pem.EncodeToMemory(block))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+		data = rest
+	}
+	return keys, nil
+}
+
+// LoadKeyring 从 current 来源加载当前密钥，并加入 retired（多个 PEM 块或 JWK Set）中在 retiredAt 轮换下来的旧密钥
+//
+// 配置了旧密钥时 retiredAt 不能为零值：若以启动时刻代替，每次重启都会重置宽限期，旧密钥永不过期
+func LoadKeyring(current []Source, retired []byte, retiredAt time.Time, grace time.Duration) (*Keyring, error) {
+	key, err := Load(current...)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := ParsePrivateKeys(retired)
+	if err != nil {
+		return nil, fmt.Errorf("previous keys: %w", err)
+	}
+	if len(previous) > 0 && retiredAt.IsZero() {
+		return nil, ErrRotatedAtRequired
+	}
+
+	k := NewKeyring(key, grace)
+	for _, p := range previous {
+		k.AddRetired(p, retiredAt)
+	}
+	return k, nil
+}
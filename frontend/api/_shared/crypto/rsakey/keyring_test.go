@@ -0,0 +1,132 @@
+package rsakey
+
+import (
+	"crypto/rsa"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestKeyringLookup(t *testing.T) {
+	current, old, expired := generateKey(t), generateKey(t), generateKey(t)
+	k := NewKeyring(current, time.Hour)
+	k.AddRetired(old, time.Now().Add(-time.Minute))
+	k.AddRetired(expired, time.Now().Add(-2*time.Hour))
+
+	if got := k.Current().ID; got != KeyID(&current.PublicKey) {
+		t.Errorf("Current().ID = %s, want current key", got)
+	}
+	if key, err := k.Lookup(KeyID(&old.PublicKey)); err != nil || !key.PrivateKey.Equal(old) {
+		t.Errorf("Lookup(old) = %v, %v", key, err)
+	}
+	if _, err := k.Lookup(KeyID(&expired.PublicKey)); !errors.Is(err, ErrKeyRetired) {
+		t.Errorf("Lookup(expired): err = %v, want ErrKeyRetired", err)
+	}
+	if _, err := k.Lookup("unknown"); !errors.Is(err, ErrUnknownKeyID) {
+		t.Errorf("Lookup(unknown): err = %v, want ErrUnknownKeyID", err)
+	}
+	if n := len(k.Keys()); n != 2 {
+		t.Errorf("len(Keys()) = %d, want 2 (current + old in grace)", n)
+	}
+}
+
+func TestKeyringRotate(t *testing.T) {
+	first, second := generateKey(t), generateKey(t)
+	k := NewKeyring(first, time.Hour)
+	before := k.Current()
+
+	k.Rotate(second)
+	if k.Current().ID != KeyID(&second.PublicKey) {
+		t.Fatal("Rotate did not replace the current key")
+	}
+	if !before.RetiredAt.IsZero() {
+		t.Error("Rotate modified the Key returned by Current")
+	}
+	if _, err := k.Lookup(KeyID(&first.PublicKey)); err != nil {
+		t.Errorf("rotated key not usable during grace: %v", err)
+	}
+}
+
+func TestKeyringDecryptByKeyID(t *testing.T) {
+	current, old := generateKey(t), generateKey(t)
+	k := NewKeyring(current, time.Hour)
+	k.AddRetired(old, time.Now())
+
+	var used *rsa.PrivateKey
+	decrypt := func(key *rsa.PrivateKey) ([]byte, error) {
+		used = key
+		return []byte("ok"), nil
+	}
+	if _, err := k.Decrypt(KeyID(&old.PublicKey), decrypt); err != nil {
+		t.Fatal(err)
+	}
+	if !used.Equal(old) {
+		t.Error("Decrypt used the wrong key for kid")
+	}
+	if _, err := k.Decrypt("unknown", decrypt); !errors.Is(err, ErrUnknownKeyID) {
+		t.Errorf("Decrypt(unknown): err = %v, want ErrUnknownKeyID", err)
+	}
+}
+
+func TestLoadKeyringRequiresRotatedAt(t *testing.T) {
+	current, old := generateKey(t), generateKey(t)
+	currentPEM, _ := MarshalPrivateKeyPEM(current)
+	oldPEM, _ := MarshalPrivateKeyPEM(old)
+	t.Setenv("RSAKEY_TEST", string(currentPEM))
+
+	if _, err := LoadKeyring([]Source{Env("RSAKEY_TEST")}, oldPEM, time.Time{}, 0); !errors.Is(err, ErrRotatedAtRequired) {
+		t.Errorf("LoadKeyring without rotatedAt: err = %v, want ErrRotatedAtRequired", err)
+	}
+
+	// 没有旧密钥时不需要轮换时间
+	if _, err := LoadKeyring([]Source{Env("RSAKEY_TEST")}, nil, time.Time{}, 0); err != nil {
+		t.Errorf("LoadKeyring without previous keys: %v", err)
+	}
+
+	// 宽限期从配置的轮换时间起算，与加载时刻无关
+	k, err := LoadKeyring([]Source{Env("RSAKEY_TEST")}, oldPEM, time.Now().Add(-8*24*time.Hour), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := k.Lookup(KeyID(&old.PublicKey)); !errors.Is(err, ErrKeyRetired) {
+		t.Errorf("Lookup(old past default grace): err = %v, want ErrKeyRetired", err)
+	}
+}
+
+// TestKeyringDecryptWithoutKeyID 不带 kid 时尝试全部可用密钥，不因某把密钥成功而提前返回
+func TestKeyringDecryptWithoutKeyID(t *testing.T) {
+	current, old, expired := generateKey(t), generateKey(t), generateKey(t)
+	k := NewKeyring(current, time.Hour)
+	k.AddRetired(old, time.Now())
+	k.AddRetired(expired, time.Now().Add(-2*time.Hour))
+
+	var tried []*rsa.PrivateKey
+	out, err := k.Decrypt("", func(key *rsa.PrivateKey) ([]byte, error) {
+		tried = append(tried, key)
+		if key.Equal(current) {
+			return []byte("current"), nil
+		}
+		return nil, errors.New("wrong key")
+	})
+	if err != nil || string(out) != "current" {
+		t.Fatalf("Decrypt = %q, %v", out, err)
+	}
+	if len(tried) != 2 {
+		t.Errorf("tried %d keys, want 2 (current + old in grace)", len(tried))
+	}
+
+	out, err = k.Decrypt("", func(key *rsa.PrivateKey) ([]byte, error) {
+		if key.Equal(old) {
+			return []byte("old"), nil
+		}
+		return nil, errors.New("wrong key")
+	})
+	if err != nil || string(out) != "old" {
+		t.Errorf("Decrypt with old key = %q, %v", out, err)
+	}
+
+	failure := errors.New("wrong key")
+	if _, err := k.Decrypt("", func(*rsa.PrivateKey) ([]byte, error) { return nil, failure }); !errors.Is(err, failure) {
+		t.Errorf("all keys fail: err = %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+// Package rsakey 统一 RSA 私钥的加载方式，standalone backend 与 Vercel 函数共用。
+//
+// 私钥可以来自环境变量（PEM 或 JWK 文本）或文件；文件来源可在首次启动时生成新密钥并持久化，
+// 保证进程重启（包括 air 热重载）后仍使用同一密钥，旧密文可继续解密。
+package rsakey
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/keyfile"
+)
+
+// DefaultBits 生成新密钥时的默认长度
+const DefaultBits = 2048
+
+// ErrNoKey 该来源没有配置密钥（环境变量未设置、文件不存在），Load 会继续尝试下一个来源
+var ErrNoKey = errors.New("rsakey: no key configured")
+
+// Source 私钥来源
+type Source interface {
+	Load() (*rsa.PrivateKey, error)
+}
+
+// Env 从环境变量读取 PEM 或 JWK 格式私钥
+type Env string
+
+// Load 读取并解析环境变量中的私钥
+func (e Env) Load() (*rsa.PrivateKey, error) {
+	v := os.Getenv(string(e))
+	if v == "" {
+		return nil, fmt.Errorf("%w: %s environment variable is not set", ErrNoKey, string(e))
+	}
+	key, err := ParsePrivateKey([]byte(v))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", string(e), err)
+	}
+	return key, nil
+}
+
+// File 从文件读取 PEM 或 JWK 格式私钥；Generate 为 true 时文件不存在则生成新密钥并以 0600 权限写入
+type File struct {
+	Path     string
+	Generate bool
+	Bits     int // 生成密钥的长度，0 表示 DefaultBits
+}
+
+// Load 读取文件中的私钥，必要时生成并持久化
+func (f File) Load() (*rsa.PrivateKey, error) {
+	if f.Path == "" {
+		return nil, fmt.Errorf("%w: key file path is empty", ErrNoKey)
+	}
+
+	data, err := os.ReadFile(f.Path)
+	if err == nil {
+		key, err := ParsePrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Path, err)
+		}
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read key file: %v", err)
+	}
+	if !f.Generate {
+		return nil, fmt.Errorf("%w: %s does not exist", ErrNoKey, f.Path)
+	}
+
+	bits := f.Bits
+	if bits == 0 {
+		bits = DefaultBits
+	}
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate RSA key pair: %v", err)
+	}
+
+	pemBytes, err := MarshalPrivateKeyPEM(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err = keyfile.Persist(f.Path, pemBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to persist key file: %v", err)
+	}
+	// 并发启动时其他进程可能先保存了私钥，以文件中的为准
+	if !bytes.Equal(data, pemBytes) {
+		if key, err = ParsePrivateKey(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Path, err)
+		}
+	}
+	return key, nil
+}
+
+// Load 依次尝试各来源，返回第一个配置了密钥的来源的结果；
+// 来源解析失败时直接返回错误，而不是悄悄退回下一个来源
+func Load(sources ...Source) (*rsa.PrivateKey, error) {
+	var errs []error
+	for _, s := range sources {
+		key, err := s.Load()
+		if err == nil {
+			return key, nil
+		}
+		if !errors.Is(err, ErrNoKey) {
+			return nil, err
+		}
+		errs = append(errs, err)
+	}
+	return nil, errors.Join(append([]error{ErrNoKey}, errs...)...)
+}
+
+// ParsePrivateKeyPEM 解析 PKCS#8（"PRIVATE KEY"）或 PKCS#1（"RSA PRIVATE KEY"）格式的 RSA 私钥
+func ParsePrivateKeyPEM(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode private key PEM")
+	}
+
+	if block.Type == "RSA PRIVATE KEY" {
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key: %v", err)
+		}
+		return key, nil
+	}
+
+	privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %v", err)
+	}
+	rsaKey, ok := privateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not RSA type")
+	}
+	return rsaKey, nil
+}
+
+// MarshalPrivateKeyPEM 以 PKCS#8 PEM 格式导出私钥
+func MarshalPrivateKeyPEM(key *rsa.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal private key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// PublicKeyPEM 导出公钥为 PKIX PEM 格式（前端 node-forge 使用该格式）
+func PublicKeyPEM(key *rsa.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal public key: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
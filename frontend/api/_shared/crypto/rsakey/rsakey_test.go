@@ -0,0 +1,127 @@
+package rsakey
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func generateKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestFileGeneratePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "rsa.pem")
+	src := File{Path: path, Generate: true}
+
+	first, err := src.Load()
+	if err != nil {
+		t.Fatalf("Load (generate): %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("key file mode = %o, want 600", perm)
+	}
+
+	second, err := src.Load()
+	if err != nil {
+		t.Fatalf("Load (existing): %v", err)
+	}
+	if !first.Equal(second) {
+		t.Error("reloaded key differs from the generated key")
+	}
+}
+
+func TestFileMissing(t *testing.T) {
+	_, err := File{Path: filepath.Join(t.TempDir(), "missing.pem")}.Load()
+	if !errors.Is(err, ErrNoKey) {
+		t.Errorf("Load(missing): err = %v, want ErrNoKey", err)
+	}
+}
+
+func TestEnv(t *testing.T) {
+	key := generateKey(t)
+	pemBytes, err := MarshalPrivateKeyPEM(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("RSAKEY_TEST", string(pemBytes))
+	got, err := Env("RSAKEY_TEST").Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !key.Equal(got) {
+		t.Error("Env key differs from the original")
+	}
+
+	t.Setenv("RSAKEY_TEST", "not a key")
+	if _, err := Env("RSAKEY_TEST").Load(); err == nil || errors.Is(err, ErrNoKey) {
+		t.Errorf("Load(invalid): err = %v, want parse error", err)
+	}
+	if _, err := Env("RSAKEY_TEST_UNSET").Load(); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Load(unset): err = %v, want ErrNoKey", err)
+	}
+}
+
+func TestLoadOrder(t *testing.T) {
+	key := generateKey(t)
+	pemBytes, _ := MarshalPrivateKeyPEM(key)
+	t.Setenv("RSAKEY_TEST", string(pemBytes))
+
+	// 未配置的来源被跳过
+	got, err := Load(Env("RSAKEY_TEST_UNSET"), Env("RSAKEY_TEST"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !key.Equal(got) {
+		t.Error("Load returned the wrong key")
+	}
+
+	// 解析失败的来源不会退回下一个来源
+	t.Setenv("RSAKEY_TEST_BAD", "garbage")
+	if _, err := Load(Env("RSAKEY_TEST_BAD"), Env("RSAKEY_TEST")); err == nil {
+		t.Error("Load fell back past an invalid source")
+	}
+
+	if _, err := Load(Env("RSAKEY_TEST_UNSET")); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Load(none): err = %v, want ErrNoKey", err)
+	}
+}
+
+func TestParsePrivateKeyPEM(t *testing.T) {
+	key := generateKey(t)
+	pkcs1 := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	pkcs8, _ := MarshalPrivateKeyPEM(key)
+
+	for name, data := range map[string][]byte{"pkcs1": pkcs1, "pkcs8": pkcs8} {
+		got, err := ParsePrivateKeyPEM(data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !key.Equal(got) {
+			t.Errorf("%s: parsed key differs", name)
+		}
+	}
+
+	if _, err := ParsePrivateKeyPEM([]byte("no pem here")); err == nil {
+		t.Error("ParsePrivateKeyPEM accepted non-PEM input")
+	}
+	pub, _ := PublicKeyPEM(&key.PublicKey)
+	if _, err := ParsePrivateKeyPEM([]byte(pub)); err == nil {
+		t.Error("ParsePrivateKeyPEM accepted a public key")
+	}
+}
@@ -0,0 +1,223 @@
+// Package rsapad 按请求选择 RSA 加密的填充方案：OAEP（标签哈希与 MGF1 哈希分别可选
+// SHA-1/256/384/512，支持 OAEP 标签）或 PKCS#1 v1.5（JSEncrypt 的默认填充）。
+//
+// PKCS#1 v1.5 解密采用隐式拒绝（draft-irtf-cfrg-rsa-guidance）：填充无效时不返回错误，
+// 而是返回由私钥和密文确定性派生的伪随机明文，调用方无法据此区分填充是否有效，
+// 从而抵御 Bleichenbacher 类填充预言攻击。
+package rsapad
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha1"
+	"crypto/sha256"
+	_ "crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// 填充方案
+const (
+	SchemeOAEP     = "oaep"
+	SchemePKCS1v15 = "pkcs1v15"
+)
+
+var (
+	// ErrUnsupportedScheme 未知的填充方案
+	ErrUnsupportedScheme = errors.New("rsapad: unsupported padding scheme")
+	// ErrUnsupportedHash 未知的 OAEP 哈希
+	ErrUnsupportedHash = errors.New("rsapad: unsupported hash")
+	// ErrDecryption 解密失败；PKCS#1 v1.5 只在密文长度不符时返回
+	ErrDecryption = errors.New("rsapad: decryption error")
+)
+
+// Padding RSA 加密填充方案，nil 或零值表示 OAEP SHA-256（MGF1 SHA-256、空标签）
+type Padding struct {
+	Scheme  string `json:"scheme,omitempty"`  // oaep（默认）或 pkcs1v15
+	Hash    string `json:"hash,omitempty"`    // OAEP 标签哈希：SHA-1、SHA-256（默认）、SHA-384、SHA-512
+	MGFHash string `json:"mgfHash,omitempty"` // MGF1 哈希，默认与 Hash 相同
+	Label   string `json:"label,omitempty"`   // OAEP 标签，标准 Base64
+}
+
+// parseHash 解析哈希名，大小写和连字符不敏感（SHA-256、sha256 均可）
+func parseHash(name string, def crypto.Hash) (crypto.Hash, error) {
+	switch strings.ReplaceAll(strings.ToUpper(name), "-", "") {
+	case "":
+		return def, nil
+	case "SHA1":
+		return crypto.SHA1, nil
+	case "SHA256":
+		return crypto.SHA256, nil
+	case "SHA384":
+		return crypto.SHA384, nil
+	case "SHA512":
+		return crypto.SHA512, nil
+	}
+	return 0, fmt.Errorf("%w: %s", ErrUnsupportedHash, name)
+}
+
+// scheme 规范化后的填充方案名
+func (p *Padding) scheme() (string, error) {
+	if p == nil || p.Scheme == "" {
+		return SchemeOAEP, nil
+	}
+	switch strings.ToLower(p.Scheme) {
+	case SchemeOAEP:
+		return SchemeOAEP, nil
+	case SchemePKCS1v15, "pkcs1", "pkcs1-v1_5":
+		return SchemePKCS1v15, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedScheme, p.Scheme)
+}
+
+// IsPKCS1v15 是否为 PKCS#1 v1.5 填充；方案名无效时返回 false，错误留给解密时报告
+func (p *Padding) IsPKCS1v15() bool {
+	scheme, err := p.scheme()
+	return err == nil && scheme == SchemePKCS1v15
+}
+
+// OAEPOptions 转换为标准库的 OAEP 解密参数
+func (p *Padding) OAEPOptions() (*rsa.OAEPOptions, error) {
+	if p == nil {
+		return &rsa.OAEPOptions{Hash: crypto.SHA256, MGFHash: crypto.SHA256}, nil
+	}
+	hash, err := parseHash(p.Hash, crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	mgfHash, err := parseHash(p.MGFHash, hash)
+	if err != nil {
+		return nil, err
+	}
+	label, err := base64.StdEncoding.DecodeString(p.Label)
+	if err != nil {
+		return nil, fmt.Errorf("rsapad: label base64 decode failed: %v", err)
+	}
+	return &rsa.OAEPOptions{Hash: hash, MGFHash: mgfHash, Label: label}, nil
+}
+
+// String 用于日志的方案描述，如 OAEP-SHA256/MGF1-SHA1 或 PKCS1v15
+func (p *Padding) String() string {
+	scheme, err := p.scheme()
+	if err != nil {
+		return "unknown"
+	}
+	if scheme == SchemePKCS1v15 {
+		return "PKCS1v15"
+	}
+	opts, err := p.OAEPOptions()
+	if err != nil {
+		return "unknown"
+	}
+	name := func(h crypto.Hash) string { return strings.ReplaceAll(h.String(), "-", "") }
+	return "OAEP-" + name(opts.Hash) + "/MGF1-" + name(opts.MGFHash)
+}
+
+// Decrypt 按填充方案解密；PKCS#1 v1.5 填充无效时返回伪随机明文而不是错误
+func (p *Padding) Decrypt(privateKey *rsa.PrivateKey, ciphertext []byte) ([]byte, error) {
+	scheme, err := p.scheme()
+	if err != nil {
+		return nil, err
+	}
+	if scheme == SchemePKCS1v15 {
+		return decryptPKCS1v15(privateKey, ciphertext)
+	}
+
+	opts, err := p.OAEPOptions()
+	if err != nil {
+		return nil, err
+	}
+	plainText, err := privateKey.Decrypt(nil, ciphertext, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecryption, err)
+	}
+	return plainText, nil
+}
+
+// UnwrapKey 解包 size 字节的内容密钥。PKCS#1 v1.5 使用标准库的会话密钥解密：
+// 填充无效或长度不符时返回随机密钥，错误在之后的 AEAD 认证中才会暴露
+func (p *Padding) UnwrapKey(privateKey *rsa.PrivateKey, ciphertext []byte, size int) ([]byte, error) {
+	scheme, err := p.scheme()
+	if err != nil {
+		return nil, err
+	}
+	if scheme != SchemePKCS1v15 {
+		return p.Decrypt(privateKey, ciphertext)
+	}
+
+	key := make([]byte, size)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	if err := rsa.DecryptPKCS1v15SessionKey(nil, privateKey, ciphertext, key); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecryption, err)
+	}
+	return key, nil
+}
+
+// decryptPKCS1v15 带隐式拒绝的 PKCS#1 v1.5 解密
+func decryptPKCS1v15(privateKey *rsa.PrivateKey, ciphertext []byte) ([]byte, error) {
+	k := privateKey.Size()
+	// 密文长度是公开信息，可以直接拒绝
+	if len(ciphertext) != k || k < 11 {
+		return nil, ErrDecryption
+	}
+
+	// 伪随机明文总是预先计算，两条路径的开销一致
+	synthetic := syntheticMessage(privateKey, ciphertext)
+	plainText, err := rsa.DecryptPKCS1v15(nil, privateKey, ciphertext)
+	if err != nil {
+		return synthetic, nil
+	}
+	return plainText, nil
+}
+
+// syntheticMessage 按 draft-irtf-cfrg-rsa-guidance 派生伪随机明文：
+// KDK = HMAC-SHA256(SHA256(d), C)，长度和内容分别由以 "length"、"message" 为标签的 PRF 生成
+func syntheticMessage(privateKey *rsa.PrivateKey, ciphertext []byte) []byte {
+	k := privateKey.Size()
+	d := privateKey.D.FillBytes(make([]byte, k))
+	dHash := sha256.Sum256(d)
+	mac := hmac.New(sha256.New, dHash[:])
+	mac.Write(ciphertext)
+	kdk := mac.Sum(nil)
+
+	// 从 128 个候选长度中取最后一个不超过最大明文长度（k-11）的值，全程不分支
+	maxSep := k - 2 - 8
+	mask := maxSep
+	for shift := 1; shift < 16; shift <<= 1 {
+		mask |= mask >> shift
+	}
+	candidates := prf(kdk, "length", 128*2*8)
+	length := 0
+	for i := 0; i < len(candidates); i += 2 {
+		l := int(binary.BigEndian.Uint16(candidates[i:])) & mask
+		length = subtle.ConstantTimeSelect(subtle.ConstantTimeLessOrEq(l+1, maxSep), l, length)
+	}
+
+	message := prf(kdk, "message", k*8)
+	return message[k-length:]
+}
+
+// prf 以 KDK 为密钥的 HMAC-SHA256 计数器模式，输出 bits 位
+func prf(kdk []byte, label string, bits int) []byte {
+	out := make([]byte, 0, bits/8+sha256.Size)
+	var buf [2]byte
+	for i := 0; len(out) < bits/8; i++ {
+		mac := hmac.New(sha256.New, kdk)
+		binary.BigEndian.PutUint16(buf[:], uint16(i))
+		mac.Write(buf[:])
+		mac.Write([]byte(label))
+		binary.BigEndian.PutUint16(buf[:], uint16(bits))
+		mac.Write(buf[:])
+		out = mac.Sum(out)
+	}
+	return out[:bits/8]
+}
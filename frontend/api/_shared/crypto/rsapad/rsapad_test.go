@@ -0,0 +1,223 @@
+package rsapad
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"testing"
+)
+
+// wycheproofFile testdata 中截取的 Wycheproof RSA 解密向量
+type wycheproofFile struct {
+	TestGroups []struct {
+		PrivateKeyPEM string `json:"privateKeyPem"`
+		SHA           string `json:"sha"`
+		MGFSHA        string `json:"mgfSha"`
+		Tests         []struct {
+			TcID    int    `json:"tcId"`
+			Comment string `json:"comment"`
+			Msg     string `json:"msg"`
+			CT      string `json:"ct"`
+			Label   string `json:"label"`
+			Result  string `json:"result"`
+		} `json:"tests"`
+	} `json:"testGroups"`
+}
+
+func loadWycheproof(t *testing.T, name string) wycheproofFile {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f wycheproofFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func parseKey(t *testing.T, pemText string) *rsa.PrivateKey {
+	t.Helper()
+	block, _ := pem.Decode([]byte(pemText))
+	if block == nil {
+		t.Fatal("no PEM block")
+	}
+	// Wycheproof 的 PEM 标签为 PRIVATE KEY，内容却是 PKCS#1
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func decodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestWycheproofOAEP(t *testing.T) {
+	for _, g := range loadWycheproof(t, "wycheproof_rsa_oaep_2048_sha256_mgf1sha1.json").TestGroups {
+		key := parseKey(t, g.PrivateKeyPEM)
+		for _, tc := range g.Tests {
+			p := &Padding{Scheme: SchemeOAEP, Hash: g.SHA, MGFHash: g.MGFSHA, Label: base64.StdEncoding.EncodeToString(decodeHex(t, tc.Label))}
+			got, err := p.Decrypt(key, decodeHex(t, tc.CT))
+			switch tc.Result {
+			case "valid":
+				if err != nil || !bytes.Equal(got, decodeHex(t, tc.Msg)) {
+					t.Errorf("tc %d (%s): got %x, %v", tc.TcID, tc.Comment, got, err)
+				}
+			case "invalid":
+				if !errors.Is(err, ErrDecryption) {
+					t.Errorf("tc %d (%s): err = %v, want ErrDecryption", tc.TcID, tc.Comment, err)
+				}
+			}
+		}
+	}
+}
+
+// TestWycheproofPKCS1v15 有效密文解出原文；无效密文不报错，返回确定性的伪随机明文
+func TestWycheproofPKCS1v15(t *testing.T) {
+	p := &Padding{Scheme: SchemePKCS1v15}
+	for _, g := range loadWycheproof(t, "wycheproof_rsa_pkcs1_2048.json").TestGroups {
+		key := parseKey(t, g.PrivateKeyPEM)
+		for _, tc := range g.Tests {
+			ct := decodeHex(t, tc.CT)
+			got, err := p.Decrypt(key, ct)
+			if len(ct) != key.Size() {
+				if !errors.Is(err, ErrDecryption) {
+					t.Errorf("tc %d (%s): err = %v, want ErrDecryption for wrong length", tc.TcID, tc.Comment, err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("tc %d (%s): err = %v, want implicit rejection", tc.TcID, tc.Comment, err)
+				continue
+			}
+			switch tc.Result {
+			case "valid":
+				if !bytes.Equal(got, decodeHex(t, tc.Msg)) {
+					t.Errorf("tc %d (%s): got %x, want %s", tc.TcID, tc.Comment, got, tc.Msg)
+				}
+			case "invalid":
+				if len(got) > key.Size()-11 {
+					t.Errorf("tc %d (%s): synthetic message of %d bytes exceeds k-11", tc.TcID, tc.Comment, len(got))
+				}
+				if again, _ := p.Decrypt(key, ct); !bytes.Equal(again, got) {
+					t.Errorf("tc %d (%s): synthetic message is not deterministic", tc.TcID, tc.Comment)
+				}
+			}
+		}
+	}
+}
+
+func TestImplicitRejection(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Padding{Scheme: SchemePKCS1v15}
+	ct, _ := rsa.EncryptPKCS1v15(rand.Reader, &key.PublicKey, []byte("secret"))
+
+	// 用 OAEP 加密的密文按 PKCS#1 v1.5 解密：填充无效，得到伪随机明文而不是错误
+	oaepCT, _ := rsa.EncryptOAEP(sha256.New(), rand.Reader, &key.PublicKey, []byte("secret"), nil)
+	synthetic, err := p.Decrypt(key, oaepCT)
+	if err != nil {
+		t.Fatalf("invalid padding: err = %v, want implicit rejection", err)
+	}
+	if bytes.Equal(synthetic, []byte("secret")) {
+		t.Error("invalid padding returned the plaintext")
+	}
+
+	// 伪随机明文依赖私钥和密文：换密钥或换密文都会变化
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	if fromOther, err := p.Decrypt(other, ct); err != nil || bytes.Equal(fromOther, synthetic) {
+		t.Errorf("wrong key: got %x, %v", fromOther, err)
+	}
+	oaepCT2, _ := rsa.EncryptOAEP(sha256.New(), rand.Reader, &key.PublicKey, []byte("secret"), nil)
+	if synthetic2, _ := p.Decrypt(key, oaepCT2); bytes.Equal(synthetic2, synthetic) {
+		t.Error("different ciphertexts produced the same synthetic message")
+	}
+
+	if _, err := p.Decrypt(key, ct[1:]); !errors.Is(err, ErrDecryption) {
+		t.Errorf("short ciphertext: err = %v, want ErrDecryption", err)
+	}
+}
+
+func TestUnwrapKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cek := bytes.Repeat([]byte{7}, 32)
+
+	pkcs1 := &Padding{Scheme: "PKCS1"}
+	ct, _ := rsa.EncryptPKCS1v15(rand.Reader, &key.PublicKey, cek)
+	if got, err := pkcs1.UnwrapKey(key, ct, 32); err != nil || !bytes.Equal(got, cek) {
+		t.Errorf("PKCS#1 v1.5 unwrap: %x, %v", got, err)
+	}
+	// 长度不符时返回随机密钥，错误留给之后的 AEAD 认证
+	if got, err := pkcs1.UnwrapKey(key, ct, 16); err != nil || len(got) != 16 || bytes.Equal(got, cek[:16]) {
+		t.Errorf("PKCS#1 v1.5 wrong size: %x, %v", got, err)
+	}
+
+	var oaep *Padding
+	ct, _ = rsa.EncryptOAEP(sha256.New(), rand.Reader, &key.PublicKey, cek, nil)
+	if got, err := oaep.UnwrapKey(key, ct, 32); err != nil || !bytes.Equal(got, cek) {
+		t.Errorf("OAEP unwrap: %x, %v", got, err)
+	}
+	sha1Label := &Padding{Hash: "sha1", Label: base64.StdEncoding.EncodeToString([]byte("L"))}
+	ct, _ = rsa.EncryptOAEP(sha1.New(), rand.Reader, &key.PublicKey, cek, []byte("L"))
+	if got, err := sha1Label.UnwrapKey(key, ct, 32); err != nil || !bytes.Equal(got, cek) {
+		t.Errorf("OAEP SHA-1 with label: %x, %v", got, err)
+	}
+	if _, err := oaep.UnwrapKey(key, ct, 32); !errors.Is(err, ErrDecryption) {
+		t.Errorf("OAEP wrong hash: err = %v, want ErrDecryption", err)
+	}
+}
+
+func TestPaddingOptions(t *testing.T) {
+	for _, tc := range []struct {
+		padding *Padding
+		want    string
+		pkcs1   bool
+	}{
+		{nil, "OAEP-SHA256/MGF1-SHA256", false},
+		{&Padding{}, "OAEP-SHA256/MGF1-SHA256", false},
+		{&Padding{Hash: "SHA-512", MGFHash: "sha1"}, "OAEP-SHA512/MGF1-SHA1", false},
+		{&Padding{Scheme: "pkcs1-v1_5"}, "PKCS1v15", true},
+		{&Padding{Scheme: "rsa-kem"}, "unknown", false},
+		{&Padding{Hash: "MD5"}, "unknown", false},
+	} {
+		if got := tc.padding.String(); got != tc.want {
+			t.Errorf("%+v: String() = %s, want %s", tc.padding, got, tc.want)
+		}
+		if got := tc.padding.IsPKCS1v15(); got != tc.pkcs1 {
+			t.Errorf("%+v: IsPKCS1v15() = %v, want %v", tc.padding, got, tc.pkcs1)
+		}
+	}
+
+	key, _ := rsa.GenerateKey(rand.Reader, 1024)
+	if _, err := (&Padding{Scheme: "rsa-kem"}).Decrypt(key, nil); !errors.Is(err, ErrUnsupportedScheme) {
+		t.Errorf("unknown scheme: err = %v, want ErrUnsupportedScheme", err)
+	}
+	if _, err := (&Padding{MGFHash: "MD5"}).Decrypt(key, nil); !errors.Is(err, ErrUnsupportedHash) {
+		t.Errorf("unknown hash: err = %v, want ErrUnsupportedHash", err)
+	}
+	if _, err := (&Padding{Label: "!!"}).OAEPOptions(); err == nil {
+		t.Error("bad label base64: want error")
+	}
+}
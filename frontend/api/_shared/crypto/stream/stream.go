@@ -0,0 +1,277 @@
+// Package stream 实现分段 AEAD 流式加密（STREAM 构造，格式参考 Tink 的 AES-GCM-HKDF 流式 AEAD），
+// 以固定内存加解密任意长度的数据。
+//
+// 流格式：
+//
+//	header = version(1) || segmentSize(4, 大端) || salt(16) || noncePrefix(7)
+//	stream = header || segment_0 || segment_1 || ... || segment_n
+//
+// 每段以 AES-256-GCM 加密 segmentSize 字节明文（最后一段可以更短，也可以为空），
+// 段密钥由 HKDF-SHA256(key, salt, info = "aes-go-js stream v1" || header || aad) 派生，
+// 段 nonce 为 noncePrefix(7) || counter(4, 大端) || lastFlag(1)。
+// 计数器保证分段不能被重排或复制，最后一段标志保证流被截断时解密失败。
+//
+// HKDF 不会增加输入的熵，主密钥必须是 KeySize 字节的随机密钥；口令应先经 jsaes 的 KDF 派生。
+package stream
+
+import (
+	"bufio"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/LeeeeeeM/aes-go-js/pkg/jsaes"
+)
+
+const (
+	// Version 当前流格式版本
+	Version byte = 1
+	// HeaderSize 流头部长度
+	HeaderSize = 1 + 4 + saltSize + noncePrefixSize
+	// DefaultSegmentSize 默认每段明文长度
+	DefaultSegmentSize = 64 << 10
+	// MaxSegmentSize 每段明文长度上限，限制解密方为单段分配的内存
+	MaxSegmentSize = 16 << 20
+	// KeySize 主密钥长度，须为全熵随机密钥
+	KeySize = 32
+
+	saltSize        = 16
+	noncePrefixSize = 7
+	tagSize         = 16
+	hkdfInfo        = "aes-go-js stream v1"
+)
+
+var (
+	// ErrInvalidHeader 流头部格式错误或版本不受支持
+	ErrInvalidHeader = errors.New("stream: invalid header")
+	// ErrInvalidSegment 分段认证失败：密钥错误、数据被篡改、分段被重排或流被截断
+	ErrInvalidSegment = errors.New("stream: segment authentication failed")
+	// ErrTooManySegments 分段计数器溢出
+	ErrTooManySegments = errors.New("stream: too many segments")
+)
+
+// segmentCipher 保存派生的段密钥和 nonce 前缀
+type segmentCipher struct {
+	c      *jsaes.Cipher
+	prefix [noncePrefixSize]byte
+}
+
+// newSegmentCipher 由主密钥和流头部派生段密钥
+func newSegmentCipher(key, aad, header []byte) (*segmentCipher, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("%w: stream key must be %d random bytes, got %d", jsaes.ErrInvalidKeySize, KeySize, len(key))
+	}
+	salt := header[5 : 5+saltSize]
+	info := append(append([]byte(hkdfInfo), header...), aad...)
+	segmentKey, err := hkdf.Key(sha256.New, key, salt, string(info), KeySize)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := jsaes.NewCipher(segmentKey)
+	if err != nil {
+		return nil, err
+	}
+	s := &segmentCipher{c: c}
+	copy(s.prefix[:], header[5+saltSize:])
+	return s, nil
+}
+
+// nonce 计算第 counter 段的 nonce
+func (s *segmentCipher) nonce(counter uint32, last bool) []byte {
+	nonce := make([]byte, 12)
+	copy(nonce, s.prefix[:])
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], counter)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// Writer 把写入的明文分段加密后写到底层 io.Writer；必须调用 Close 写出最后一段
+type Writer struct {
+	w       io.Writer
+	s       *segmentCipher
+	buf     []byte
+	size    int
+	counter uint32
+	closed  bool
+	err     error
+}
+
+// NewWriter 写出流头部并返回加密 Writer；segmentSize 为 0 时使用 DefaultSegmentSize，aad 可为空
+func NewWriter(w io.Writer, key, aad []byte, segmentSize int) (*Writer, error) {
+	if segmentSize == 0 {
+		segmentSize = DefaultSegmentSize
+	}
+	if segmentSize < 1 || segmentSize > MaxSegmentSize {
+		return nil, fmt.Errorf("%w: segment size %d out of range", ErrInvalidHeader, segmentSize)
+	}
+
+	header := make([]byte, HeaderSize)
+	header[0] = Version
+	binary.BigEndian.PutUint32(header[1:5], uint32(segmentSize))
+	if _, err := io.ReadFull(rand.Reader, header[5:]); err != nil {
+		return nil, err
+	}
+
+	s, err := newSegmentCipher(key, aad, header)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &Writer{w: w, s: s, buf: make([]byte, 0, segmentSize), size: segmentSize}, nil
+}
+
+// Write 缓冲明文，攒满一段且后面还有数据时才加密写出，以便最后一段能带上结束标志
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	if w.closed {
+		return 0, errors.New("stream: write to closed writer")
+	}
+
+	written := 0
+	for len(p) > 0 {
+		if len(w.buf) == w.size {
+			if err := w.flush(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(w.buf[len(w.buf):w.size], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close 加密并写出最后一段（可能为空），不会关闭底层 io.Writer
+func (w *Writer) Close() error {
+	if w.closed {
+		return w.err
+	}
+	w.closed = true
+	if w.err != nil {
+		return w.err
+	}
+	return w.flush(true)
+}
+
+// flush 加密缓冲区中的一段并写出
+func (w *Writer) flush(last bool) error {
+	sealed, err := w.s.c.Seal(w.s.nonce(w.counter, last), w.buf)
+	if err == nil {
+		_, err = w.w.Write(sealed)
+	}
+	if err == nil && !last {
+		if w.counter == math.MaxUint32 {
+			err = ErrTooManySegments
+		}
+		w.counter++
+	}
+	if err != nil {
+		w.err = err
+		return err
+	}
+	w.buf = w.buf[:0]
+	return nil
+}
+
+// Reader 从底层 io.Reader 读取密文流并逐段解密；只会返回已通过认证的明文
+type Reader struct {
+	r       *bufio.Reader
+	s       *segmentCipher
+	buf     []byte
+	plain   []byte
+	counter uint32
+	done    bool
+	err     error
+}
+
+// NewReader 读取并校验流头部，返回解密 Reader；key 与 aad 须与加密时一致
+func NewReader(r io.Reader, key, aad []byte) (*Reader, error) {
+	header := make([]byte, HeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+	}
+	if header[0] != Version {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidHeader, header[0])
+	}
+	size := int(binary.BigEndian.Uint32(header[1:5]))
+	if size < 1 || size > MaxSegmentSize {
+		return nil, fmt.Errorf("%w: segment size %d out of range", ErrInvalidHeader, size)
+	}
+
+	s, err := newSegmentCipher(key, aad, header)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{
+		// 多缓冲 1 字节，用于判断当前段之后是否还有数据
+		r:   bufio.NewReaderSize(r, size+tagSize+1),
+		s:   s,
+		buf: make([]byte, size+tagSize),
+	}, nil
+}
+
+// Read 返回解密后的明文；流被截断、篡改或密钥错误时返回 ErrInvalidSegment
+func (r *Reader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.done {
+			return 0, io.EOF
+		}
+		r.err = r.next()
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+// next 读取并解密下一段
+func (r *Reader) next() error {
+	n, err := io.ReadFull(r.r, r.buf)
+	last := false
+	switch err {
+	case nil:
+		// 整段读满：后面没有数据则为最后一段
+		if _, err := r.r.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	case io.ErrUnexpectedEOF, io.EOF:
+		last = true
+	default:
+		return err
+	}
+	if n < tagSize {
+		return fmt.Errorf("%w: truncated segment %d", ErrInvalidSegment, r.counter)
+	}
+
+	plain, err := r.s.c.Open(r.s.nonce(r.counter, last), r.buf[:n])
+	if err != nil {
+		return fmt.Errorf("%w: segment %d", ErrInvalidSegment, r.counter)
+	}
+	if last {
+		r.done = true
+	} else {
+		if r.counter == math.MaxUint32 {
+			return ErrTooManySegments
+		}
+		r.counter++
+	}
+	r.plain = plain
+	return nil
+}
@@ -0,0 +1,143 @@
+package stream
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/LeeeeeeM/aes-go-js/pkg/jsaes"
+)
+
+const testSegmentSize = 16
+
+func testKey() []byte {
+	return bytes.Repeat([]byte{0x5a}, KeySize)
+}
+
+func encrypt(t *testing.T, key, aad, plainText []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, key, aad, testSegmentSize)
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	if _, err := w.Write(plainText); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func decrypt(key, aad, sealed []byte) ([]byte, error) {
+	r, err := NewReader(bytes.NewReader(sealed), key, aad)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, size := range []int{0, 1, testSegmentSize - 1, testSegmentSize, testSegmentSize + 1, 3 * testSegmentSize, 1000} {
+		plainText := make([]byte, size)
+		rand.Read(plainText)
+		sealed := encrypt(t, testKey(), []byte("aad"), plainText)
+
+		// 恰好整段时最后一段就是满的那段，只有空流才写出空的最后一段
+		segments := max(1, (size+testSegmentSize-1)/testSegmentSize)
+		if want := HeaderSize + size + segments*tagSize; len(sealed) != want {
+			t.Errorf("size %d: sealed length %d, want %d", size, len(sealed), want)
+		}
+
+		got, err := decrypt(testKey(), []byte("aad"), sealed)
+		if err != nil {
+			t.Fatalf("size %d: decrypt: %v", size, err)
+		}
+		if !bytes.Equal(got, plainText) {
+			t.Fatalf("size %d: round trip mismatch", size)
+		}
+	}
+}
+
+func TestTamper(t *testing.T) {
+	plainText := make([]byte, 3*testSegmentSize+5)
+	sealed := encrypt(t, testKey(), nil, plainText)
+	segment := testSegmentSize + tagSize
+	first := HeaderSize
+
+	tests := []struct {
+		name   string
+		sealed []byte
+	}{
+		{"flip ciphertext byte", func() []byte {
+			b := bytes.Clone(sealed)
+			b[first+3] ^= 1
+			return b
+		}()},
+		{"truncate last segment", sealed[:first+3*segment]},
+		{"truncate mid segment", sealed[:len(sealed)-1]},
+		{"drop first segment", append(bytes.Clone(sealed[:first]), sealed[first+segment:]...)},
+		{"swap segments", func() []byte {
+			b := bytes.Clone(sealed[:first])
+			b = append(b, sealed[first+segment:first+2*segment]...)
+			b = append(b, sealed[first:first+segment]...)
+			return append(b, sealed[first+2*segment:]...)
+		}()},
+		{"append data", append(bytes.Clone(sealed), 0)},
+		{"flip header salt", func() []byte {
+			b := bytes.Clone(sealed)
+			b[6] ^= 1
+			return b
+		}()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decrypt(testKey(), nil, tt.sealed); !errors.Is(err, ErrInvalidSegment) {
+				t.Errorf("err = %v, want ErrInvalidSegment", err)
+			}
+		})
+	}
+
+	otherKey := bytes.Repeat([]byte{1}, KeySize)
+	if _, err := decrypt(otherKey, nil, sealed); !errors.Is(err, ErrInvalidSegment) {
+		t.Errorf("wrong key: err = %v, want ErrInvalidSegment", err)
+	}
+	if _, err := decrypt(testKey(), []byte("aad"), sealed); !errors.Is(err, ErrInvalidSegment) {
+		t.Errorf("wrong aad: err = %v, want ErrInvalidSegment", err)
+	}
+}
+
+func TestInvalidHeader(t *testing.T) {
+	sealed := encrypt(t, testKey(), nil, []byte("hello"))
+
+	badVersion := bytes.Clone(sealed)
+	badVersion[0] = 2
+	if _, err := decrypt(testKey(), nil, badVersion); !errors.Is(err, ErrInvalidHeader) {
+		t.Errorf("bad version: err = %v, want ErrInvalidHeader", err)
+	}
+
+	hugeSegment := bytes.Clone(sealed)
+	hugeSegment[1] = 0xff
+	if _, err := decrypt(testKey(), nil, hugeSegment); !errors.Is(err, ErrInvalidHeader) {
+		t.Errorf("huge segment size: err = %v, want ErrInvalidHeader", err)
+	}
+
+	if _, err := decrypt(testKey(), nil, sealed[:HeaderSize-1]); !errors.Is(err, ErrInvalidHeader) {
+		t.Errorf("short header: err = %v, want ErrInvalidHeader", err)
+	}
+}
+
+func TestKeySize(t *testing.T) {
+	for _, size := range []int{0, 16, 24, 33} {
+		if _, err := NewWriter(io.Discard, make([]byte, size), nil, 0); !errors.Is(err, jsaes.ErrInvalidKeySize) {
+			t.Errorf("NewWriter(%d-byte key): err = %v, want ErrInvalidKeySize", size, err)
+		}
+	}
+	sealed := encrypt(t, testKey(), nil, nil)
+	if _, err := decrypt([]byte("password"), nil, sealed); !errors.Is(err, jsaes.ErrInvalidKeySize) {
+		t.Errorf("NewReader(password): err = %v, want ErrInvalidKeySize", err)
+	}
+}
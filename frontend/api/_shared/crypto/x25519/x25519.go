@@ -0,0 +1,91 @@
+// Package x25519 实现临时 X25519 ECDH 密钥协商，为会话提供前向保密。
+//
+// 双方各自生成一次性的 X25519 密钥对并交换公钥，由共享秘密派生 AES-256-GCM 密钥：
+//
+//	key = HKDF-SHA256(ikm = X25519(priv, peerPub), salt = "",
+//	                  info = "aes-go-js x25519 v1" || clientPublicKey || serverPublicKey)
+//
+// info 绑定了完整的握手记录（双方公钥），中间人替换任一公钥都会得到不同的密钥。
+// 服务端私钥用完即弃，事后泄露长期密钥（RSA）也无法还原会话密钥。
+package x25519
+
+import (
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+const (
+	// PublicKeySize X25519 公钥长度
+	PublicKeySize = 32
+	// KeySize 派生的 AES-256-GCM 密钥长度
+	KeySize = 32
+
+	hkdfInfo = "aes-go-js x25519 v1"
+)
+
+// ErrInvalidPublicKey 对方公钥长度错误或为低阶点（共享秘密全零）
+var ErrInvalidPublicKey = errors.New("x25519: invalid public key")
+
+// DeriveKey 由共享秘密和握手记录派生 AES-256-GCM 密钥
+func DeriveKey(secret, clientPublic, serverPublic []byte) ([]byte, error) {
+	info := append(append([]byte(hkdfInfo), clientPublic...), serverPublic...)
+	return hkdf.Key(sha256.New, secret, nil, string(info), KeySize)
+}
+
+// Respond 服务端一侧：生成临时密钥对，与客户端公钥协商，返回服务端公钥和派生密钥
+func Respond(clientPublic []byte) (serverPublic, key []byte, err error) {
+	peer, err := ecdh.X25519().NewPublicKey(clientPublic)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+	}
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	secret, err := priv.ECDH(peer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+	}
+	serverPublic = priv.PublicKey().Bytes()
+	key, err = DeriveKey(secret, clientPublic, serverPublic)
+	if err != nil {
+		return nil, nil, err
+	}
+	return serverPublic, key, nil
+}
+
+// Client 客户端一侧的临时密钥对
+type Client struct {
+	priv *ecdh.PrivateKey
+}
+
+// NewClient 生成客户端临时密钥对
+func NewClient() (*Client, error) {
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{priv: priv}, nil
+}
+
+// PublicKey 发送给服务端的客户端公钥
+func (c *Client) PublicKey() []byte {
+	return c.priv.PublicKey().Bytes()
+}
+
+// Finish 用服务端公钥完成协商，返回与服务端一致的派生密钥
+func (c *Client) Finish(serverPublic []byte) ([]byte, error) {
+	peer, err := ecdh.X25519().NewPublicKey(serverPublic)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+	}
+	secret, err := c.priv.ECDH(peer)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+	}
+	return DeriveKey(secret, c.PublicKey(), serverPublic)
+}
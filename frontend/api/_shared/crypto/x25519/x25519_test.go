@@ -0,0 +1,109 @@
+package x25519
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func decodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestHandshake(t *testing.T) {
+	c, err := NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverPublic, serverKey, err := Respond(c.PublicKey())
+	if err != nil {
+		t.Fatalf("Respond: %v", err)
+	}
+	clientKey, err := c.Finish(serverPublic)
+	if err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+	if len(serverKey) != KeySize || !bytes.Equal(clientKey, serverKey) {
+		t.Fatalf("keys differ: client %x, server %x", clientKey, serverKey)
+	}
+
+	// 服务端每次都生成新的临时密钥对
+	serverPublic2, serverKey2, _ := Respond(c.PublicKey())
+	if bytes.Equal(serverPublic, serverPublic2) || bytes.Equal(serverKey, serverKey2) {
+		t.Error("Respond reused its ephemeral key")
+	}
+}
+
+func TestTranscriptBinding(t *testing.T) {
+	c, _ := NewClient()
+	serverPublic, serverKey, err := Respond(c.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 中间人用自己的公钥替换服务端公钥：客户端得到的密钥与服务端不同
+	mitm, _ := NewClient()
+	clientKey, err := c.Finish(mitm.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(clientKey, serverKey) {
+		t.Error("substituted server key produced the server's key")
+	}
+
+	// 相同共享秘密、不同握手记录派生出不同密钥
+	secret := bytes.Repeat([]byte{7}, 32)
+	k1, _ := DeriveKey(secret, c.PublicKey(), serverPublic)
+	k2, _ := DeriveKey(secret, serverPublic, c.PublicKey())
+	if bytes.Equal(k1, k2) {
+		t.Error("DeriveKey ignores the transcript order")
+	}
+}
+
+// TestRFC7748Vector RFC 7748 §6.1 的 Alice/Bob 密钥：Finish 使用该共享秘密派生密钥
+func TestRFC7748Vector(t *testing.T) {
+	alicePriv, err := ecdh.X25519().NewPrivateKey(decodeHex(t, "77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bobPublic := decodeHex(t, "de9edb7d7b7dc1b4d35b61c2ece435373f8343c85b78674dadfc7e146f882b4f")
+	shared := decodeHex(t, "4a5d9d5ba4ce2de1728e3bf480350f25e07e21c947d19e3376f09b3c1e161742")
+
+	c := &Client{priv: alicePriv}
+	if got := hex.EncodeToString(c.PublicKey()); got != "8520f0098930a754748b7ddcb43ef75a0dbf3a0d26381af4eba4a98eaa9b4e6a" {
+		t.Fatalf("PublicKey() = %s", got)
+	}
+	got, err := c.Finish(bobPublic)
+	if err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+	want, _ := DeriveKey(shared, c.PublicKey(), bobPublic)
+	if !bytes.Equal(got, want) {
+		t.Errorf("Finish = %x, want %x", got, want)
+	}
+}
+
+func TestInvalidPublicKey(t *testing.T) {
+	c, _ := NewClient()
+	for name, pub := range map[string][]byte{
+		"empty":      nil,
+		"short":      make([]byte, PublicKeySize-1),
+		"long":       make([]byte, PublicKeySize+1),
+		"zero point": make([]byte, PublicKeySize), // 低阶点，共享秘密全零
+		"order 8":    decodeHex(t, "e0eb7a7c3b41b8ae1656e3faf19fc46ada098deb9c32b1fd866205165f49b800"),
+	} {
+		if _, _, err := Respond(pub); !errors.Is(err, ErrInvalidPublicKey) {
+			t.Errorf("Respond(%s): err = %v, want ErrInvalidPublicKey", name, err)
+		}
+		if _, err := c.Finish(pub); !errors.Is(err, ErrInvalidPublicKey) {
+			t.Errorf("Finish(%s): err = %v, want ErrInvalidPublicKey", name, err)
+		}
+	}
+}
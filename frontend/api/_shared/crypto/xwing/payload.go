@@ -0,0 +1,64 @@
+package xwing
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/LeeeeeeM/aes-go-js/pkg/jsaes"
+)
+
+// Payload X-Wing 封装密钥 + AES-GCM 正文的传输结构，各字段均为标准 Base64，
+// 与 hybrid.Payload 的区别只是用 encapsulatedKey 代替 RSA 包裹的 encryptedKey
+type Payload struct {
+	EncapsulatedKey string `json:"encapsulatedKey"` // X-Wing 封装密文（1120 字节）
+	IV              string `json:"iv"`              // 12 字节 GCM IV
+	EncryptedData   string `json:"encryptedData"`   // 密文 + 16 字节认证标签
+	KID             string `json:"kid,omitempty"`   // 封装所用公钥的 kid
+}
+
+// Encrypt 向封装公钥封装内容密钥，并用它以 AES-256-GCM 加密正文
+func Encrypt(publicKey, plainText, aad []byte) (*Payload, error) {
+	key, ciphertext, err := Encapsulate(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	c, err := jsaes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	cipherB64, ivB64, err := c.EncryptWithAAD(plainText, aad)
+	if err != nil {
+		return nil, err
+	}
+	return &Payload{
+		EncapsulatedKey: base64.StdEncoding.EncodeToString(ciphertext),
+		IV:              ivB64,
+		EncryptedData:   cipherB64,
+		KID:             KeyID(publicKey),
+	}, nil
+}
+
+// UnwrapKey 解封装 Base64 编码的封装密文，返回 32 字节内容密钥；kid 不为空时必须与私钥对应
+func (k *PrivateKey) UnwrapKey(kid, encapsulatedKeyB64 string) ([]byte, error) {
+	if kid != "" && kid != k.KeyID() {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKeyID, kid)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(encapsulatedKeyB64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: encapsulated key base64 decode failed: %v", jsaes.ErrInvalidBase64, err)
+	}
+	return k.Decapsulate(ciphertext)
+}
+
+// Decrypt 解封装内容密钥并解密正文
+func (k *PrivateKey) Decrypt(p *Payload, aad []byte) ([]byte, error) {
+	key, err := k.UnwrapKey(p.KID, p.EncapsulatedKey)
+	if err != nil {
+		return nil, err
+	}
+	c, err := jsaes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return c.DecryptWithAAD(p.EncryptedData, p.IV, aad)
+}
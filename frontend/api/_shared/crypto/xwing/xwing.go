@@ -0,0 +1,216 @@
+// Package xwing 实现 X-Wing 混合后量子 KEM（draft-connolly-cfrg-xwing-kem）：
+// X25519 与 ML-KEM-768（FIPS 203）并联，只要其中一个安全，共享秘密就安全，
+// 用于抵御"先存储、后解密"的量子攻击。
+//
+//	公钥     = pk_M(1184) || pk_X(32)
+//	封装密文 = ct_M(1088) || ct_X(32)
+//	共享秘密 = SHA3-256(ss_M || ss_X || ct_X || pk_X || "\.//^\")
+//
+// 私钥为 32 字节种子，经 SHAKE256 扩展为 ML-KEM 种子 (d, z) 和 X25519 私钥。
+// 共享秘密为 32 字节，直接作为 AES-256-GCM 内容密钥，与 RSA 包裹的密钥用法相同。
+package xwing
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/mlkem"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha3"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/keyfile"
+)
+
+const (
+	// SeedSize 私钥种子长度
+	SeedSize = 32
+	// PublicKeySize 封装公钥长度
+	PublicKeySize = mlkem.EncapsulationKeySize768 + 32
+	// CiphertextSize 封装密文长度
+	CiphertextSize = mlkem.CiphertextSize768 + 32
+	// SharedKeySize 共享秘密长度
+	SharedKeySize = 32
+
+	// Algorithm 对外公布的算法名
+	Algorithm = "X-Wing"
+
+	pemType = "X-WING PRIVATE KEY"
+	label   = `\.//^\`
+)
+
+var (
+	// ErrInvalidPublicKey 封装公钥格式错误
+	ErrInvalidPublicKey = errors.New("xwing: invalid public key")
+	// ErrInvalidCiphertext 封装密文格式错误
+	ErrInvalidCiphertext = errors.New("xwing: invalid ciphertext")
+	// ErrUnknownKeyID kid 与服务端公钥不一致
+	ErrUnknownKeyID = errors.New("xwing: unknown key id")
+)
+
+// PrivateKey X-Wing 解封装私钥
+type PrivateKey struct {
+	seed []byte
+	m    *mlkem.DecapsulationKey768
+	x    *ecdh.PrivateKey
+	pk   []byte
+}
+
+// GenerateKey 生成新的私钥
+func GenerateKey() (*PrivateKey, error) {
+	seed := make([]byte, SeedSize)
+	if _, err := io.ReadFull(rand.Reader, seed); err != nil {
+		return nil, err
+	}
+	return NewPrivateKey(seed)
+}
+
+// NewPrivateKey 由 32 字节种子展开私钥
+func NewPrivateKey(seed []byte) (*PrivateKey, error) {
+	if len(seed) != SeedSize {
+		return nil, fmt.Errorf("xwing: seed must be %d bytes, got %d", SeedSize, len(seed))
+	}
+	expanded := make([]byte, 96)
+	h := sha3.NewSHAKE256()
+	h.Write(seed)
+	h.Read(expanded)
+
+	m, err := mlkem.NewDecapsulationKey768(expanded[:64])
+	if err != nil {
+		return nil, err
+	}
+	x, err := ecdh.X25519().NewPrivateKey(expanded[64:])
+	if err != nil {
+		return nil, err
+	}
+	pk := append(m.EncapsulationKey().Bytes(), x.PublicKey().Bytes()...)
+	return &PrivateKey{seed: bytes.Clone(seed), m: m, x: x, pk: pk}, nil
+}
+
+// Seed 返回私钥种子
+func (k *PrivateKey) Seed() []byte {
+	return bytes.Clone(k.seed)
+}
+
+// PublicKey 返回封装公钥
+func (k *PrivateKey) PublicKey() []byte {
+	return bytes.Clone(k.pk)
+}
+
+// KeyID 公钥的 SHA-256（Base64URL），用于客户端确认封装所用公钥
+func (k *PrivateKey) KeyID() string {
+	return KeyID(k.pk)
+}
+
+// KeyID 计算封装公钥的 kid
+func KeyID(publicKey []byte) string {
+	sum := sha256.Sum256(publicKey)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// combine X-Wing 共享秘密组合函数
+func combine(ssM, ssX, ctX, pkX []byte) []byte {
+	h := sha3.New256()
+	h.Write(ssM)
+	h.Write(ssX)
+	h.Write(ctX)
+	h.Write(pkX)
+	h.Write([]byte(label))
+	return h.Sum(nil)
+}
+
+// Encapsulate 用封装公钥生成共享秘密和封装密文
+func Encapsulate(publicKey []byte) (sharedKey, ciphertext []byte, err error) {
+	if len(publicKey) != PublicKeySize {
+		return nil, nil, fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidPublicKey, PublicKeySize, len(publicKey))
+	}
+	pkM, pkX := publicKey[:mlkem.EncapsulationKeySize768], publicKey[mlkem.EncapsulationKeySize768:]
+
+	ek, err := mlkem.NewEncapsulationKey768(pkM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+	}
+	peer, err := ecdh.X25519().NewPublicKey(pkX)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+	}
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	ssX, err := ephemeral.ECDH(peer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+	}
+	ctX := ephemeral.PublicKey().Bytes()
+	ssM, ctM := ek.Encapsulate()
+
+	return combine(ssM, ssX, ctX, pkX), append(ctM, ctX...), nil
+}
+
+// Decapsulate 用私钥从封装密文恢复共享秘密；ML-KEM 部分采用隐式拒绝，
+// 被篡改的密文得到的是与封装方不一致的随机秘密，而不是错误
+func (k *PrivateKey) Decapsulate(ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) != CiphertextSize {
+		return nil, fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidCiphertext, CiphertextSize, len(ciphertext))
+	}
+	ctM, ctX := ciphertext[:mlkem.CiphertextSize768], ciphertext[mlkem.CiphertextSize768:]
+
+	ssM, err := k.m.Decapsulate(ctM)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCiphertext, err)
+	}
+	peer, err := ecdh.X25519().NewPublicKey(ctX)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCiphertext, err)
+	}
+	ssX, err := k.x.ECDH(peer)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCiphertext, err)
+	}
+	return combine(ssM, ssX, ctX, k.pk[mlkem.EncapsulationKeySize768:]), nil
+}
+
+// ParsePrivateKeyPEM 解析 "X-WING PRIVATE KEY" PEM（内容为 32 字节种子）
+func ParsePrivateKeyPEM(data []byte) (*PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != pemType {
+		return nil, fmt.Errorf("failed to decode X-Wing private key PEM")
+	}
+	return NewPrivateKey(block.Bytes)
+}
+
+// MarshalPrivateKeyPEM 将私钥种子编码为 PEM
+func MarshalPrivateKeyPEM(k *PrivateKey) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: pemType, Bytes: k.seed})
+}
+
+// LoadFile 从文件读取私钥；generate 为 true 时文件不存在则生成并以 0600 权限保存
+func LoadFile(path string, generate bool) (*PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return ParsePrivateKeyPEM(data)
+	}
+	if !errors.Is(err, os.ErrNotExist) || !generate {
+		return nil, fmt.Errorf("failed to read X-Wing key file: %v", err)
+	}
+
+	key, err := GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	pemBytes := MarshalPrivateKeyPEM(key)
+	if data, err = keyfile.Persist(path, pemBytes); err != nil {
+		return nil, fmt.Errorf("failed to persist X-Wing key file: %v", err)
+	}
+	// 并发启动时其他进程可能先保存了私钥，以文件中的为准
+	if !bytes.Equal(data, pemBytes) {
+		return ParsePrivateKeyPEM(data)
+	}
+	return key, nil
+}
@@ -0,0 +1,41 @@
+package shared
+
+import (
+	"crypto/ecdh"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/hpke"
+)
+
+var (
+	hpkeKey       *ecdh.PrivateKey
+	hpkeOnce      sync.Once
+	hpkeInitError error
+)
+
+// initHPKEKey 初始化 HPKE 接收方私钥（一次性初始化）
+//
+// 依次从 HPKE_PRIVATE_KEY 环境变量、HPKE_PRIVATE_KEY_FILE 指向的文件读取 PKCS#8 PEM 格式的 X25519 私钥；
+// 各函数实例必须使用同一把私钥，因此不会自动生成
+func initHPKEKey() {
+	if v := os.Getenv("HPKE_PRIVATE_KEY"); v != "" {
+		hpkeKey, hpkeInitError = hpke.ParsePrivateKeyPEM([]byte(v))
+		return
+	}
+	if path := os.Getenv("HPKE_PRIVATE_KEY_FILE"); path != "" {
+		hpkeKey, hpkeInitError = hpke.LoadFile(path, false)
+		return
+	}
+	hpkeInitError = fmt.Errorf("HPKE_PRIVATE_KEY or HPKE_PRIVATE_KEY_FILE is not set")
+}
+
+// GetHPKEKey 获取 HPKE（DHKEM(X25519, HKDF-SHA256)）接收方私钥
+func GetHPKEKey() (*ecdh.PrivateKey, error) {
+	hpkeOnce.Do(initHPKEKey)
+	if hpkeInitError != nil {
+		return nil, hpkeInitError
+	}
+	return hpkeKey, nil
+}
@@ -0,0 +1,37 @@
+package shared
+
+import (
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/logging"
+)
+
+var (
+	logger     *slog.Logger
+	loggerOnce sync.Once
+)
+
+// initLogger 按 LOG_LEVEL（debug/info/warn/error）和 LOG_FORMAT（text/json）创建脱敏日志器，
+// 并设为 slog 默认日志器，使其余 log.Printf 输出也走同一个处理器；配置无效时退回 info/text
+func initLogger() {
+	level, levelErr := logging.ParseLevel(os.Getenv("LOG_LEVEL"))
+	l, formatErr := logging.New(os.Stderr, level, os.Getenv("LOG_FORMAT"))
+	if formatErr != nil {
+		l, _ = logging.New(os.Stderr, level, "text")
+	}
+	for _, err := range []error{levelErr, formatErr} {
+		if err != nil {
+			l.Warn("Invalid logging configuration, using defaults", "error", err)
+		}
+	}
+	logger = l
+	slog.SetDefault(logger)
+}
+
+// Logger 获取脱敏日志器
+func Logger() *slog.Logger {
+	loggerOnce.Do(initLogger)
+	return logger
+}
@@ -0,0 +1,104 @@
+// Package logging 提供带脱敏层的 log/slog 日志器，保证密钥和明文不会进入日志。
+//
+// 脱敏分两层：
+//   - 值类型：Secret 只输出长度，Plaintext 只输出长度和带密钥的哈希
+//   - 字段名：key、secret、password、token 等敏感字段名的值一律替换为 [REDACTED]，
+//     防止有人直接把原始字符串写进日志
+//
+// debug 级别只打开更详细的处理步骤，不会解除脱敏。
+package logging
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Redacted 敏感值的替换文本
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys 值必须脱敏的字段名（小写，忽略 - 和 _）
+var sensitiveKeys = map[string]bool{
+	"key":           true,
+	"secret":        true,
+	"password":      true,
+	"passphrase":    true,
+	"privatekey":    true,
+	"token":         true,
+	"authorization": true,
+	"apikey":        true,
+	"plaintext":     true,
+	"decrypteddata": true,
+}
+
+// hashKey 明文摘要的 HMAC 密钥，进程启动时随机生成：同一进程内相同明文的摘要相同，
+// 便于关联日志，但无法对短明文做字典还原
+var hashKey = func() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return key
+}()
+
+// Secret 密钥等敏感字节，日志中只输出长度
+type Secret []byte
+
+// LogValue 实现 slog.LogValuer
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(fmt.Sprintf("%s len=%d", Redacted, len(s)))
+}
+
+// Plaintext 解密得到的明文，日志中只输出长度和 HMAC-SHA256 前 8 字节
+type Plaintext []byte
+
+// LogValue 实现 slog.LogValuer
+func (p Plaintext) LogValue() slog.Value {
+	mac := hmac.New(sha256.New, hashKey)
+	mac.Write(p)
+	return slog.GroupValue(
+		slog.Int("len", len(p)),
+		slog.String("hmac", hex.EncodeToString(mac.Sum(nil)[:8])),
+	)
+}
+
+// ParseLevel 解析日志级别：debug、info（默认）、warn、error
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if s == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("invalid log level %q", s)
+	}
+	return level, nil
+}
+
+// New 创建带脱敏层的日志器，format 为 text（默认）或 json
+func New(w io.Writer, level slog.Leveler, format string) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: level, ReplaceAttr: redact}
+	switch format {
+	case "", "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	}
+	return nil, fmt.Errorf("invalid log format %q", format)
+}
+
+// redact 替换敏感字段名的值；已经由 Secret 脱敏的值保留长度信息
+func redact(groups []string, a slog.Attr) slog.Attr {
+	name := strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(a.Key))
+	if !sensitiveKeys[name] {
+		return a
+	}
+	if a.Value.Kind() == slog.KindString && strings.HasPrefix(a.Value.String(), Redacted) {
+		return a
+	}
+	return slog.String(a.Key, Redacted)
+}
@@ -0,0 +1,94 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRedactsSensitiveFields(t *testing.T) {
+	for _, format := range []string{"text", "json"} {
+		var buf bytes.Buffer
+		logger, err := New(&buf, slog.LevelDebug, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger.Info("request",
+			"key", "k3y-material",
+			"API_Key", "abc123",
+			"private-key", "-----BEGIN",
+			"Authorization", "Bearer s3cr3t",
+			"decryptedData", "hello world",
+			"kid", "public-kid",
+			slog.Group("req", "password", "hunter2"),
+		)
+		out := buf.String()
+		for _, leaked := range []string{"k3y-material", "abc123", "-----BEGIN", "s3cr3t", "hello world", "hunter2"} {
+			if strings.Contains(out, leaked) {
+				t.Errorf("%s: %q leaked: %s", format, leaked, out)
+			}
+		}
+		if !strings.Contains(out, "public-kid") {
+			t.Errorf("%s: non-sensitive field redacted: %s", format, out)
+		}
+		if n := strings.Count(out, Redacted); n != 6 {
+			t.Errorf("%s: %d redactions, want 6: %s", format, n, out)
+		}
+	}
+}
+
+func TestSecretAndPlaintext(t *testing.T) {
+	var buf bytes.Buffer
+	logger, _ := New(&buf, slog.LevelInfo, "json")
+	logger.Info("decrypted",
+		"sessionKey", Secret("0123456789abcdef"),
+		"key", Secret("0123456789abcdef"),
+		"plaintext", Plaintext("attack at dawn"),
+		"other", Plaintext("attack at dawn"),
+	)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("%v: %s", err, buf.String())
+	}
+	want := Redacted + " len=16"
+	if entry["sessionKey"] != want || entry["key"] != want {
+		t.Errorf("Secret = %v / %v, want %q", entry["sessionKey"], entry["key"], want)
+	}
+	if strings.Contains(buf.String(), "attack at dawn") || strings.Contains(buf.String(), "0123456789abcdef") {
+		t.Fatalf("value leaked: %s", buf.String())
+	}
+
+	// 同一进程内相同明文的摘要相同，不同明文的摘要不同
+	a, b := Plaintext("attack at dawn").LogValue(), Plaintext("attack at dusk").LogValue()
+	if a.String() != Plaintext("attack at dawn").LogValue().String() || a.String() == b.String() {
+		t.Errorf("Plaintext digests: %s / %s", a, b)
+	}
+	if other, ok := entry["other"].(map[string]interface{}); !ok || other["len"] != float64(14) || len(other["hmac"].(string)) != 16 {
+		t.Errorf("Plaintext = %v", entry["other"])
+	}
+}
+
+func TestParseLevelAndFormat(t *testing.T) {
+	for in, want := range map[string]slog.Level{"": slog.LevelInfo, "debug": slog.LevelDebug, "WARN": slog.LevelWarn, "error": slog.LevelError} {
+		if got, err := ParseLevel(in); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v", in, got, err)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("ParseLevel(verbose): want error")
+	}
+	if _, err := New(&bytes.Buffer{}, slog.LevelInfo, "xml"); err == nil {
+		t.Error("New(xml): want error")
+	}
+
+	// debug 只在 debug 级别输出
+	var buf bytes.Buffer
+	logger, _ := New(&buf, slog.LevelInfo, "")
+	logger.Debug("step")
+	if buf.Len() != 0 {
+		t.Errorf("debug logged at info level: %s", buf.String())
+	}
+}
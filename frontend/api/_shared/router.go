@@ -0,0 +1,40 @@
+package shared
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/router"
+)
+
+var (
+	handler     http.Handler
+	handlerOnce sync.Once
+)
+
+// Router 获取挂载全部接口的路由（一次性初始化），各 Vercel 函数入口都转交给它处理
+func Router() http.Handler {
+	handlerOnce.Do(func() {
+		// 跨域配置无效时拒绝所有跨域请求，而不是退回允许任意来源
+		cors, err := loadCORSPolicy()
+		if err != nil {
+			Logger().Error("Invalid CORS configuration, cross-origin requests are rejected", "error", err)
+			cors, _ = router.NewCORSPolicy(router.CORSConfig{})
+		}
+		if auth, err := GetAuthenticator(); err == nil && auth != nil && cors.AllowsAnyOrigin() {
+			Logger().Warn("CORS allows any origin while authentication is enabled; set CORS_ALLOWED_ORIGINS to the sites that hold credentials")
+		}
+		handler = router.New(router.Deps{
+			RSAKeyring:     GetRSAKeyring,
+			SigningKeyring: GetSigningKeyring,
+			XWingKey:       GetXWingKey,
+			HPKEKey:        GetHPKEKey,
+			SessionStore:   GetSessionStore,
+			ResponseSigner: GetResponseSigner,
+			Auth:           GetAuthenticator,
+			CORS:           cors,
+			Logger:         Logger(),
+		})
+	})
+	return handler
+}
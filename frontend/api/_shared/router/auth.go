@@ -0,0 +1,371 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 认证相关请求头与 HMAC 方案名
+const (
+	APIKeyHeader = "X-API-Key"
+	HMACScheme   = "HMAC-SHA256"
+)
+
+// DefaultClockSkew HMAC 签名时间戳与服务器时间的默认允许偏差
+const DefaultClockSkew = 5 * time.Minute
+
+// HMAC 认证缓冲请求体的上限：memBodyLimit 以内留在内存中，超出部分写入临时文件；
+// 签名请求的请求体不能超过 maxSignedBodySize（超过返回 413），更大的文件请使用 API 密钥认证
+const (
+	memBodyLimit      = 8 << 20
+	maxSignedBodySize = 64 << 20
+)
+
+var (
+	// ErrNoCredentials 请求未携带该认证方式的凭据
+	ErrNoCredentials = errors.New("authentication required")
+	// ErrInvalidAPIKey API 密钥无效
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	// ErrMalformedAuthorization Authorization 头格式错误
+	ErrMalformedAuthorization = errors.New("malformed Authorization header")
+	// ErrInvalidSignature 请求签名无效或 keyId 未知
+	ErrInvalidSignature = errors.New("invalid request signature")
+	// ErrClockSkew 请求时间戳超出允许偏差
+	ErrClockSkew = errors.New("request timestamp outside allowed clock skew")
+	// ErrKeyNotAllowed 密钥无权调用该接口
+	ErrKeyNotAllowed = errors.New("key is not allowed to call this endpoint")
+
+	// errReadBody 缓冲请求体失败（客户端断开或超过上传上限），不是认证错误
+	errReadBody = errors.New("failed to read request body")
+)
+
+// AuthKey 访问密钥
+type AuthKey struct {
+	ID     string   `json:"id"`
+	Secret string   `json:"secret"`
+	Paths  []string `json:"paths,omitempty"` // 允许调用的接口路径，为空时不限制
+}
+
+// Allows 判断密钥是否允许调用 path
+func (k *AuthKey) Allows(path string) bool {
+	if len(k.Paths) == 0 {
+		return true
+	}
+	for _, p := range k.Paths {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseAuthKeys 解析密钥配置：JSON 数组（[{"id":"web","secret":"...","paths":["/api/process"]}]），
+// 或逗号分隔的 id:secret 列表
+func ParseAuthKeys(data []byte) ([]AuthKey, error) {
+	var keys []AuthKey
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("[")) {
+		if err := json.Unmarshal(trimmed, &keys); err != nil {
+			return nil, fmt.Errorf("invalid auth keys: %v", err)
+		}
+	} else {
+		for _, item := range SplitList(string(trimmed)) {
+			id, secret, ok := strings.Cut(item, ":")
+			if !ok {
+				return nil, errors.New("invalid auth keys: expected id:secret")
+			}
+			keys = append(keys, AuthKey{ID: strings.TrimSpace(id), Secret: strings.TrimSpace(secret)})
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, k := range keys {
+		if k.ID == "" || k.Secret == "" {
+			return nil, errors.New("invalid auth keys: id and secret are required")
+		}
+		if seen[k.ID] {
+			return nil, fmt.Errorf("invalid auth keys: duplicate id %q", k.ID)
+		}
+		seen[k.ID] = true
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("invalid auth keys: no keys configured")
+	}
+	return keys, nil
+}
+
+// LoadAuthKeysFile 从文件读取密钥配置
+func LoadAuthKeysFile(path string) ([]AuthKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseAuthKeys(data)
+}
+
+// Authenticator 请求认证方式
+type Authenticator interface {
+	// Authenticate 校验请求并返回对应的密钥；请求未携带本方式的凭据时返回 ErrNoCredentials
+	Authenticate(r *http.Request) (*AuthKey, error)
+}
+
+// bodyReader 需要读取请求体的认证方式
+type bodyReader interface {
+	readsBody() bool
+}
+
+// Authenticators 依次尝试多种认证方式，使用请求携带了凭据的第一种
+//
+// 只看请求头的方式（API 密钥）总是先于需要读取请求体的方式（HMAC）尝试，
+// 与配置顺序无关：携带无效 API 密钥的请求在读取请求体之前就被拒绝
+type Authenticators []Authenticator
+
+// Authenticate 实现 Authenticator
+func (a Authenticators) Authenticate(r *http.Request) (*AuthKey, error) {
+	for _, pass := range []bool{false, true} {
+		for _, auth := range a {
+			if b, ok := auth.(bodyReader); (ok && b.readsBody()) != pass {
+				continue
+			}
+			key, err := auth.Authenticate(r)
+			if !errors.Is(err, ErrNoCredentials) {
+				return key, err
+			}
+		}
+	}
+	return nil, ErrNoCredentials
+}
+
+// APIKeyAuth 静态 API 密钥：X-API-Key: <secret> 或 Authorization: Bearer <secret>
+type APIKeyAuth struct {
+	keys map[[sha256.Size]byte]*AuthKey
+}
+
+// NewAPIKeyAuth 创建 API 密钥认证
+func NewAPIKeyAuth(keys []AuthKey) *APIKeyAuth {
+	a := &APIKeyAuth{keys: make(map[[sha256.Size]byte]*AuthKey)}
+	for i := range keys {
+		a.keys[sha256.Sum256([]byte(keys[i].Secret))] = &keys[i]
+	}
+	return a
+}
+
+// Authenticate 实现 Authenticator；按密钥摘要查找，查找耗时与密钥内容无关
+func (a *APIKeyAuth) Authenticate(r *http.Request) (*AuthKey, error) {
+	secret := r.Header.Get(APIKeyHeader)
+	if secret == "" {
+		if v, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			secret = strings.TrimSpace(v)
+		}
+	}
+	if secret == "" {
+		return nil, ErrNoCredentials
+	}
+	key, ok := a.keys[sha256.Sum256([]byte(secret))]
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+	return key, nil
+}
+
+// HMACAuth HMAC-SHA256 请求签名：
+//
+//	Authorization: HMAC-SHA256 keyId=<id>, timestamp=<Unix 秒>, signature=<标准 Base64>
+//
+// 签名内容为 StringToSign 返回的方法、路径、时间戳和请求体 SHA-256 的组合。
+// 时间戳与服务器时间相差超过 Skew 的请求被拒绝，限制截获请求的重放窗口
+type HMACAuth struct {
+	keys map[string]*AuthKey
+	Skew time.Duration
+}
+
+// NewHMACAuth 创建 HMAC 请求签名认证，skew 为 0 时使用 DefaultClockSkew
+func NewHMACAuth(keys []AuthKey, skew time.Duration) *HMACAuth {
+	if skew <= 0 {
+		skew = DefaultClockSkew
+	}
+	a := &HMACAuth{keys: make(map[string]*AuthKey), Skew: skew}
+	for i := range keys {
+		a.keys[keys[i].ID] = &keys[i]
+	}
+	return a
+}
+
+// StringToSign 待签名字符串：
+//
+//	HMAC-SHA256\n<METHOD>\n<path>\n<timestamp>\n<hex(SHA-256(body))>
+func StringToSign(method, path string, timestamp int64, bodyHash []byte) string {
+	return strings.Join([]string{HMACScheme, strings.ToUpper(method), path, strconv.FormatInt(timestamp, 10), hex.EncodeToString(bodyHash)}, "\n")
+}
+
+// SignHMAC 计算请求签名（标准 Base64），供客户端使用
+func SignHMAC(secret []byte, method, path string, timestamp int64, body []byte) string {
+	sum := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(StringToSign(method, path, timestamp, sum[:])))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (a *HMACAuth) readsBody() bool { return true }
+
+// Authenticate 实现 Authenticator。校验签名需要请求体摘要，请求体会被完整读取并缓冲后交给后续处理；
+// 头部格式、时间戳、keyId 和接口权限在读取请求体之前校验
+func (a *HMACAuth) Authenticate(r *http.Request) (*AuthKey, error) {
+	params, ok := strings.CutPrefix(r.Header.Get("Authorization"), HMACScheme+" ")
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	fields := make(map[string]string)
+	for _, item := range strings.Split(params, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok {
+			return nil, ErrMalformedAuthorization
+		}
+		fields[name] = value
+	}
+	timestamp, err := strconv.ParseInt(fields["timestamp"], 10, 64)
+	if err != nil {
+		return nil, ErrMalformedAuthorization
+	}
+	signature, err := base64.StdEncoding.DecodeString(fields["signature"])
+	if err != nil || fields["keyId"] == "" {
+		return nil, ErrMalformedAuthorization
+	}
+
+	if d := time.Since(time.Unix(timestamp, 0)); d > a.Skew || d < -a.Skew {
+		return nil, ErrClockSkew
+	}
+	key, ok := a.keys[fields["keyId"]]
+	if !ok {
+		return nil, ErrInvalidSignature
+	}
+	if !key.Allows(r.URL.Path) {
+		return nil, ErrKeyNotAllowed
+	}
+
+	bodyHash, err := bufferBody(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errReadBody, err)
+	}
+	mac := hmac.New(sha256.New, []byte(key.Secret))
+	mac.Write([]byte(StringToSign(r.Method, r.URL.Path, timestamp, bodyHash)))
+	if subtle.ConstantTimeCompare(mac.Sum(nil), signature) != 1 {
+		return nil, ErrInvalidSignature
+	}
+	return key, nil
+}
+
+// bufferBody 读取请求体并计算 SHA-256，再把缓冲的内容放回 r.Body；
+// 超过 memBodyLimit 的部分写入临时文件，总长度不超过 maxSignedBodySize
+func bufferBody(r *http.Request) ([]byte, error) {
+	r.Body = http.MaxBytesReader(nil, r.Body, maxSignedBodySize)
+	h := sha256.New()
+	var buf bytes.Buffer
+	n, err := io.Copy(io.MultiWriter(h, &buf), io.LimitReader(r.Body, memBodyLimit))
+	if err != nil {
+		return nil, err
+	}
+	if n < memBodyLimit {
+		r.Body = io.NopCloser(&buf)
+		return h.Sum(nil), nil
+	}
+
+	tmp, err := os.CreateTemp("", "auth-body-*")
+	if err != nil {
+		return nil, err
+	}
+	// 创建后立即删除目录项，已打开的文件仍可读写，进程异常退出也不会留下临时文件；
+	// 不支持删除已打开文件的系统（Windows）在关闭时删除
+	body := &tempBody{File: tmp, removed: os.Remove(tmp.Name()) == nil}
+	if _, err = tmp.Write(buf.Bytes()); err == nil {
+		_, err = io.Copy(io.MultiWriter(h, tmp), r.Body)
+	}
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		body.Close()
+		return nil, err
+	}
+	r.Body = body
+	return h.Sum(nil), nil
+}
+
+// tempBody 以临时文件缓冲的请求体
+type tempBody struct {
+	*os.File
+	removed bool // 目录项已删除
+}
+
+func (b *tempBody) Close() error {
+	err := b.File.Close()
+	if !b.removed {
+		b.removed = true
+		return os.Remove(b.Name())
+	}
+	return err
+}
+
+// protected 启用认证时校验请求凭据：缺少或无效返回 401，密钥无权调用该接口返回 403，
+// 签名请求的请求体过大返回 413
+func (s *server) protected(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var auth Authenticator
+		if s.Auth != nil {
+			var ok bool
+			if auth, ok = need(w, r, s.Auth, "Authentication unavailable"); !ok {
+				return
+			}
+		}
+		if auth == nil {
+			next(w, r)
+			return
+		}
+
+		logger := Logger(r.Context())
+		key, err := auth.Authenticate(r)
+		// 服务器只会关闭原始请求体；缓冲的请求体（可能是临时文件）无论认证成败都在这里关闭
+		defer r.Body.Close()
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			logger.Warn("Signed request body too large", "limit", tooLarge.Limit)
+			WriteError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Request body too large: signed requests are limited to %d bytes", tooLarge.Limit))
+			return
+		}
+		if errors.Is(err, errReadBody) {
+			logger.Warn("Request body read failed", "error", err)
+			WriteError(w, http.StatusBadRequest, "Failed to read request body")
+			return
+		}
+		if err == nil && !key.Allows(r.URL.Path) {
+			err = fmt.Errorf("%w: %s", ErrKeyNotAllowed, key.ID)
+		}
+		if errors.Is(err, ErrKeyNotAllowed) {
+			logger.Warn("Key not allowed for endpoint", "error", err, "path", r.URL.Path)
+			WriteError(w, http.StatusForbidden, "Forbidden: key is not allowed to call this endpoint")
+			return
+		}
+		if err != nil {
+			logger.Warn("Authentication failed", "error", err)
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer, %s`, HMACScheme))
+			WriteError(w, http.StatusUnauthorized, fmt.Sprintf("Unauthorized: %v", err))
+			return
+		}
+
+		logger = logger.With("keyId", key.ID)
+		next(w, r.WithContext(context.WithValue(r.Context(), loggerKey{}, logger)))
+	}
+}
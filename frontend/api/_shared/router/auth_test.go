@@ -0,0 +1,213 @@
+package router
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/stream"
+)
+
+func TestParseAuthKeys(t *testing.T) {
+	keys, err := ParseAuthKeys([]byte(` [{"id":"web","secret":"s1"},{"id":"batch","secret":"s2","paths":["/api/process"]}] `))
+	if err != nil || len(keys) != 2 || !keys[1].Allows("/api/process") || keys[1].Allows("/api/session") || !keys[0].Allows("/any") {
+		t.Fatalf("JSON keys: %+v, %v", keys, err)
+	}
+	if keys, err := ParseAuthKeys([]byte("web:s1, batch : s2")); err != nil || len(keys) != 2 || keys[1].ID != "batch" || keys[1].Secret != "s2" {
+		t.Errorf("list keys: %+v, %v", keys, err)
+	}
+	for _, bad := range []string{"", "web", "web:", ":s1", "web:s1,web:s2", `[{"id":"web"}]`, "[{"} {
+		if _, err := ParseAuthKeys([]byte(bad)); err == nil {
+			t.Errorf("ParseAuthKeys(%q): want error", bad)
+		}
+	}
+}
+
+// countingBody 记录请求体是否被读取
+type countingBody struct {
+	io.Reader
+	reads int
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	b.reads++
+	return b.Reader.Read(p)
+}
+
+func (b *countingBody) Close() error { return nil }
+
+func hmacHeader(keyID, secret, method, path string, ts time.Time, body []byte) string {
+	return fmt.Sprintf("%s keyId=%s, timestamp=%d, signature=%s", HMACScheme, keyID, ts.Unix(),
+		SignHMAC([]byte(secret), method, path, ts.Unix(), body))
+}
+
+func newAuthHandler(t *testing.T) http.Handler {
+	t.Helper()
+	keys := []AuthKey{
+		{ID: "web", Secret: "web-secret"},
+		{ID: "batch", Secret: "batch-secret", Paths: []string{"/api/stream/encrypt"}},
+	}
+	// HMAC 放在前面：只看请求头的 API 密钥仍应先于 HMAC 校验
+	auth := Authenticators{NewHMACAuth(keys, time.Minute), NewAPIKeyAuth(keys)}
+	return New(Deps{Auth: Value[Authenticator](auth), Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+}
+
+func serveAuth(h http.Handler, path string, body []byte, header map[string]string) (*httptest.ResponseRecorder, *countingBody) {
+	b := &countingBody{Reader: bytes.NewReader(body)}
+	r := httptest.NewRequest("POST", path, b)
+	for k, v := range header {
+		r.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w, b
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	h := newAuthHandler(t)
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{3}, stream.KeySize))
+
+	for name, tc := range map[string]struct {
+		header map[string]string
+		path   string
+		want   int
+	}{
+		"X-API-Key":        {map[string]string{APIKeyHeader: "web-secret"}, "/api/stream/encrypt", http.StatusOK},
+		"Bearer":           {map[string]string{"Authorization": "Bearer web-secret"}, "/api/stream/encrypt", http.StatusOK},
+		"restricted key":   {map[string]string{APIKeyHeader: "batch-secret"}, "/api/stream/encrypt", http.StatusOK},
+		"path not allowed": {map[string]string{APIKeyHeader: "batch-secret"}, "/api/stream/decrypt", http.StatusForbidden},
+		"invalid key":      {map[string]string{APIKeyHeader: "nope"}, "/api/stream/encrypt", http.StatusUnauthorized},
+		"no credentials":   {nil, "/api/stream/encrypt", http.StatusUnauthorized},
+	} {
+		header := map[string]string{"X-Key": key}
+		for k, v := range tc.header {
+			header[k] = v
+		}
+		w, body := serveAuth(h, tc.path, []byte("data"), header)
+		if w.Code != tc.want {
+			t.Errorf("%s: status %d, want %d: %s", name, w.Code, tc.want, w.Body)
+		}
+		if tc.want != http.StatusOK && body.reads != 0 {
+			t.Errorf("%s: body read before rejection", name)
+		}
+		if tc.want == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%s: missing WWW-Authenticate", name)
+		}
+	}
+
+	// 公钥发布接口不需要认证
+	r := httptest.NewRequest("GET", "/api/rsa/public-key", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code == http.StatusUnauthorized {
+		t.Error("public key endpoint requires authentication")
+	}
+}
+
+func TestHMACAuth(t *testing.T) {
+	h := newAuthHandler(t)
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{3}, stream.KeySize))
+	path := "/api/stream/encrypt"
+	body := []byte("signed body")
+	now := time.Now()
+
+	w, _ := serveAuth(h, path, body, map[string]string{"X-Key": key, "Authorization": hmacHeader("batch", "batch-secret", "POST", path, now, body)})
+	if w.Code != http.StatusOK || w.Body.Len() <= len(body) {
+		t.Fatalf("valid signature: status %d: %s", w.Code, w.Body)
+	}
+
+	for name, tc := range map[string]struct {
+		auth      string
+		path      string
+		want      int
+		readsBody bool
+	}{
+		"tampered body":     {hmacHeader("batch", "batch-secret", "POST", path, now, []byte("other body")), path, http.StatusUnauthorized, true},
+		"wrong secret":      {hmacHeader("batch", "web-secret", "POST", path, now, body), path, http.StatusUnauthorized, true},
+		"signed other path": {hmacHeader("web", "web-secret", "POST", "/api/process", now, body), path, http.StatusUnauthorized, true},
+		"expired":           {hmacHeader("batch", "batch-secret", "POST", path, now.Add(-2*time.Minute), body), path, http.StatusUnauthorized, false},
+		"future":            {hmacHeader("batch", "batch-secret", "POST", path, now.Add(2*time.Minute), body), path, http.StatusUnauthorized, false},
+		"unknown keyId":     {hmacHeader("nobody", "batch-secret", "POST", path, now, body), path, http.StatusUnauthorized, false},
+		"malformed":         {HMACScheme + " keyId=batch, timestamp=soon, signature=AA==", path, http.StatusUnauthorized, false},
+		"bad signature b64": {HMACScheme + fmt.Sprintf(" keyId=batch, timestamp=%d, signature=!!", now.Unix()), path, http.StatusUnauthorized, false},
+		"path not allowed":  {hmacHeader("batch", "batch-secret", "POST", "/api/stream/decrypt", now, body), "/api/stream/decrypt", http.StatusForbidden, false},
+	} {
+		w, b := serveAuth(h, tc.path, body, map[string]string{"X-Key": key, "Authorization": tc.auth})
+		if w.Code != tc.want {
+			t.Errorf("%s: status %d, want %d: %s", name, w.Code, tc.want, w.Body)
+		}
+		if !tc.readsBody && b.reads != 0 {
+			t.Errorf("%s: body read before rejection", name)
+		}
+	}
+
+	// 同时带无效 API 密钥和有效签名：先校验 API 密钥，不读取请求体
+	w, b := serveAuth(h, path, body, map[string]string{"X-Key": key, APIKeyHeader: "nope",
+		"Authorization": hmacHeader("batch", "batch-secret", "POST", path, now, body)})
+	if w.Code != http.StatusUnauthorized || b.reads != 0 {
+		t.Errorf("invalid API key with signature: status %d, body reads %d", w.Code, b.reads)
+	}
+}
+
+// TestHMACBufferedBody 超过内存上限的请求体暂存在临时文件中，认证成败都不会留下文件；超过签名上限返回 413
+func TestHMACBufferedBody(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	h := newAuthHandler(t)
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{3}, stream.KeySize))
+	path := "/api/stream/encrypt"
+	body := bytes.Repeat([]byte("x"), memBodyLimit+1024)
+	now := time.Now()
+
+	w, _ := serveAuth(h, path, body, map[string]string{"X-Key": key, "Authorization": hmacHeader("web", "web-secret", "POST", path, now, body)})
+	if w.Code != http.StatusOK {
+		t.Fatalf("large signed body: status %d: %s", w.Code, w.Body)
+	}
+	w, _ = serveAuth(h, path, body, map[string]string{"X-Key": key, "Authorization": hmacHeader("web", "web-secret", "POST", path, now, body[1:])})
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("large tampered body: status %d", w.Code)
+	}
+	if entries, _ := os.ReadDir(tmp); len(entries) != 0 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+
+	r := httptest.NewRequest("POST", path, io.LimitReader(zeroReader{}, maxSignedBodySize+1))
+	r.Header.Set("Authorization", hmacHeader("web", "web-secret", "POST", path, now, nil))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	if rec.Code != http.StatusRequestEntityTooLarge || !strings.Contains(rec.Body.String(), "too large") {
+		t.Errorf("oversized body: status %d: %s", rec.Code, rec.Body)
+	}
+	if entries, _ := os.ReadDir(tmp); len(entries) != 0 {
+		t.Errorf("temporary files left behind after 413: %v", entries)
+	}
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestAuthenticatorsOrder(t *testing.T) {
+	keys := []AuthKey{{ID: "web", Secret: "web-secret"}}
+	auth := Authenticators{NewHMACAuth(keys, 0), NewAPIKeyAuth(keys)}
+	r := httptest.NewRequest("POST", "/api/process", strings.NewReader("{}"))
+	if _, err := auth.Authenticate(r); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("no credentials: err = %v", err)
+	}
+	r.Header.Set(APIKeyHeader, "web-secret")
+	if key, err := auth.Authenticate(r); err != nil || key.ID != "web" {
+		t.Errorf("API key: %v, %v", key, err)
+	}
+}
@@ -0,0 +1,251 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/respsig"
+)
+
+// 默认允许的请求头与暴露的响应头
+var (
+	DefaultAllowedHeaders = []string{"Content-Type", "Authorization", "X-Session-Id", "X-Key", "X-AAD", APIKeyHeader, RequestIDHeader}
+	DefaultExposedHeaders = []string{RequestIDHeader, FileTypeHeader, respsig.Header}
+)
+
+// DefaultCORSMaxAge 默认预检结果缓存时间
+const DefaultCORSMaxAge = 10 * time.Minute
+
+// CORSConfig 跨域策略配置
+type CORSConfig struct {
+	// AllowedOrigins 允许的来源：精确 origin（https://app.example.com）、子域通配
+	// （https://*.example.com，匹配任意层级子域，不含 example.com 本身）或 *（任意来源）
+	AllowedOrigins []string
+	// AllowedHeaders 预检允许的请求头，为空时使用 DefaultAllowedHeaders
+	AllowedHeaders []string
+	// ExposedHeaders 浏览器脚本可读取的响应头，为空时使用 DefaultExposedHeaders
+	ExposedHeaders []string
+	// AllowCredentials 允许携带 Cookie / HTTP 认证，不能与 * 同时使用
+	AllowCredentials bool
+	// MaxAge 预检结果缓存时间，0 表示不发送 Access-Control-Max-Age
+	MaxAge time.Duration
+}
+
+// DefaultCORSConfig 默认策略：允许任意来源，不携带凭据
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{AllowedOrigins: []string{"*"}, MaxAge: DefaultCORSMaxAge}
+}
+
+// CORSPolicy 校验后的跨域策略
+type CORSPolicy struct {
+	anyOrigin   bool
+	exact       map[string]bool
+	wildcards   []originPattern
+	credentials bool
+
+	allowHeaders  string
+	exposeHeaders string
+	maxAge        string
+}
+
+// originPattern 子域通配：scheme://*.<suffix>[:port]
+type originPattern struct {
+	scheme, suffix, port string
+}
+
+// NewCORSPolicy 校验配置并创建跨域策略
+func NewCORSPolicy(c CORSConfig) (*CORSPolicy, error) {
+	p := &CORSPolicy{exact: make(map[string]bool), credentials: c.AllowCredentials}
+	for _, o := range c.AllowedOrigins {
+		if o == "*" {
+			p.anyOrigin = true
+			continue
+		}
+		u, err := parseOrigin(o)
+		if err != nil {
+			return nil, err
+		}
+		if host, ok := strings.CutPrefix(u.Hostname(), "*."); ok {
+			if host == "" || strings.Contains(host, "*") {
+				return nil, fmt.Errorf("invalid CORS origin %q", o)
+			}
+			p.wildcards = append(p.wildcards, originPattern{scheme: u.Scheme, suffix: "." + host, port: u.Port()})
+			continue
+		}
+		if strings.Contains(u.Host, "*") {
+			return nil, fmt.Errorf("invalid CORS origin %q: wildcard must be the leftmost label", o)
+		}
+		p.exact[u.Scheme+"://"+u.Host] = true
+	}
+	if p.anyOrigin && p.credentials {
+		return nil, errors.New("CORS credentials cannot be allowed for origin *")
+	}
+
+	headers := c.AllowedHeaders
+	if len(headers) == 0 {
+		headers = DefaultAllowedHeaders
+	}
+	p.allowHeaders = strings.Join(headers, ", ")
+	exposed := c.ExposedHeaders
+	if len(exposed) == 0 {
+		exposed = DefaultExposedHeaders
+	}
+	p.exposeHeaders = strings.Join(exposed, ", ")
+	if c.MaxAge < 0 {
+		return nil, fmt.Errorf("invalid CORS max age %v", c.MaxAge)
+	}
+	if c.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(int(c.MaxAge / time.Second))
+	}
+	return p, nil
+}
+
+// parseOrigin 解析 scheme://host[:port] 形式的 origin 并规范化：scheme 和主机名转为小写，
+// 去掉默认端口（http 的 80、https 的 443）和空端口，https://a.com:443 与 https://a.com 视为同一来源
+func parseOrigin(origin string) (*url.URL, error) {
+	u, err := url.Parse(strings.ToLower(origin))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("invalid CORS origin %q", origin)
+	}
+	u.Host = normalizeHost(u.Scheme, u.Host)
+	return u, nil
+}
+
+// defaultPorts 各 scheme 的默认端口
+var defaultPorts = map[string]string{"http": "80", "https": "443"}
+
+// normalizeHost 主机名转为小写并去掉 scheme 的默认端口
+func normalizeHost(scheme, host string) string {
+	host = strings.ToLower(host)
+	host = strings.TrimSuffix(host, ":")
+	if port := defaultPorts[scheme]; port != "" {
+		host = strings.TrimSuffix(host, ":"+port)
+	}
+	return host
+}
+
+// AllowsAnyOrigin 策略是否允许任意来源（*）
+func (p *CORSPolicy) AllowsAnyOrigin() bool {
+	return p.anyOrigin
+}
+
+// AllowOrigin 判断来源是否在允许列表中
+func (p *CORSPolicy) AllowOrigin(origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+	u, err := parseOrigin(origin)
+	if err != nil {
+		return false
+	}
+	if p.exact[u.Scheme+"://"+u.Host] {
+		return true
+	}
+	for _, w := range p.wildcards {
+		host := u.Hostname()
+		if u.Scheme == w.scheme && u.Port() == w.port && len(host) > len(w.suffix) && strings.HasSuffix(host, w.suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware 按策略设置跨域响应头并应答预检请求；来源不在允许列表中的跨域请求返回 403。
+// 不带 Origin 的请求（curl、服务端调用）和同源请求不受限制
+func (p *CORSPolicy) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		preflight := r.Method == "OPTIONS"
+		if origin == "" {
+			if preflight {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h := w.Header()
+		if !p.anyOrigin || p.credentials {
+			h.Add("Vary", "Origin")
+		}
+		if !p.AllowOrigin(origin) && !sameOrigin(r, origin) {
+			Logger(r.Context()).Warn("CORS origin rejected", "origin", origin)
+			WriteError(w, http.StatusForbidden, "Origin not allowed")
+			return
+		}
+
+		if p.anyOrigin && !p.credentials {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if p.credentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if preflight {
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			h.Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+			h.Set("Access-Control-Allow-Headers", p.allowHeaders)
+			if p.maxAge != "" {
+				h.Set("Access-Control-Max-Age", p.maxAge)
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		h.Add("Access-Control-Expose-Headers", p.exposeHeaders)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// sameOrigin 浏览器对同源 POST 也会带 Origin，与请求自身的来源一致时视为同源放行。
+//
+// 部署在反向代理（Vercel、Nginx 等）之后时 r.Host 可能是内部地址，因此优先使用代理设置的
+// X-Forwarded-Host / X-Forwarded-Proto（多级代理时取第一个，即最靠近客户端的值）。
+// 浏览器跨域发送这两个头需要先通过预检，而预检请求不带头部的值，伪造它们无法绕过跨域检查。
+// 协议未知（没有 TLS 也没有 X-Forwarded-Proto）时只比较主机和端口
+func sameOrigin(r *http.Request, origin string) bool {
+	u, err := parseOrigin(origin)
+	if err != nil {
+		return false
+	}
+
+	scheme := firstForwarded(r.Header.Get("X-Forwarded-Proto"))
+	if scheme == "" && r.TLS != nil {
+		scheme = "https"
+	}
+	if scheme != "" && !strings.EqualFold(scheme, u.Scheme) {
+		return false
+	}
+	host := firstForwarded(r.Header.Get("X-Forwarded-Host"))
+	if host == "" {
+		host = r.Host
+	}
+	return normalizeHost(u.Scheme, host) == u.Host
+}
+
+// firstForwarded 逗号分隔的转发头中的第一个值
+func firstForwarded(v string) string {
+	first, _, _ := strings.Cut(v, ",")
+	return strings.TrimSpace(first)
+}
+
+// SplitList 解析逗号分隔的配置列表，忽略空项
+func SplitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,142 @@
+package router
+
+import (
+	"crypto/tls"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewCORSPolicyRejects(t *testing.T) {
+	for name, c := range map[string]CORSConfig{
+		"scheme":            {AllowedOrigins: []string{"ftp://example.com"}},
+		"path":              {AllowedOrigins: []string{"https://example.com/app"}},
+		"userinfo":          {AllowedOrigins: []string{"https://user@example.com"}},
+		"bare wildcard":     {AllowedOrigins: []string{"https://*."}},
+		"inner wildcard":    {AllowedOrigins: []string{"https://app.*.example.com"}},
+		"credentials and *": {AllowedOrigins: []string{"*"}, AllowCredentials: true},
+		"negative max age":  {AllowedOrigins: []string{"*"}, MaxAge: -time.Second},
+	} {
+		if _, err := NewCORSPolicy(c); err == nil {
+			t.Errorf("%s: want error", name)
+		}
+	}
+}
+
+func TestAllowOrigin(t *testing.T) {
+	p, err := NewCORSPolicy(CORSConfig{AllowedOrigins: []string{
+		"https://App.Example.com:443",
+		"http://localhost:5173",
+		"https://*.example.org",
+		"http://*.dev.test:80",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.AllowsAnyOrigin() {
+		t.Error("AllowsAnyOrigin() = true for an allow-list")
+	}
+	for origin, want := range map[string]bool{
+		"https://app.example.com":      true,
+		"https://APP.EXAMPLE.COM":      true,
+		"https://app.example.com:443":  true,
+		"https://app.example.com:":     true,
+		"http://app.example.com":       false, // 协议不同
+		"https://app.example.com:8443": false,
+		"http://localhost:5173":        true,
+		"http://localhost":             false,
+		"https://a.example.org":        true,
+		"https://a.b.example.org:443":  true,
+		"https://example.org":          false, // 子域通配不含裸域
+		"https://evilexample.org":      false,
+		"http://a.example.org":         false,
+		"https://a.example.org:8443":   false,
+		"http://x.dev.test":            true,
+		"null":                         false,
+		"https://app.example.com/path": false,
+	} {
+		if got := p.AllowOrigin(origin); got != want {
+			t.Errorf("AllowOrigin(%q) = %v, want %v", origin, got, want)
+		}
+	}
+}
+
+func corsRequest(p *CORSPolicy, method, origin string, setup func(*http.Request)) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, "http://api.example.com/api/process", nil)
+	if origin != "" {
+		r.Header.Set("Origin", origin)
+	}
+	if setup != nil {
+		setup(r)
+	}
+	w := httptest.NewRecorder()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	RequestID(logger, p.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))).ServeHTTP(w, r)
+	return w
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	p, _ := NewCORSPolicy(CORSConfig{AllowedOrigins: []string{"https://app.example.com"}, AllowCredentials: true, MaxAge: time.Minute})
+
+	w := corsRequest(p, "OPTIONS", "https://app.example.com", nil)
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+		w.Header().Get("Access-Control-Allow-Credentials") != "true" || w.Header().Get("Access-Control-Max-Age") != "60" {
+		t.Errorf("preflight: %d %v", w.Code, w.Header())
+	}
+	w = corsRequest(p, "POST", "https://app.example.com", nil)
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Expose-Headers") == "" || w.Header().Values("Vary")[0] != "Origin" {
+		t.Errorf("allowed request: %d %v", w.Code, w.Header())
+	}
+	for _, method := range []string{"OPTIONS", "POST"} {
+		if w := corsRequest(p, method, "https://evil.example", nil); w.Code != http.StatusForbidden || w.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Errorf("%s from disallowed origin: %d %v", method, w.Code, w.Header())
+		}
+	}
+	if w := corsRequest(p, "POST", "", nil); w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("no origin: %d %v", w.Code, w.Header())
+	}
+
+	anyPolicy, _ := NewCORSPolicy(DefaultCORSConfig())
+	if !anyPolicy.AllowsAnyOrigin() {
+		t.Error("AllowsAnyOrigin() = false for the default policy")
+	}
+	if w := corsRequest(anyPolicy, "POST", "https://anywhere.test", nil); w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Errorf("any origin: %d %v", w.Code, w.Header())
+	}
+}
+
+func TestSameOrigin(t *testing.T) {
+	p, _ := NewCORSPolicy(CORSConfig{})
+	withTLS := func(r *http.Request) { r.TLS = &tls.ConnectionState{} }
+	forwarded := func(proto, host string) func(*http.Request) {
+		return func(r *http.Request) {
+			r.Host = "10.0.0.5:8080"
+			r.Header.Set("X-Forwarded-Proto", proto)
+			r.Header.Set("X-Forwarded-Host", host)
+		}
+	}
+	for name, tc := range map[string]struct {
+		origin string
+		setup  func(*http.Request)
+		want   int
+	}{
+		"host":                   {"http://api.example.com", nil, http.StatusOK},
+		"host with default port": {"http://API.example.com:80", nil, http.StatusOK},
+		"other host":             {"http://app.example.com", nil, http.StatusForbidden},
+		"behind proxy":           {"https://api.example.com", forwarded("https", "api.example.com"), http.StatusOK},
+		"proxy chain":            {"https://api.example.com", forwarded("https, http", "api.example.com:443, 10.0.0.1"), http.StatusOK},
+		"proxy scheme mismatch":  {"http://api.example.com", forwarded("https", "api.example.com"), http.StatusForbidden},
+		"internal host":          {"http://10.0.0.5:8080", forwarded("https", "api.example.com"), http.StatusForbidden},
+		"tls":                    {"https://api.example.com", withTLS, http.StatusOK},
+		"tls scheme mismatch":    {"http://api.example.com", withTLS, http.StatusForbidden},
+	} {
+		if w := corsRequest(p, "POST", tc.origin, tc.setup); w.Code != tc.want {
+			t.Errorf("%s: status %d, want %d", name, w.Code, tc.want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package router
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/fileenc"
+)
+
+// 文件加解密接口的上传限制：超过 multipartMemory 的部分暂存到临时文件
+const (
+	maxUploadSize   = 1 << 30
+	multipartMemory = 8 << 20
+)
+
+// FileTypeHeader 解密接口返回原文件 MIME 类型的响应头；响应体始终以 application/octet-stream 下发
+const FileTypeHeader = "X-File-Type"
+
+// fileEncrypt 文件加密接口：multipart/form-data 上传 file 字段及 key 或 sessionId（可选 aad），
+// 返回加密文件下载，头部记录文件名、MIME 类型和原始大小
+func (s *server) fileEncrypt(w http.ResponseWriter, r *http.Request) {
+	logger := Logger(r.Context())
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	if err := r.ParseMultipartForm(multipartMemory); err != nil {
+		logger.Warn("Multipart parse error", "error", err)
+		WriteError(w, http.StatusBadRequest, "Invalid multipart form")
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, fileHeader, err := r.FormFile("file")
+	if err != nil {
+		logger.Warn("Missing file", "error", err)
+		WriteError(w, http.StatusBadRequest, "File is required")
+		return
+	}
+	defer file.Close()
+
+	key, ok := s.streamKey(w, r, r.FormValue("sessionId"), r.FormValue("key"))
+	if !ok {
+		return
+	}
+
+	contentType := fileHeader.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	meta := fileenc.Metadata{Name: fileHeader.Filename, Type: contentType, Size: fileHeader.Size}
+	// 文件名可能含个人信息，日志只记录长度
+	logger.Info("Encrypting file", "nameLen", len(meta.Name), "type", meta.Type, "size", meta.Size)
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	// FormatMediaType 无法编码时返回空串，与 fileDecrypt 一样退回固定文件名
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": fileHeader.Filename + ".enc"})
+	if disposition == "" {
+		disposition = `attachment; filename="download.enc"`
+	}
+	w.Header().Set("Content-Disposition", disposition)
+	declareStatusTrailer(w)
+	fw, err := fileenc.NewWriter(w, key, []byte(r.FormValue("aad")), meta)
+	if err != nil {
+		logger.Error("File encryption init failed", "error", err)
+		WriteError(w, http.StatusInternalServerError, "File encryption failed")
+		return
+	}
+
+	n, err := io.Copy(fw, file)
+	if err == nil {
+		err = fw.Close()
+	}
+	if err != nil {
+		// 响应已开始，只能停止写出并中断连接
+		logger.Warn("File encryption failed", "bytes", n, "error", err)
+		if err := abortResponse(w); err != nil {
+			logger.Warn("Connection not closed after file encryption failure", "error", err)
+		}
+		return
+	}
+
+	finishResponse(w)
+	logger.Info("File encryption successful", "bytes", n)
+}
+
+// fileDecrypt 文件解密接口：multipart/form-data 上传加密文件及 key 或 sessionId（可选 aad），
+// 全部分段的 GCM 标签和原始大小校验通过后才返回原文件
+func (s *server) fileDecrypt(w http.ResponseWriter, r *http.Request) {
+	logger := Logger(r.Context())
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	if err := r.ParseMultipartForm(multipartMemory); err != nil {
+		logger.Warn("Multipart parse error", "error", err)
+		WriteError(w, http.StatusBadRequest, "Invalid multipart form")
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		logger.Warn("Missing file", "error", err)
+		WriteError(w, http.StatusBadRequest, "File is required")
+		return
+	}
+	defer file.Close()
+
+	key, ok := s.streamKey(w, r, r.FormValue("sessionId"), r.FormValue("key"))
+	if !ok {
+		return
+	}
+
+	meta, fr, err := fileenc.NewReader(file, key, []byte(r.FormValue("aad")))
+	if err != nil {
+		logger.Warn("Invalid encrypted file", "error", err)
+		WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid encrypted file: %v", err))
+		return
+	}
+
+	// 先解密到临时文件，整个文件认证通过后再开始响应，避免输出未经验证的内容
+	tmp, err := os.CreateTemp("", "aes-demo-file-*")
+	if err != nil {
+		logger.Error("Temp file creation failed", "error", err)
+		WriteError(w, http.StatusInternalServerError, "File decryption failed")
+		return
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err := io.Copy(tmp, fr); err != nil {
+		logger.Warn("File decryption failed", "error", err)
+		WriteError(w, http.StatusBadRequest, fmt.Sprintf("Decryption failed: %v", err))
+		return
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		logger.Error("Temp file seek failed", "error", err)
+		WriteError(w, http.StatusInternalServerError, "File decryption failed")
+		return
+	}
+
+	// 头部中的文件名和类型来自上传方：只取文件名部分；类型不作为 Content-Type 使用，
+	// 避免上传方借 text/html 等类型让浏览器渲染文件内容，校验后通过 X-File-Type 单独返回
+	name := meta.Name[strings.LastIndexAny(meta.Name, `/\`)+1:]
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": name})
+	if name == "" || disposition == "" {
+		disposition = `attachment; filename="download"`
+	}
+	fileType := meta.Type
+	if _, _, err := mime.ParseMediaType(fileType); err != nil {
+		fileType = "application/octet-stream"
+	}
+	logger.Info("File decryption successful", "nameLen", len(name), "type", fileType, "size", meta.Size)
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set(FileTypeHeader, fileType)
+	w.Header().Set("Content-Disposition", disposition)
+	w.Header().Set("Content-Length", strconv.FormatInt(meta.Size, 10))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	io.Copy(w, tmp)
+}
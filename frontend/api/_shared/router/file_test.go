@@ -0,0 +1,106 @@
+package router
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/stream"
+)
+
+func postFile(t *testing.T, url, key, name, contentType string, data []byte) (*http.Response, []byte) {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("key", key)
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Disposition", `form-data; name="file"; filename="`+name+`"`)
+	h.Set("Content-Type", contentType)
+	part, _ := mw.CreatePart(h)
+	part.Write(data)
+	mw.Close()
+
+	resp, err := http.Post(url, mw.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	got, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, got
+}
+
+func TestFileRoundTrip(t *testing.T) {
+	srv := newTestServer(t, Deps{})
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{5}, stream.KeySize))
+	page := []byte("<script>alert(1)</script>")
+
+	resp, sealed := postFile(t, srv.URL+"/api/file/encrypt", key, "page.html", "text/html", page)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("encrypt: status %d: %s", resp.StatusCode, sealed)
+	}
+	resp, got := postFile(t, srv.URL+"/api/file/decrypt", key, "page.html.enc", "application/octet-stream", sealed)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("decrypt: status %d: %s", resp.StatusCode, got)
+	}
+	if !bytes.Equal(got, page) {
+		t.Fatalf("round trip mismatch: %q", got)
+	}
+
+	// 上传方声明的类型只通过 X-File-Type 返回，不能让浏览器把内容当作 HTML 渲染
+	if ct := resp.Header.Get("Content-Type"); ct != "application/octet-stream" {
+		t.Errorf("Content-Type = %q, want application/octet-stream", ct)
+	}
+	if ft := resp.Header.Get(FileTypeHeader); ft != "text/html" {
+		t.Errorf("%s = %q, want text/html", FileTypeHeader, ft)
+	}
+	if cd := resp.Header.Get("Content-Disposition"); cd != `attachment; filename=page.html` {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+
+	sealed[len(sealed)-1] ^= 1
+	resp, _ = postFile(t, srv.URL+"/api/file/decrypt", key, "page.html.enc", "application/octet-stream", sealed)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("tampered: status %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestFileEncryptControlCharName(t *testing.T) {
+	srv := newTestServer(t, Deps{})
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{6}, stream.KeySize))
+
+	// RFC 2231 编码的文件名可以携带控制字符
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("key", key)
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Disposition", `form-data; name="file"; filename*=UTF-8''a%01b.txt`)
+	part, _ := mw.CreatePart(h)
+	part.Write([]byte("data"))
+	mw.Close()
+
+	resp, err := http.Post(srv.URL+"/api/file/encrypt", mw.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("encrypt: status %d", resp.StatusCode)
+	}
+	cd := resp.Header.Get("Content-Disposition")
+	if _, params, err := mime.ParseMediaType(cd); err != nil || params["filename"] == "" {
+		t.Fatalf("Content-Disposition = %q, want an attachment with a filename", cd)
+	}
+	if strings.ContainsFunc(cd, unicode.IsControl) {
+		t.Errorf("Content-Disposition %q contains a raw control character", cd)
+	}
+}
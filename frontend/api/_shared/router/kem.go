@@ -0,0 +1,119 @@
+package router
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/hpke"
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/xwing"
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/logging"
+)
+
+type KEMHybridRequest struct {
+	xwing.Payload
+	AAD string `json:"aad,omitempty"`
+}
+
+// kemPublicKey X-Wing 封装公钥接口
+func (s *server) kemPublicKey(w http.ResponseWriter, r *http.Request) {
+	key, ok := need(w, r, s.XWingKey, "KEM key unavailable")
+	if !ok {
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]string{
+		"alg":       xwing.Algorithm,
+		"publicKey": base64.StdEncoding.EncodeToString(key.PublicKey()),
+		"kid":       key.KeyID(),
+	})
+}
+
+// kemHybrid X-Wing 封装密钥 + AES-GCM 加密正文的混合解密接口，与 /api/rsa/hybrid 对应
+func (s *server) kemHybrid(w http.ResponseWriter, r *http.Request) {
+	logger := Logger(r.Context())
+
+	var req KEMHybridRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Warn("JSON decode error", "error", err)
+		WriteError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if req.EncapsulatedKey == "" || req.IV == "" || req.EncryptedData == "" {
+		logger.Warn("Empty encapsulated key, IV or data provided")
+		WriteError(w, http.StatusBadRequest, "Encapsulated key, IV and data are required")
+		return
+	}
+
+	key, ok := need(w, r, s.XWingKey, "KEM key unavailable")
+	if !ok {
+		return
+	}
+
+	decrypted, err := key.Decrypt(&req.Payload, []byte(req.AAD))
+	if err != nil {
+		logger.Warn("KEM hybrid decryption failed", "error", err)
+		WriteError(w, http.StatusBadRequest, fmt.Sprintf("Hybrid decryption failed: %v", err))
+		return
+	}
+
+	logger.Info("KEM hybrid decryption successful", "plaintext", logging.Plaintext(decrypted))
+
+	WriteJSON(w, http.StatusOK, map[string]string{
+		"decryptedData": string(decrypted),
+	})
+}
+
+// hpkePublicKey HPKE 接收方公钥接口
+func (s *server) hpkePublicKey(w http.ResponseWriter, r *http.Request) {
+	key, ok := need(w, r, s.HPKEKey, "HPKE key unavailable")
+	if !ok {
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"kem":       hpke.DHKEMX25519HKDFSHA256,
+		"kdf":       hpke.HKDFSHA256,
+		"aeads":     []hpke.AEAD{hpke.AES128GCM, hpke.AES256GCM, hpke.ChaCha20Poly1305},
+		"publicKey": base64.StdEncoding.EncodeToString(key.PublicKey().Bytes()),
+		"kid":       hpke.KeyID(key.PublicKey()),
+	})
+}
+
+// hpkeOpen HPKE 单次消息解密接口（base 模式，或带 senderPublicKey 的 auth 模式）
+func (s *server) hpkeOpen(w http.ResponseWriter, r *http.Request) {
+	logger := Logger(r.Context())
+
+	var req hpke.Message
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Warn("JSON decode error", "error", err)
+		WriteError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if req.Enc == "" || req.Ciphertext == "" {
+		WriteError(w, http.StatusBadRequest, "enc and ciphertext are required")
+		return
+	}
+
+	key, ok := need(w, r, s.HPKEKey, "HPKE key unavailable")
+	if !ok {
+		return
+	}
+
+	plainText, err := req.Open(key)
+	if err != nil {
+		logger.Warn("HPKE open failed", "error", err)
+		WriteError(w, http.StatusBadRequest, fmt.Sprintf("HPKE open failed: %v", err))
+		return
+	}
+
+	logger.Info("HPKE open successful", "mode", req.Mode(), "plaintext", logging.Plaintext(plainText))
+
+	WriteJSON(w, http.StatusOK, map[string]string{
+		"mode":      req.Mode(),
+		"plaintext": base64.StdEncoding.EncodeToString(plainText),
+	})
+}
@@ -0,0 +1,85 @@
+package router
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// RequestIDHeader 请求 id 头：客户端提供合法值时沿用，否则由服务端生成，并在响应中返回
+const RequestIDHeader = "X-Request-Id"
+
+// ErrorResponse 错误响应结构体
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// WriteJSON 以 status 输出 JSON 响应
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// WriteError 以 status 输出 ErrorResponse
+func WriteError(w http.ResponseWriter, status int, msg string) {
+	WriteJSON(w, status, ErrorResponse{Error: msg})
+}
+
+// Allow 方法守卫：只放行 methods 中的请求方法，其余返回 405
+func Allow(next http.HandlerFunc, methods ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		for _, m := range methods {
+			if r.Method == m {
+				next(w, r)
+				return
+			}
+		}
+		w.Header().Set("Allow", strings.Join(methods, ", "))
+		WriteError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+type loggerKey struct{}
+
+// RequestID 为每个请求分配 id，写入响应头，并把带 requestId 字段的日志器放入请求上下文
+func RequestID(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			b := make([]byte, 8)
+			rand.Read(b)
+			id = hex.EncodeToString(b)
+		}
+		w.Header().Set(RequestIDHeader, id)
+
+		l := logger.With("requestId", id)
+		l.Debug("Request", "method", r.Method, "path", r.URL.Path)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), loggerKey{}, l)))
+	})
+}
+
+// validRequestID 客户端提供的请求 id 只接受 1～64 个字母、数字、'-'、'_'、'.'，防止日志注入
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// Logger 请求上下文中的日志器，不在 RequestID 中间件内时返回 slog 默认日志器
+func Logger(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
@@ -0,0 +1,81 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/logging"
+	"github.com/LeeeeeeM/aes-go-js/pkg/jsaes"
+)
+
+type ProcessRequest struct {
+	EncryptedData string `json:"encryptedData"` // v2 信封或旧版 cipherB64|ivB64[|kdfParams]
+	Key           string `json:"key,omitempty"`
+	SessionID     string `json:"sessionId,omitempty"` // 握手得到的会话 id，提供时忽略 key
+	AAD           string `json:"aad,omitempty"`       // 附加认证数据（如用户 id、租户），加解密两端必须一致
+}
+
+type ProcessResponse struct {
+	ProcessedData string `json:"processedData"` // v2 信封或旧版 cipherB64|ivB64[|kdfParams]
+}
+
+// process 处理接口：接收加密内容和密钥，解密后重新加密返回
+func (s *server) process(w http.ResponseWriter, r *http.Request) {
+	logger := Logger(r.Context())
+
+	var req ProcessRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Warn("JSON decode error", "error", err)
+		WriteError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	// 解析加密数据：v2 信封，或迁移期内的旧版 cipherB64|ivB64[|kdfParams]
+	env, err := jsaes.ParseEnvelope(req.EncryptedData)
+	if err != nil {
+		logger.Warn("Invalid encrypted data format", "error", err)
+		WriteError(w, http.StatusBadRequest, "Invalid encrypted data format")
+		return
+	}
+
+	logger.Info("Received request", "version", env.Version, "alg", env.Algorithm, "kid", env.KeyID,
+		"key", logging.Secret(req.Key), "session", req.SessionID != "")
+
+	// 优先使用握手得到的会话密钥，对称密钥不必以明文出现在请求中
+	key, ok := s.sessionKey(w, r, req.SessionID, []byte(req.Key))
+	if !ok {
+		return
+	}
+
+	if len(env.Ciphertext) == 0 || len(env.Nonce) == 0 {
+		logger.Warn("Empty cipher or IV provided")
+		WriteError(w, http.StatusBadRequest, "Cipher and IV are required")
+		return
+	}
+
+	// 解密接收到的加密内容
+	logger.Debug("Starting GCM decryption", "ciphertextLen", len(env.Ciphertext), "aadLen", len(req.AAD))
+	decrypted, err := env.Open(key, []byte(req.AAD))
+	if err != nil {
+		logger.Warn("Decryption failed", "error", err)
+		WriteError(w, http.StatusBadRequest, fmt.Sprintf("Decryption failed: %v", err))
+		return
+	}
+
+	logger.Info("Decryption successful", "plaintext", logging.Plaintext(decrypted))
+
+	// 重新加密解密后的内容（沿用请求的信封格式、KDF 成本参数与 AAD，盐和 IV 重新生成）
+	reEnv, err := env.Reseal(decrypted, key, []byte(req.AAD))
+	if err != nil {
+		logger.Error("Re-encryption failed", "error", err)
+		WriteError(w, http.StatusInternalServerError, "Re-encryption failed")
+		return
+	}
+
+	logger.Debug("Re-encryption successful")
+
+	WriteJSON(w, http.StatusOK, ProcessResponse{
+		ProcessedData: reEnv.String(),
+	})
+}
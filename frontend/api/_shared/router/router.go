@@ -0,0 +1,121 @@
+// Package router 是 standalone backend 与 Vercel 函数共用的 HTTP 路由树。
+//
+// backend 用 New 得到的 http.Handler 直接 ListenAndServe；Vercel 的每个函数入口只是把请求转交给
+// 同一个 Handler，两种部署的接口行为因此不会走样。公共的 CORS 策略、方法守卫、JSON 错误输出和
+// 请求 id 由中间件统一处理。
+package router
+
+import (
+	"crypto/ecdh"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/jws"
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/respsig"
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/rsakey"
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/xwing"
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/session"
+)
+
+// errNotConfigured 依赖未提供
+var errNotConfigured = errors.New("not configured")
+
+// Deps 路由依赖。各项按需获取：Vercel 上密钥从环境变量惰性初始化，某项未配置只影响用到它的接口；
+// backend 启动时已加载全部密钥，用 Value 包装即可
+type Deps struct {
+	RSAKeyring     func() (*rsakey.Keyring, error)
+	SigningKeyring func() (*jws.Keyring, error)
+	XWingKey       func() (*xwing.PrivateKey, error)
+	HPKEKey        func() (*ecdh.PrivateKey, error)
+	SessionStore   func() (session.Store, error)
+	// ResponseSigner 返回 nil 签名器（或本字段为 nil）表示不签名
+	ResponseSigner func() (*respsig.Signer, error)
+	// Auth 请求认证，返回 nil（或本字段为 nil）表示不认证；公钥发布接口始终公开
+	Auth func() (Authenticator, error)
+	// CORS 跨域策略，为 nil 时使用 DefaultCORSConfig
+	CORS *CORSPolicy
+	// Logger 为 nil 时使用 slog 默认日志器
+	Logger *slog.Logger
+}
+
+// Value 把已加载的依赖包装为 Deps 字段
+func Value[T any](v T) func() (T, error) {
+	return func() (T, error) { return v, nil }
+}
+
+type server struct {
+	Deps
+}
+
+// New 创建挂载全部接口的 http.Handler
+func New(deps Deps) http.Handler {
+	if deps.Logger == nil {
+		deps.Logger = slog.Default()
+	}
+	if deps.CORS == nil {
+		deps.CORS, _ = NewCORSPolicy(DefaultCORSConfig())
+	}
+	s := &server{Deps: deps}
+
+	// 公钥发布接口公开，其余接口启用认证时经 protected 校验凭据
+	mux := http.NewServeMux()
+	// RSA
+	mux.HandleFunc("/api/rsa/public-key", Allow(s.rsaPublicKey, "GET"))
+	mux.HandleFunc("/.well-known/jwks.json", Allow(s.jwks, "GET"))
+	mux.HandleFunc("/api/jwks", Allow(s.jwks, "GET")) // Vercel 经 rewrite 调用的函数路径
+	mux.HandleFunc("/api/rsa/process", Allow(s.signed(s.protected(s.rsaProcess)), "POST"))
+	mux.HandleFunc("/api/rsa/hybrid", Allow(s.protected(s.rsaHybrid), "POST"))
+	// 后量子 KEM 与 HPKE
+	mux.HandleFunc("/api/kem/public-key", Allow(s.kemPublicKey, "GET"))
+	mux.HandleFunc("/api/kem/hybrid", Allow(s.protected(s.kemHybrid), "POST"))
+	mux.HandleFunc("/api/hpke/public-key", Allow(s.hpkePublicKey, "GET"))
+	mux.HandleFunc("/api/hpke/open", Allow(s.protected(s.hpkeOpen), "POST"))
+	// 签名
+	mux.HandleFunc("/api/signing-key", Allow(s.signingKey, "GET"))
+	mux.HandleFunc("/api/sign", Allow(s.protected(s.sign), "GET", "POST"))
+	mux.HandleFunc("/api/verify", Allow(s.protected(s.verify), "POST"))
+	// 会话与对称加密
+	mux.HandleFunc("/api/session", Allow(s.protected(s.session), "POST"))
+	mux.HandleFunc("/api/ecdh", Allow(s.protected(s.ecdh), "POST"))
+	mux.HandleFunc("/api/process", Allow(s.signed(s.protected(s.process)), "POST"))
+	mux.HandleFunc("/api/stream/encrypt", Allow(s.protected(s.streamEncrypt), "POST"))
+	mux.HandleFunc("/api/stream/decrypt", Allow(s.protected(s.streamDecrypt), "POST"))
+	// 文件接口依赖临时文件和大请求体，Vercel 不提供对应的函数入口
+	mux.HandleFunc("/api/file/encrypt", Allow(s.protected(s.fileEncrypt), "POST"))
+	mux.HandleFunc("/api/file/decrypt", Allow(s.protected(s.fileDecrypt), "POST"))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		WriteError(w, http.StatusNotFound, "Not found")
+	})
+
+	return RequestID(deps.Logger, deps.CORS.Middleware(mux))
+}
+
+// need 获取依赖；失败时记录日志并输出 500，msg 为返回给客户端的错误信息
+func need[T any](w http.ResponseWriter, r *http.Request, get func() (T, error), msg string) (T, bool) {
+	var v T
+	err := errNotConfigured
+	if get != nil {
+		v, err = get()
+	}
+	if err != nil {
+		Logger(r.Context()).Error(msg, "error", err)
+		WriteError(w, http.StatusInternalServerError, msg)
+		return v, false
+	}
+	return v, true
+}
+
+// signed 启用响应签名时为 next 的响应签名（X-Signature 响应头）
+func (s *server) signed(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var signer *respsig.Signer
+		if s.ResponseSigner != nil {
+			var ok bool
+			if signer, ok = need(w, r, s.ResponseSigner, "Response signing unavailable"); !ok {
+				return
+			}
+		}
+		signer.Middleware(Logger(r.Context()), next)(w, r)
+	}
+}
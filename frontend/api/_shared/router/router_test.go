@@ -0,0 +1,166 @@
+package router
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/respsig"
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/x25519"
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/session"
+	"github.com/LeeeeeeM/aes-go-js/pkg/jsaes"
+)
+
+func TestMethodGuardAndNotFound(t *testing.T) {
+	srv := newTestServer(t, Deps{})
+
+	resp, err := http.Get(srv.URL + "/api/process")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed || resp.Header.Get("Allow") != "POST" {
+		t.Errorf("GET /api/process: status %d, Allow %q", resp.StatusCode, resp.Header.Get("Allow"))
+	}
+
+	var e ErrorResponse
+	if resp := postJSON(t, srv.URL+"/api/nope", "{}", &e); resp.StatusCode != http.StatusNotFound || e.Error != "Not found" {
+		t.Errorf("unknown path: status %d, error %q", resp.StatusCode, e.Error)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("405 Content-Type = %q", ct)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	srv := newTestServer(t, Deps{})
+
+	for id, keep := range map[string]bool{
+		"client-id_1.2":           true,
+		"":                        false,
+		"spoofed requestId=admin": false,
+		strings.Repeat("a", 65):   false,
+	} {
+		req, _ := http.NewRequest("POST", srv.URL+"/api/process", strings.NewReader("{"))
+		req.Header.Set(RequestIDHeader, id)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		got := resp.Header.Get(RequestIDHeader)
+		if keep && got != id || !keep && (got == id || !validRequestID(got)) {
+			t.Errorf("request id %q: response %q", id, got)
+		}
+	}
+}
+
+func TestMissingDependency(t *testing.T) {
+	srv := newTestServer(t, Deps{
+		SessionStore: func() (session.Store, error) { return nil, errors.New("no secret") },
+	})
+	var e ErrorResponse
+	if resp := postJSON(t, srv.URL+"/api/rsa/process", RSAProcessRequest{EncryptedData: "AAAA"}, &e); resp.StatusCode != http.StatusInternalServerError || e.Error != "RSA key generation failed" {
+		t.Errorf("unconfigured keyring: status %d, error %q", resp.StatusCode, e.Error)
+	}
+	if resp := postJSON(t, srv.URL+"/api/session", SessionRequest{EncryptedKey: "AAAA"}, &e); resp.StatusCode != http.StatusInternalServerError || e.Error != "Session store unavailable" {
+		t.Errorf("failing session store: status %d, error %q", resp.StatusCode, e.Error)
+	}
+}
+
+func TestProcessRoundTrip(t *testing.T) {
+	srv := newTestServer(t, Deps{})
+	password, aad := []byte("correct horse"), "user-42"
+	env, err := jsaes.SealEnvelope([]byte("hello"), password, []byte(aad), nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out ProcessResponse
+	resp := postJSON(t, srv.URL+"/api/process", ProcessRequest{EncryptedData: env.String(), Key: string(password), AAD: aad}, &out)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	reEnv, err := jsaes.ParseEnvelope(out.ProcessedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := reEnv.Open(password, []byte(aad)); err != nil || string(got) != "hello" {
+		t.Errorf("re-encrypted data: %q, %v", got, err)
+	}
+	if out.ProcessedData == env.String() {
+		t.Error("re-encryption reused the salt and nonce")
+	}
+
+	for name, req := range map[string]ProcessRequest{
+		"wrong aad":      {EncryptedData: env.String(), Key: string(password), AAD: "user-43"},
+		"wrong key":      {EncryptedData: env.String(), Key: "wrong", AAD: aad},
+		"tampered":       {EncryptedData: env.String()[:len(env.String())-2] + "AA", Key: string(password), AAD: aad},
+		"missing key":    {EncryptedData: env.String(), AAD: aad},
+		"invalid format": {EncryptedData: "not an envelope", Key: string(password)},
+	} {
+		var e ErrorResponse
+		if resp := postJSON(t, srv.URL+"/api/process", req, &e); resp.StatusCode != http.StatusBadRequest || e.Error == "" {
+			t.Errorf("%s: status %d, error %q", name, resp.StatusCode, e.Error)
+		}
+	}
+}
+
+// TestECDHSession X25519 握手建立会话后，/api/process 只凭会话 id 解密；响应签名可被验证
+func TestECDHSession(t *testing.T) {
+	signingKey, err := respsig.GenerateKey(respsig.ModeEd25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := respsig.NewSigner(signingKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newTestServer(t, Deps{
+		SessionStore:   Value[session.Store](session.NewMemoryStore(time.Minute, 10)),
+		ResponseSigner: Value(signer),
+	})
+
+	client, _ := x25519.NewClient()
+	var hs ECDHResponse
+	if resp := postJSON(t, srv.URL+"/api/ecdh", ECDHRequest{PublicKey: base64.StdEncoding.EncodeToString(client.PublicKey())}, &hs); resp.StatusCode != http.StatusOK {
+		t.Fatalf("ecdh: status %d", resp.StatusCode)
+	}
+	serverPublic, _ := base64.StdEncoding.DecodeString(hs.PublicKey)
+	key, err := client.Finish(serverPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env, _ := jsaes.SealEnvelope([]byte("over ecdh"), key, nil, nil, "")
+	body, _ := json.Marshal(ProcessRequest{EncryptedData: env.String(), SessionID: hs.SessionID})
+	resp, err := http.Post(srv.URL+"/api/process", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks, _ := json.Marshal(map[string][]respsig.JWK{"keys": {signer.PublicJWK()}})
+	keys, _ := respsig.ParseKeySet(jwks)
+	data, err := keys.VerifyResponse(resp, time.Minute)
+	if err != nil {
+		t.Fatalf("VerifyResponse: %v", err)
+	}
+	var out ProcessResponse
+	json.Unmarshal(data, &out)
+	reEnv, _ := jsaes.ParseEnvelope(out.ProcessedData)
+	if got, err := reEnv.Open(key, nil); err != nil || string(got) != "over ecdh" {
+		t.Errorf("session round trip: %q, %v", got, err)
+	}
+
+	var e ErrorResponse
+	if resp := postJSON(t, srv.URL+"/api/process", ProcessRequest{EncryptedData: env.String(), SessionID: "unknown"}, &e); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("unknown session: status %d, error %q", resp.StatusCode, e.Error)
+	}
+	if resp := postJSON(t, srv.URL+"/api/ecdh", ECDHRequest{PublicKey: base64.StdEncoding.EncodeToString(make([]byte, 32))}, &e); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("low-order public key: status %d, error %q", resp.StatusCode, e.Error)
+	}
+}
@@ -0,0 +1,232 @@
+package router
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/hybrid"
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/jwe"
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/rsakey"
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/rsapad"
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/logging"
+)
+
+type RSAProcessRequest struct {
+	EncryptedData string `json:"encryptedData"`
+	KID           string `json:"kid,omitempty"` // 加密所用公钥的 kid，为空时尝试当前密钥和宽限期内的旧密钥；PKCS#1 v1.5 时必填
+	JWE           string `json:"jwe,omitempty"` // JWE 紧凑令牌（RSA-OAEP-256 + A128GCM/A256GCM），替代 encryptedData
+	// Padding encryptedData 的填充方案，为空时为 OAEP-SHA256（不影响 JWE）
+	Padding *rsapad.Padding `json:"padding,omitempty"`
+	// ResponseJWK 客户端 RSA 公钥，设置后响应额外返回用它加密解密结果的 JWE
+	ResponseJWK *rsakey.JWK `json:"responseJwk,omitempty"`
+}
+
+type RSAHybridRequest struct {
+	hybrid.Payload
+	AAD string `json:"aad,omitempty"`
+}
+
+// requireKID PKCS#1 v1.5 隐式拒绝在任何密钥下都"解密成功"，无法在密钥环中挑选密钥，
+// 因此该填充必须提供 kid；缺少时写入 400 并返回 false
+func requireKID(w http.ResponseWriter, r *http.Request, kid string, padding *rsapad.Padding) bool {
+	if kid != "" || !padding.IsPKCS1v15() {
+		return true
+	}
+	Logger(r.Context()).Warn("PKCS#1 v1.5 request without kid")
+	WriteError(w, http.StatusBadRequest, "kid is required for PKCS#1 v1.5 padding")
+	return false
+}
+
+// RSADecrypt 使用RSA私钥按 padding 解密（nil 为 OAEP-SHA256）
+func RSADecrypt(privateKey *rsa.PrivateKey, encryptedData string, padding *rsapad.Padding) (string, error) {
+	// 解码Base64密文
+	encryptedBytes, err := base64.StdEncoding.DecodeString(encryptedData)
+	if err != nil {
+		return "", fmt.Errorf("base64 decode failed: %v", err)
+	}
+
+	// 使用RSA私钥解密
+	decryptedBytes, err := padding.Decrypt(privateKey, encryptedBytes)
+	if err != nil {
+		return "", fmt.Errorf("RSA decryption failed: %v", err)
+	}
+
+	// 将解密后的字节数组作为UTF-8字符串返回
+	return string(decryptedBytes), nil
+}
+
+// rsaPublicKey 获取RSA公钥接口
+func (s *server) rsaPublicKey(w http.ResponseWriter, r *http.Request) {
+	keyring, ok := need(w, r, s.RSAKeyring, "RSA key generation failed")
+	if !ok {
+		return
+	}
+	current := keyring.Current()
+	publicKey, err := rsakey.PublicKeyPEM(&current.PrivateKey.PublicKey)
+	if err != nil {
+		Logger(r.Context()).Error("Failed to marshal public key", "error", err)
+		WriteError(w, http.StatusInternalServerError, "Failed to export RSA public key")
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]string{
+		"publicKey": publicKey,
+		"kid":       current.ID,
+	})
+}
+
+// jwks 以 JWK Set（RFC 7517）格式发布 RSA 加密公钥（use: enc，包含当前密钥和宽限期内的旧密钥），
+// 配置了文档签名密钥环时一并发布验证公钥（use: sig），客户端按 use 与 kid 选择密钥
+func (s *server) jwks(w http.ResponseWriter, r *http.Request) {
+	keyring, ok := need(w, r, s.RSAKeyring, "RSA key generation failed")
+	if !ok {
+		return
+	}
+	keys := []interface{}{}
+	for _, j := range keyring.JWKS().Keys {
+		keys = append(keys, j)
+	}
+	if s.SigningKeyring != nil {
+		signingKeyring, ok := need(w, r, s.SigningKeyring, "Signing unavailable")
+		if !ok {
+			return
+		}
+		set, err := signingKeyring.JWKS()
+		if err != nil {
+			Logger(r.Context()).Error("Failed to export signing keys", "error", err)
+			WriteError(w, http.StatusInternalServerError, "Failed to export signing keys")
+			return
+		}
+		for _, j := range set.Keys {
+			keys = append(keys, j)
+		}
+	}
+
+	// 旧密钥在宽限期内仍会出现在集合中，客户端缓存一小时不会错过轮换
+	w.Header().Set("Content-Type", "application/jwk-set+json")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	json.NewEncoder(w).Encode(map[string][]interface{}{"keys": keys})
+}
+
+// rsaProcess RSA解密处理接口
+func (s *server) rsaProcess(w http.ResponseWriter, r *http.Request) {
+	logger := Logger(r.Context())
+
+	var req RSAProcessRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Warn("JSON decode error", "error", err)
+		WriteError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if req.EncryptedData == "" && req.JWE == "" {
+		logger.Warn("Empty encrypted data provided")
+		WriteError(w, http.StatusBadRequest, "Encrypted data or JWE is required")
+		return
+	}
+	if req.JWE == "" && !requireKID(w, r, req.KID, req.Padding) {
+		return
+	}
+
+	// 按 kid 从密钥环选择私钥解密（确保使用与公钥匹配的密钥对）
+	keyring, ok := need(w, r, s.RSAKeyring, "RSA key generation failed")
+	if !ok {
+		return
+	}
+
+	logger.Debug("Starting RSA decryption", "kid", req.KID, "jwe", req.JWE != "", "padding", req.Padding.String())
+	var decryptedBytes []byte
+	var err error
+	enc := jwe.EncA256GCM
+	if req.JWE != "" {
+		var token *jwe.Token
+		token, err = jwe.Parse(req.JWE)
+		if err != nil {
+			logger.Warn("JWE parse failed", "error", err)
+			WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid JWE: %v", err))
+			return
+		}
+		// JWE 头部中的 kid 优先于请求字段
+		kid := token.Header.Kid
+		if kid == "" {
+			kid = req.KID
+		}
+		enc = token.Header.Enc
+		decryptedBytes, err = keyring.Decrypt(kid, token.Decrypt)
+	} else {
+		decryptedBytes, err = keyring.Decrypt(req.KID, func(privateKey *rsa.PrivateKey) ([]byte, error) {
+			data, err := RSADecrypt(privateKey, req.EncryptedData, req.Padding)
+			return []byte(data), err
+		})
+	}
+	if err != nil {
+		logger.Warn("RSA decryption failed", "error", err)
+		WriteError(w, http.StatusBadRequest, fmt.Sprintf("RSA decryption failed: %v", err))
+		return
+	}
+
+	logger.Info("RSA decryption successful", "plaintext", logging.Plaintext(decryptedBytes))
+
+	resp := map[string]string{
+		"decryptedData": string(decryptedBytes),
+	}
+	// 客户端提供 responseJwk 时，额外返回用其公钥加密的 JWE
+	if req.ResponseJWK != nil {
+		publicKey, err := req.ResponseJWK.PublicKey()
+		if err == nil {
+			resp["jwe"], err = jwe.Encrypt(publicKey, decryptedBytes, jwe.Header{Enc: enc, Kid: req.ResponseJWK.Kid})
+		}
+		if err != nil {
+			logger.Warn("JWE encryption failed", "error", err)
+			WriteError(w, http.StatusBadRequest, fmt.Sprintf("JWE encryption failed: %v", err))
+			return
+		}
+	}
+
+	WriteJSON(w, http.StatusOK, resp)
+}
+
+// rsaHybrid RSA-OAEP 包裹密钥 + AES-GCM 加密正文的混合解密接口，支持任意长度数据
+func (s *server) rsaHybrid(w http.ResponseWriter, r *http.Request) {
+	logger := Logger(r.Context())
+
+	var req RSAHybridRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Warn("JSON decode error", "error", err)
+		WriteError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if req.EncryptedKey == "" || req.IV == "" || req.EncryptedData == "" {
+		logger.Warn("Empty encrypted key, IV or data provided")
+		WriteError(w, http.StatusBadRequest, "Encrypted key, IV and data are required")
+		return
+	}
+	if !requireKID(w, r, req.KID, req.Padding) {
+		return
+	}
+
+	// 按 kid 从密钥环选择与 /api/rsa/public-key 对应的私钥解包内容密钥
+	keyring, ok := need(w, r, s.RSAKeyring, "RSA key generation failed")
+	if !ok {
+		return
+	}
+
+	decrypted, err := keyring.Decrypt(req.KID, func(privateKey *rsa.PrivateKey) ([]byte, error) {
+		return hybrid.Decrypt(privateKey, &req.Payload, []byte(req.AAD))
+	})
+	if err != nil {
+		logger.Warn("Hybrid decryption failed", "error", err)
+		WriteError(w, http.StatusBadRequest, fmt.Sprintf("Hybrid decryption failed: %v", err))
+		return
+	}
+
+	logger.Info("Hybrid decryption successful", "plaintext", logging.Plaintext(decrypted))
+
+	WriteJSON(w, http.StatusOK, map[string]string{
+		"decryptedData": string(decrypted),
+	})
+}
@@ -0,0 +1,64 @@
+package router
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/rsakey"
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/rsapad"
+)
+
+func TestRSAProcessKeyring(t *testing.T) {
+	current, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring := rsakey.NewKeyring(current, time.Hour)
+	keyring.AddRetired(old, time.Now())
+	srv := newTestServer(t, Deps{RSAKeyring: Value(keyring)})
+
+	// 不带 kid 的 OAEP 密文：尝试所有密钥，旧密钥加密的也能解开
+	ct, _ := rsa.EncryptOAEP(sha256.New(), rand.Reader, &old.PublicKey, []byte("hello"), nil)
+	var out map[string]string
+	if resp := postJSON(t, srv.URL+"/api/rsa/process", RSAProcessRequest{EncryptedData: base64.StdEncoding.EncodeToString(ct)}, &out); resp.StatusCode != http.StatusOK || out["decryptedData"] != "hello" {
+		t.Errorf("OAEP without kid: status %d, %v", resp.StatusCode, out)
+	}
+
+	pkcs1 := &rsapad.Padding{Scheme: rsapad.SchemePKCS1v15}
+	ct, _ = rsa.EncryptPKCS1v15(rand.Reader, &old.PublicKey, []byte("legacy"))
+	req := RSAProcessRequest{EncryptedData: base64.StdEncoding.EncodeToString(ct), KID: rsakey.KeyID(&old.PublicKey), Padding: pkcs1}
+	if resp := postJSON(t, srv.URL+"/api/rsa/process", req, &out); resp.StatusCode != http.StatusOK || out["decryptedData"] != "legacy" {
+		t.Errorf("PKCS#1 v1.5 with kid: status %d, %v", resp.StatusCode, out)
+	}
+}
+
+// TestPKCS1v15RequiresKID 隐式拒绝下任何密钥都"解密成功"，不带 kid 的 PKCS#1 v1.5 请求一律 400
+func TestPKCS1v15RequiresKID(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newTestServer(t, Deps{RSAKeyring: Value(rsakey.NewKeyring(key, time.Hour))})
+	pkcs1 := &rsapad.Padding{Scheme: rsapad.SchemePKCS1v15}
+
+	requests := map[string]interface{}{
+		"/api/rsa/process": RSAProcessRequest{EncryptedData: "AAAA", Padding: pkcs1},
+		"/api/session":     SessionRequest{EncryptedKey: "AAAA", Padding: pkcs1},
+		"/api/rsa/hybrid":  map[string]interface{}{"encryptedKey": "AAAA", "iv": "AAAA", "encryptedData": "AAAA", "padding": pkcs1},
+	}
+	for path, body := range requests {
+		var e ErrorResponse
+		if resp := postJSON(t, srv.URL+path, body, &e); resp.StatusCode != http.StatusBadRequest || e.Error != "kid is required for PKCS#1 v1.5 padding" {
+			t.Errorf("%s: status %d, error %q", path, resp.StatusCode, e.Error)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package router
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/hybrid"
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/rsapad"
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/x25519"
+)
+
+type SessionRequest struct {
+	EncryptedKey    string `json:"encryptedKey,omitempty"`    // RSA-OAEP-SHA256 包裹的 16/24/32 字节会话密钥
+	EncapsulatedKey string `json:"encapsulatedKey,omitempty"` // 或：X-Wing 封装密文，共享秘密即会话密钥
+	KID             string `json:"kid,omitempty"`             // 包裹/封装所用公钥的 kid，PKCS#1 v1.5 时必填
+	// Padding encryptedKey 的填充方案，为空时为 OAEP-SHA256；PKCS#1 v1.5 只支持 32 字节会话密钥
+	Padding *rsapad.Padding `json:"padding,omitempty"`
+}
+
+type SessionResponse struct {
+	SessionID string `json:"sessionId"`
+	ExpiresAt string `json:"expiresAt"` // RFC 3339
+}
+
+type ECDHRequest struct {
+	PublicKey string `json:"publicKey"` // 客户端临时 X25519 公钥（32 字节，标准 Base64）
+}
+
+type ECDHResponse struct {
+	PublicKey string `json:"publicKey"` // 服务端临时 X25519 公钥（标准 Base64）
+	SessionID string `json:"sessionId"`
+	ExpiresAt string `json:"expiresAt"` // RFC 3339
+}
+
+// session 会话密钥握手：解包客户端用 RSA 公钥包裹（或用 X-Wing 公钥封装）的会话密钥，返回会话 id
+func (s *server) session(w http.ResponseWriter, r *http.Request) {
+	logger := Logger(r.Context())
+
+	var req SessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Warn("JSON decode error", "error", err)
+		WriteError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if req.EncryptedKey == "" && req.EncapsulatedKey == "" {
+		logger.Warn("Empty encrypted key provided")
+		WriteError(w, http.StatusBadRequest, "Encrypted key is required")
+		return
+	}
+	if req.EncapsulatedKey == "" && !requireKID(w, r, req.KID, req.Padding) {
+		return
+	}
+
+	store, ok := need(w, r, s.SessionStore, "Session store unavailable")
+	if !ok {
+		return
+	}
+
+	var key []byte
+	if req.EncapsulatedKey != "" {
+		kemKey, ok := need(w, r, s.XWingKey, "KEM key unavailable")
+		if !ok {
+			return
+		}
+		var err error
+		if key, err = kemKey.UnwrapKey(req.KID, req.EncapsulatedKey); err != nil {
+			logger.Warn("Session key decapsulation failed", "error", err)
+			WriteError(w, http.StatusBadRequest, fmt.Sprintf("Session key decapsulation failed: %v", err))
+			return
+		}
+	} else {
+		keyring, ok := need(w, r, s.RSAKeyring, "RSA key generation failed")
+		if !ok {
+			return
+		}
+		var err error
+		key, err = keyring.Decrypt(req.KID, func(privateKey *rsa.PrivateKey) ([]byte, error) {
+			return hybrid.UnwrapKey(privateKey, req.EncryptedKey, req.Padding)
+		})
+		if err != nil {
+			logger.Warn("Session key unwrap failed", "error", err)
+			WriteError(w, http.StatusBadRequest, fmt.Sprintf("Session key unwrap failed: %v", err))
+			return
+		}
+	}
+
+	sess, err := store.Create(key)
+	if err != nil {
+		logger.Warn("Session creation failed", "error", err)
+		WriteError(w, http.StatusBadRequest, fmt.Sprintf("Session creation failed: %v", err))
+		return
+	}
+
+	logger.Info("Session created", "expiresAt", sess.ExpiresAt.Format(time.RFC3339))
+
+	WriteJSON(w, http.StatusOK, SessionResponse{
+		SessionID: sess.ID,
+		ExpiresAt: sess.ExpiresAt.Format(time.RFC3339),
+	})
+}
+
+// ecdh 临时 X25519 密钥协商：派生的 AES-256-GCM 密钥保存为会话，提供前向保密
+func (s *server) ecdh(w http.ResponseWriter, r *http.Request) {
+	logger := Logger(r.Context())
+
+	var req ECDHRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Warn("JSON decode error", "error", err)
+		WriteError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	clientPublic, err := base64.StdEncoding.DecodeString(req.PublicKey)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid public key encoding: %v", err))
+		return
+	}
+
+	serverPublic, key, err := x25519.Respond(clientPublic)
+	if err != nil {
+		logger.Warn("X25519 key agreement failed", "error", err)
+		WriteError(w, http.StatusBadRequest, fmt.Sprintf("Key agreement failed: %v", err))
+		return
+	}
+
+	store, ok := need(w, r, s.SessionStore, "Session store unavailable")
+	if !ok {
+		return
+	}
+
+	sess, err := store.Create(key)
+	if err != nil {
+		logger.Error("Session creation failed", "error", err)
+		WriteError(w, http.StatusInternalServerError, fmt.Sprintf("Session creation failed: %v", err))
+		return
+	}
+
+	logger.Info("ECDH session created", "expiresAt", sess.ExpiresAt.Format(time.RFC3339))
+
+	WriteJSON(w, http.StatusOK, ECDHResponse{
+		PublicKey: base64.StdEncoding.EncodeToString(serverPublic),
+		SessionID: sess.ID,
+		ExpiresAt: sess.ExpiresAt.Format(time.RFC3339),
+	})
+}
+
+// sessionKey 按会话 id 取密钥；sessionID 为空时使用请求直接提供的 key。
+// 失败时已输出错误响应，返回 false
+func (s *server) sessionKey(w http.ResponseWriter, r *http.Request, sessionID string, key []byte) ([]byte, bool) {
+	logger := Logger(r.Context())
+	if sessionID == "" {
+		if len(key) == 0 {
+			logger.Warn("Empty key provided")
+			WriteError(w, http.StatusBadRequest, "Key is required")
+			return nil, false
+		}
+		return key, true
+	}
+
+	store, ok := need(w, r, s.SessionStore, "Session store unavailable")
+	if !ok {
+		return nil, false
+	}
+	key, err := store.Get(sessionID)
+	if err != nil {
+		logger.Warn("Session lookup failed", "error", err)
+		WriteError(w, http.StatusUnauthorized, "Invalid or expired session")
+		return nil, false
+	}
+	return key, true
+}
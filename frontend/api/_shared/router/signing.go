@@ -0,0 +1,192 @@
+package router
+
+import (
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/jws"
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/respsig"
+)
+
+// SignRequest 签名请求；Encoding 为 base64 时 Payload 按标准 Base64 解码为二进制文档
+type SignRequest struct {
+	Payload  string `json:"payload"`
+	Encoding string `json:"encoding,omitempty"`
+	Alg      string `json:"alg,omitempty"`
+}
+
+// SignResponse 签名响应，JWS 为分离载荷的 Compact 串（<header>..<signature>）
+type SignResponse struct {
+	JWS string  `json:"jws"`
+	Alg string  `json:"alg"`
+	Kid string  `json:"kid"`
+	JWK jws.JWK `json:"jwk"`
+}
+
+// VerifyRequest 验签请求；JWK 或 PublicKey（PKIX PEM）提供客户端公钥，都为空时按 kid 使用服务端签名密钥
+type VerifyRequest struct {
+	JWS       string   `json:"jws"`
+	Payload   string   `json:"payload"`
+	Encoding  string   `json:"encoding,omitempty"`
+	JWK       *jws.JWK `json:"jwk,omitempty"`
+	PublicKey string   `json:"publicKey,omitempty"`
+}
+
+// VerifyResponse 验签结果
+type VerifyResponse struct {
+	Valid bool   `json:"valid"`
+	Alg   string `json:"alg,omitempty"`
+	Kid   string `json:"kid,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// signingKey 以 JWK Set 格式发布响应签名的验证公钥
+func (s *server) signingKey(w http.ResponseWriter, r *http.Request) {
+	var signer *respsig.Signer
+	if s.ResponseSigner != nil {
+		var ok bool
+		if signer, ok = need(w, r, s.ResponseSigner, "Response signing unavailable"); !ok {
+			return
+		}
+	}
+	if signer == nil {
+		WriteError(w, http.StatusNotFound, "Response signing is not enabled")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/jwk-set+json")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	json.NewEncoder(w).Encode(map[string][]respsig.JWK{
+		"keys": {signer.PublicJWK()},
+	})
+}
+
+// sign 用服务端签名密钥对文档签名；GET 返回可用算法和验证公钥
+func (s *server) sign(w http.ResponseWriter, r *http.Request) {
+	logger := Logger(r.Context())
+
+	keyring, ok := need(w, r, s.SigningKeyring, "Signing unavailable")
+	if !ok {
+		return
+	}
+
+	if r.Method == "GET" {
+		set, err := keyring.JWKS()
+		if err != nil {
+			logger.Error("Failed to export signing keys", "error", err)
+			WriteError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to export signing keys: %v", err))
+			return
+		}
+		WriteJSON(w, http.StatusOK, map[string]interface{}{
+			"algorithms": keyring.Algorithms(),
+			"keys":       set.Keys,
+		})
+		return
+	}
+
+	var req SignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Warn("JSON decode error", "error", err)
+		WriteError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	payload := []byte(req.Payload)
+	if req.Encoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(req.Payload)
+		if err != nil {
+			logger.Warn("Payload base64 decode failed", "error", err)
+			WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid base64 payload: %v", err))
+			return
+		}
+		payload = decoded
+	}
+	// 默认使用密钥环中的首选算法（含 RSA 私钥时为 PS256）
+	if algs := keyring.Algorithms(); req.Alg == "" && len(algs) > 0 {
+		req.Alg = algs[0]
+	}
+
+	compact, key, err := keyring.Sign(req.Alg, payload)
+	if err != nil {
+		logger.Warn("Signing failed", "alg", req.Alg, "error", err)
+		WriteError(w, http.StatusBadRequest, fmt.Sprintf("Signing failed: %v", err))
+		return
+	}
+	jwk, err := jws.PublicJWK(key.Signer.Public(), req.Alg)
+	if err != nil {
+		logger.Error("Failed to export signing key", "kid", key.ID, "error", err)
+		WriteError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to export signing key: %v", err))
+		return
+	}
+
+	logger.Info("Document signed", "alg", req.Alg, "kid", key.ID, "payloadLen", len(payload))
+	WriteJSON(w, http.StatusOK, SignResponse{JWS: compact, Alg: req.Alg, Kid: key.ID, JWK: jwk})
+}
+
+// verify 验签接口：jwk / publicKey 提供客户端公钥，都为空时按 kid 使用服务端签名密钥
+func (s *server) verify(w http.ResponseWriter, r *http.Request) {
+	logger := Logger(r.Context())
+
+	var req VerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Warn("JSON decode error", "error", err)
+		WriteError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	token, err := jws.Parse(req.JWS)
+	if err != nil {
+		logger.Warn("Invalid JWS", "error", err)
+		WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid JWS: %v", err))
+		return
+	}
+
+	// 内嵌载荷的 JWS 可以不传 payload
+	var payload []byte
+	if req.Payload != "" || token.Detached() {
+		payload = []byte(req.Payload)
+		if req.Encoding == "base64" {
+			if payload, err = base64.StdEncoding.DecodeString(req.Payload); err != nil {
+				logger.Warn("Payload base64 decode failed", "error", err)
+				WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid base64 payload: %v", err))
+				return
+			}
+		}
+	}
+
+	var publicKey crypto.PublicKey
+	switch {
+	case req.JWK != nil:
+		publicKey, err = req.JWK.PublicKey()
+	case req.PublicKey != "":
+		publicKey, err = jws.ParsePublicKeyPEM([]byte(req.PublicKey))
+	default:
+		keyring, ok := need(w, r, s.SigningKeyring, "Signing unavailable")
+		if !ok {
+			return
+		}
+		var key *jws.Key
+		if key, err = keyring.Lookup(token.Kid); err == nil {
+			publicKey = key.Signer.Public()
+		}
+	}
+	if err != nil {
+		logger.Warn("Invalid verification key", "kid", token.Kid, "error", err)
+		WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid verification key: %v", err))
+		return
+	}
+
+	resp := VerifyResponse{Alg: token.Alg, Kid: token.Kid}
+	// 签名无效不是请求错误：返回 200 和 valid=false
+	if err := token.Verify(publicKey, payload); err != nil {
+		logger.Info("Signature invalid", "alg", token.Alg, "kid", token.Kid, "error", err)
+		resp.Error = err.Error()
+	} else {
+		logger.Info("Signature verified", "alg", token.Alg, "kid", token.Kid)
+		resp.Valid = true
+	}
+	WriteJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,118 @@
+package router
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/jws"
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/rsakey"
+)
+
+func postJSON(t *testing.T, url string, body interface{}, out interface{}) *http.Response {
+	t.Helper()
+	var buf bytes.Buffer
+	if s, ok := body.(string); ok {
+		buf.WriteString(s)
+	} else {
+		json.NewEncoder(&buf).Encode(body)
+	}
+	resp, err := http.Post(url, "application/json", &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		json.NewDecoder(resp.Body).Decode(out)
+	}
+	return resp
+}
+
+func newSigningDeps(t *testing.T) Deps {
+	t.Helper()
+	decryptKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	signing, err := jws.NewKeyring(signKey, edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Deps{
+		RSAKeyring:     Value(rsakey.NewKeyring(decryptKey, time.Hour)),
+		SigningKeyring: Value(signing),
+	}
+}
+
+func TestSignVerifyEndpoints(t *testing.T) {
+	srv := newTestServer(t, newSigningDeps(t))
+
+	var signed SignResponse
+	if resp := postJSON(t, srv.URL+"/api/sign", SignRequest{Payload: "doc"}, &signed); resp.StatusCode != http.StatusOK {
+		t.Fatalf("sign: status %d", resp.StatusCode)
+	}
+	if signed.Alg != jws.AlgPS256 || signed.JWK.Use != "sig" {
+		t.Errorf("sign: alg %s, use %q", signed.Alg, signed.JWK.Use)
+	}
+
+	var result VerifyResponse
+	postJSON(t, srv.URL+"/api/verify", VerifyRequest{JWS: signed.JWS, Payload: "doc"}, &result)
+	if !result.Valid || result.Kid != signed.Kid {
+		t.Errorf("verify: %+v", result)
+	}
+	postJSON(t, srv.URL+"/api/verify", VerifyRequest{JWS: signed.JWS, Payload: "doc!"}, &result)
+	if result.Valid {
+		t.Error("verify tampered payload: valid")
+	}
+
+	for _, path := range []string{"/api/sign", "/api/verify"} {
+		var e ErrorResponse
+		if resp := postJSON(t, srv.URL+path, "{", &e); resp.StatusCode != http.StatusBadRequest || e.Error != "Invalid JSON" {
+			t.Errorf("%s malformed body: status %d, error %q", path, resp.StatusCode, e.Error)
+		}
+	}
+}
+
+func TestJWKSPublishesSigningKeys(t *testing.T) {
+	deps := newSigningDeps(t)
+	srv := newTestServer(t, deps)
+	decryptKeyring, _ := deps.RSAKeyring()
+	signing, _ := deps.SigningKeyring()
+
+	resp, err := http.Get(srv.URL + "/.well-known/jwks.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var set jws.JWKSet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		t.Fatal(err)
+	}
+
+	uses := map[string]string{}
+	for _, j := range set.Keys {
+		uses[j.Kid] = j.Use
+	}
+	if got := uses[decryptKeyring.Current().ID]; got != rsakey.JWKUseEnc {
+		t.Errorf("decryption key use = %q, want enc", got)
+	}
+	sigSet, _ := signing.JWKS()
+	for _, j := range sigSet.Keys {
+		if got := uses[j.Kid]; got != "sig" {
+			t.Errorf("signing key %s use = %q, want sig", j.Kid, got)
+		}
+	}
+	if len(set.Keys) != 1+len(sigSet.Keys) {
+		t.Errorf("JWKS has %d keys, want %d", len(set.Keys), 1+len(sigSet.Keys))
+	}
+}
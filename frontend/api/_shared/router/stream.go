@@ -0,0 +1,155 @@
+package router
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/stream"
+)
+
+// streamEncrypt 流式加密接口：请求体为任意长度明文，响应体为分段 AEAD 密文流，内存占用与数据长度无关
+//
+// 密钥来自 X-Session-Id（/api/session 握手得到的会话）或 X-Key 请求头，X-AAD 为可选的附加认证数据
+func (s *server) streamEncrypt(w http.ResponseWriter, r *http.Request) {
+	logger := Logger(r.Context())
+
+	key, ok := s.streamKey(w, r, r.Header.Get("X-Session-Id"), r.Header.Get("X-Key"))
+	if !ok {
+		return
+	}
+
+	// HTTP/1.x 默认在开始写响应后关闭请求体，边读边写需要全双工（运行环境不支持时忽略）
+	http.NewResponseController(w).EnableFullDuplex()
+
+	// 先读取请求体再写响应，带 Expect: 100-continue 的客户端才会收到 100 Continue 并开始上传
+	body := bufio.NewReader(r.Body)
+	if _, err := body.Peek(1); err != nil && err != io.EOF {
+		logger.Warn("Request body read failed", "error", err)
+		WriteError(w, http.StatusBadRequest, "Failed to read request body")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	declareStatusTrailer(w)
+	sw, err := stream.NewWriter(w, key, []byte(r.Header.Get("X-AAD")), 0)
+	if err != nil {
+		logger.Error("Stream init failed", "error", err)
+		WriteError(w, http.StatusInternalServerError, "Stream init failed")
+		return
+	}
+
+	n, err := io.Copy(sw, body)
+	if err == nil {
+		err = sw.Close()
+	}
+	if err != nil {
+		// 响应头已发出，只能停止写出并中断连接，客户端据此得知密文流不完整
+		logger.Warn("Stream encryption failed", "bytes", n, "error", err)
+		if err := abortResponse(w); err != nil {
+			logger.Warn("Connection not closed after stream failure", "error", err)
+		}
+		return
+	}
+
+	finishResponse(w)
+	logger.Info("Stream encryption successful", "plaintextLen", n)
+}
+
+// streamDecrypt 流式解密接口：请求体为 /api/stream/encrypt 格式的密文流，逐段认证后输出明文
+func (s *server) streamDecrypt(w http.ResponseWriter, r *http.Request) {
+	logger := Logger(r.Context())
+
+	key, ok := s.streamKey(w, r, r.Header.Get("X-Session-Id"), r.Header.Get("X-Key"))
+	if !ok {
+		return
+	}
+
+	// HTTP/1.x 默认在开始写响应后关闭请求体，边读边写需要全双工（运行环境不支持时忽略）
+	http.NewResponseController(w).EnableFullDuplex()
+
+	sr, err := stream.NewReader(r.Body, key, []byte(r.Header.Get("X-AAD")))
+	if err != nil {
+		logger.Warn("Invalid stream", "error", err)
+		WriteError(w, http.StatusBadRequest, fmt.Sprintf("Invalid stream: %v", err))
+		return
+	}
+
+	// 先解密第一段，密钥错误等常见失败仍能返回 JSON 错误
+	first := make([]byte, 32<<10)
+	n, err := sr.Read(first)
+	if err != nil && err != io.EOF {
+		logger.Warn("Stream decryption failed", "error", err)
+		WriteError(w, http.StatusBadRequest, fmt.Sprintf("Decryption failed: %v", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	declareStatusTrailer(w)
+	w.Write(first[:n])
+	rest, err := io.Copy(w, sr)
+	if err != nil {
+		// 已输出的明文均已通过认证，但流被截断或篡改，停止写出并中断连接让客户端丢弃结果
+		logger.Warn("Stream decryption failed", "bytes", int64(n)+rest, "error", err)
+		if err := abortResponse(w); err != nil {
+			logger.Warn("Connection not closed after stream failure", "error", err)
+		}
+		return
+	}
+
+	finishResponse(w)
+	logger.Info("Stream decryption successful", "plaintextLen", int64(n)+rest)
+}
+
+// streamKey 取流式与文件接口的密钥：会话密钥，或标准 Base64 编码的 stream.KeySize 字节随机密钥。
+// 分段密钥由 HKDF 直接派生，不接受口令等低熵密钥
+func (s *server) streamKey(w http.ResponseWriter, r *http.Request, sessionID, keyB64 string) ([]byte, bool) {
+	logger := Logger(r.Context())
+
+	var key []byte
+	if sessionID == "" && keyB64 != "" {
+		var err error
+		if key, err = base64.StdEncoding.DecodeString(keyB64); err != nil {
+			logger.Warn("Key base64 decode failed", "error", err)
+			WriteError(w, http.StatusBadRequest, "Key must be Base64 encoded")
+			return nil, false
+		}
+	}
+	key, ok := s.sessionKey(w, r, sessionID, key)
+	if !ok {
+		return nil, false
+	}
+	if len(key) != stream.KeySize {
+		logger.Warn("Invalid stream key size", "keyLen", len(key))
+		WriteError(w, http.StatusBadRequest, fmt.Sprintf("Key must be %d random bytes", stream.KeySize))
+		return nil, false
+	}
+	return key, true
+}
+
+// StreamStatusTrailer 流式与文件加密接口在响应末尾以 trailer 报告结果：成功为 ok，失败为 error
+const StreamStatusTrailer = "X-Stream-Status"
+
+// declareStatusTrailer 在写出响应体前声明结果 trailer
+func declareStatusTrailer(w http.ResponseWriter) {
+	w.Header().Set("Trailer", StreamStatusTrailer)
+}
+
+// finishResponse 响应体完整写出后报告成功
+func finishResponse(w http.ResponseWriter) {
+	w.Header().Set(StreamStatusTrailer, "ok")
+}
+
+// abortResponse 在响应已开始后中止请求：停止写出，trailer 报告 error，并尽量关闭底层连接，
+// 客户端因响应不完整（缺少结束分块）而得知失败。HTTP/2、Serverless 等无法劫持连接的场景返回错误，
+// 此时只能依靠缺失的结束段和 trailer 提示失败
+func abortResponse(w http.ResponseWriter) error {
+	w.Header().Set(StreamStatusTrailer, "error")
+	conn, _, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
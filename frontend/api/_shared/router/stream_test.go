@@ -0,0 +1,128 @@
+package router
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/stream"
+)
+
+func newTestServer(t *testing.T, deps Deps) *httptest.Server {
+	t.Helper()
+	if deps.Logger == nil {
+		deps.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	srv := httptest.NewServer(New(deps))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func postStream(t *testing.T, url, key string, body []byte) (*http.Response, []byte, error) {
+	t.Helper()
+	req, _ := http.NewRequest("POST", url, bytes.NewReader(body))
+	req.Header.Set("X-Key", key)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	return resp, data, err
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	srv := newTestServer(t, Deps{})
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{3}, stream.KeySize))
+	plainText := bytes.Repeat([]byte("stream "), 20000)
+
+	resp, sealed, err := postStream(t, srv.URL+"/api/stream/encrypt", key, plainText)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("encrypt: status %d, err %v", resp.StatusCode, err)
+	}
+	resp, got, err := postStream(t, srv.URL+"/api/stream/decrypt", key, sealed)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("decrypt: status %d, err %v", resp.StatusCode, err)
+	}
+	if !bytes.Equal(got, plainText) {
+		t.Fatal("round trip mismatch")
+	}
+	if status := resp.Trailer.Get(StreamStatusTrailer); status != "ok" {
+		t.Fatalf("status trailer %q, want ok", status)
+	}
+}
+
+func TestStreamRejectsWeakKeys(t *testing.T) {
+	srv := newTestServer(t, Deps{})
+	for name, key := range map[string]string{
+		"empty":      "",
+		"not base64": "secret",
+		"16 bytes":   base64.StdEncoding.EncodeToString(make([]byte, 16)),
+		"password":   base64.StdEncoding.EncodeToString([]byte("password")),
+	} {
+		resp, _, _ := postStream(t, srv.URL+"/api/stream/encrypt", key, []byte("data"))
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", name, resp.StatusCode)
+		}
+	}
+}
+
+func TestStreamDecryptAbortsOnLateTamper(t *testing.T) {
+	srv := newTestServer(t, Deps{})
+	raw := bytes.Repeat([]byte{4}, stream.KeySize)
+	key := base64.StdEncoding.EncodeToString(raw)
+
+	var buf bytes.Buffer
+	w, _ := stream.NewWriter(&buf, raw, nil, 1024)
+	w.Write(make([]byte, 100<<10))
+	w.Close()
+	sealed := buf.Bytes()
+	sealed[len(sealed)-20] ^= 1 // 篡改最后一段，首段仍能通过认证
+
+	resp, _, err := postStream(t, srv.URL+"/api/stream/decrypt", key, sealed)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d, want 200 (failure only detected after the response started)", resp.StatusCode)
+	}
+	if err == nil {
+		t.Fatal("response body completed normally, want the connection to be closed")
+	}
+}
+
+func TestStreamDecryptAbortWithoutHijack(t *testing.T) {
+	raw := bytes.Repeat([]byte{5}, stream.KeySize)
+
+	var buf bytes.Buffer
+	w, _ := stream.NewWriter(&buf, raw, nil, 1024)
+	w.Write(make([]byte, 100<<10))
+	w.Close()
+	sealed := buf.Bytes()
+	sealed[len(sealed)-20] ^= 1
+
+	// ResponseRecorder 不支持 Hijack，与 HTTP/2 和 Serverless 运行环境相同
+	req := httptest.NewRequest("POST", "/api/stream/decrypt", bytes.NewReader(sealed))
+	req.Header.Set("X-Key", base64.StdEncoding.EncodeToString(raw))
+	rec := httptest.NewRecorder()
+	func() {
+		defer func() {
+			if v := recover(); v != nil {
+				t.Fatalf("handler panicked: %v", v)
+			}
+		}()
+		New(Deps{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}).ServeHTTP(rec, req)
+	}()
+
+	resp := rec.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d, want 200", resp.StatusCode)
+	}
+	if status := resp.Trailer.Get(StreamStatusTrailer); status != "error" {
+		t.Fatalf("status trailer %q, want error", status)
+	}
+	if n := rec.Body.Len(); n == 0 || n >= 100<<10 {
+		t.Fatalf("wrote %d plaintext bytes, want a truncated prefix", n)
+	}
+}
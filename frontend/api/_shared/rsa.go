@@ -2,69 +2,76 @@ package shared
 
 import (
 	"crypto/rsa"
-	"crypto/x509"
-	"encoding/pem"
 	"fmt"
 	"os"
 	"sync"
+	"time"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/rsakey"
 )
 
 var (
+	rsaKeyring    *rsakey.Keyring
 	rsaPrivateKey *rsa.PrivateKey
 	rsaPublicKey  string
 	initOnce      sync.Once
 	initError     error
 )
 
-// initRSAKeys 初始化RSA密钥对（从环境变量加载，一次性初始化）
+// initRSAKeys 初始化RSA密钥环（一次性初始化）
+//
+// 当前私钥依次从 RSA_PRIVATE_KEY 环境变量（PEM 或 JWK 文本）、RSA_PRIVATE_KEY_FILE 指向的文件加载；
+// RSA_PREVIOUS_PRIVATE_KEYS 可配置多把已轮换的旧私钥（PEM 块拼接或 JWK Set），
+// 自 RSA_KEY_ROTATED_AT（RFC 3339，配置旧私钥时必填）起在 RSA_KEY_GRACE_PERIOD 内仍可解密。
+// 公钥由当前私钥导出，保证二者始终匹配
 func initRSAKeys() {
-	// 从环境变量获取私钥
-	privateKeyPEM := os.Getenv("RSA_PRIVATE_KEY")
-	if privateKeyPEM == "" {
-		initError = fmt.Errorf("RSA_PRIVATE_KEY environment variable is not set")
-		return
-	}
-
-	// 从环境变量获取公钥
-	publicKeyPEM := os.Getenv("RSA_PUBLIC_KEY")
-	if publicKeyPEM == "" {
-		initError = fmt.Errorf("RSA_PUBLIC_KEY environment variable is not set")
-		return
+	var rotatedAt time.Time
+	if v := os.Getenv("RSA_KEY_ROTATED_AT"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			initError = fmt.Errorf("invalid RSA_KEY_ROTATED_AT: %v", err)
+			return
+		}
+		rotatedAt = t
 	}
 
-	// 解析私钥
-	block, _ := pem.Decode([]byte(privateKeyPEM))
-	if block == nil {
-		initError = fmt.Errorf("failed to decode private key PEM")
-		return
+	var grace time.Duration
+	if v := os.Getenv("RSA_KEY_GRACE_PERIOD"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			initError = fmt.Errorf("invalid RSA_KEY_GRACE_PERIOD: %v", err)
+			return
+		}
+		grace = d
 	}
 
-	privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	keyring, err := rsakey.LoadKeyring(
+		[]rsakey.Source{
+			rsakey.Env("RSA_PRIVATE_KEY"),
+			rsakey.File{Path: os.Getenv("RSA_PRIVATE_KEY_FILE")},
+		},
+		[]byte(os.Getenv("RSA_PREVIOUS_PRIVATE_KEYS")),
+		rotatedAt,
+		grace,
+	)
 	if err != nil {
-		initError = fmt.Errorf("failed to parse private key: %v", err)
+		initError = err
 		return
 	}
 
-	rsaKey, ok := privateKey.(*rsa.PrivateKey)
-	if !ok {
-		initError = fmt.Errorf("private key is not RSA type")
+	privateKey := keyring.Current().PrivateKey
+	publicKeyPEM, err := rsakey.PublicKeyPEM(&privateKey.PublicKey)
+	if err != nil {
+		initError = err
 		return
 	}
 
-	rsaPrivateKey = rsaKey
+	rsaKeyring = keyring
+	rsaPrivateKey = privateKey
 	rsaPublicKey = publicKeyPEM
 }
 
-// GetRSAPublicKey 获取RSA公钥（使用固定的环境变量密钥）
-func GetRSAPublicKey() (string, error) {
-	initOnce.Do(initRSAKeys)
-	if initError != nil {
-		return "", initError
-	}
-	return rsaPublicKey, nil
-}
-
-// GetRSAKeyPair 获取完整的RSA密钥对（使用固定的环境变量密钥）
+// GetRSAKeyPair 获取当前的RSA密钥对（使用固定的环境变量或文件密钥）
 func GetRSAKeyPair() (*rsa.PrivateKey, string, error) {
 	initOnce.Do(initRSAKeys)
 	if initError != nil {
@@ -73,7 +80,11 @@ func GetRSAKeyPair() (*rsa.PrivateKey, string, error) {
 	return rsaPrivateKey, rsaPublicKey, nil
 }
 
-// ErrorResponse 错误响应结构体
-type ErrorResponse struct {
-	Error string `json:"error"`
+// GetRSAKeyring 获取RSA密钥环（当前密钥 + 宽限期内的旧密钥）
+func GetRSAKeyring() (*rsakey.Keyring, error) {
+	initOnce.Do(initRSAKeys)
+	if initError != nil {
+		return nil, initError
+	}
+	return rsaKeyring, nil
 }
@@ -0,0 +1,47 @@
+package shared
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/session"
+)
+
+var (
+	sessionStore     session.Store
+	sessionOnce      sync.Once
+	sessionInitError error
+)
+
+// initSessionStore 初始化会话存储（一次性初始化）
+//
+// Vercel 上 /api/session 与 /api/process 是不同的函数实例，不共享内存，
+// 因此配置了 SESSION_SECRET 时使用无状态票据；否则退回进程内存储（仅适合单实例）。
+func initSessionStore() {
+	ttl := session.DefaultTTL
+	if v := os.Getenv("SESSION_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			sessionInitError = fmt.Errorf("invalid SESSION_TTL: %v", err)
+			return
+		}
+		ttl = d
+	}
+
+	if secret := os.Getenv("SESSION_SECRET"); secret != "" {
+		sessionStore, sessionInitError = session.NewTicketStore([]byte(secret), ttl)
+		return
+	}
+	sessionStore = session.NewMemoryStore(ttl, session.DefaultMaxSessions)
+}
+
+// GetSessionStore 获取会话密钥存储
+func GetSessionStore() (session.Store, error) {
+	sessionOnce.Do(initSessionStore)
+	if sessionInitError != nil {
+		return nil, sessionInitError
+	}
+	return sessionStore, nil
+}
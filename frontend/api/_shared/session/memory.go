@@ -0,0 +1,118 @@
+package session
+
+import (
+	"container/list"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"sync"
+	"time"
+)
+
+// MemoryStore 进程内的有界会话存储
+//
+// 所有会话有效期相同，因此按创建顺序即按过期顺序排列；
+// 存满时先清理已过期会话，仍然不足则淘汰最早创建的会话。
+type MemoryStore struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	max      int
+	sessions map[string]*list.Element
+	order    *list.List // 元素为 *memoryEntry，从旧到新
+}
+
+type memoryEntry struct {
+	id        string
+	key       []byte
+	expiresAt time.Time
+}
+
+// NewMemoryStore 创建内存会话存储；ttl、max 非正数时使用默认值
+func NewMemoryStore(ttl time.Duration, max int) *MemoryStore {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	if max <= 0 {
+		max = DefaultMaxSessions
+	}
+	return &MemoryStore{
+		ttl:      ttl,
+		max:      max,
+		sessions: make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Create 保存会话密钥并返回随机会话 id
+func (s *MemoryStore) Create(key []byte) (*Session, error) {
+	if err := validKey(key); err != nil {
+		return nil, err
+	}
+
+	idBytes := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, idBytes); err != nil {
+		return nil, err
+	}
+
+	e := &memoryEntry{
+		id:        base64.RawURLEncoding.EncodeToString(idBytes),
+		key:       append([]byte(nil), key...),
+		expiresAt: time.Now().Add(s.ttl),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purgeExpired()
+	for s.order.Len() >= s.max {
+		s.remove(s.order.Front())
+	}
+	s.sessions[e.id] = s.order.PushBack(e)
+
+	return &Session{ID: e.id, ExpiresAt: e.expiresAt}, nil
+}
+
+// Get 按会话 id 取回密钥
+func (s *MemoryStore) Get(id string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.sessions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	e := el.Value.(*memoryEntry)
+	if !time.Now().Before(e.expiresAt) {
+		s.remove(el)
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), e.key...), nil
+}
+
+// Delete 主动删除会话
+func (s *MemoryStore) Delete(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.sessions[id]; ok {
+		s.remove(el)
+	}
+}
+
+// purgeExpired 从最旧的会话开始清理已过期会话（调用方持有锁）
+func (s *MemoryStore) purgeExpired() {
+	now := time.Now()
+	for el := s.order.Front(); el != nil; el = s.order.Front() {
+		if now.Before(el.Value.(*memoryEntry).expiresAt) {
+			return
+		}
+		s.remove(el)
+	}
+}
+
+// remove 删除会话并清零密钥（调用方持有锁）
+func (s *MemoryStore) remove(el *list.Element) {
+	e := s.order.Remove(el).(*memoryEntry)
+	delete(s.sessions, e.id)
+	clear(e.key)
+}
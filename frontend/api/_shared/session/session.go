@@ -0,0 +1,44 @@
+// Package session 保存握手阶段协商出的会话密钥，使 /api/process 只需携带 sessionId，
+// 对称密钥不再以明文出现在请求 JSON 中。
+package session
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// DefaultTTL 会话默认有效期
+	DefaultTTL = 30 * time.Minute
+	// DefaultMaxSessions 内存存储默认最多保存的会话数
+	DefaultMaxSessions = 10000
+)
+
+var (
+	// ErrNotFound 会话不存在、已过期或已被淘汰
+	ErrNotFound = errors.New("session: not found or expired")
+	// ErrInvalidKey 会话密钥长度不是 16/24/32 字节
+	ErrInvalidKey = errors.New("session: invalid key size")
+)
+
+// Session 新建会话的信息
+type Session struct {
+	ID        string
+	ExpiresAt time.Time
+}
+
+// Store 会话密钥存储
+type Store interface {
+	// Create 保存会话密钥并返回会话 id
+	Create(key []byte) (*Session, error)
+	// Get 按会话 id 取回密钥，不存在或已过期时返回 ErrNotFound
+	Get(id string) ([]byte, error)
+}
+
+// validKey 会话密钥直接作为 AES 密钥使用，只接受标准长度
+func validKey(key []byte) error {
+	if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+		return ErrInvalidKey
+	}
+	return nil
+}
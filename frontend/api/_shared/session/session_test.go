@@ -0,0 +1,121 @@
+package session
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func testStores(t *testing.T) map[string]Store {
+	t.Helper()
+	tickets, err := NewTicketStore([]byte("server secret"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]Store{
+		"memory": NewMemoryStore(0, 0),
+		"ticket": tickets,
+	}
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			for _, size := range []int{16, 24, 32} {
+				key := bytes.Repeat([]byte{byte(size)}, size)
+				s, err := store.Create(key)
+				if err != nil {
+					t.Fatalf("Create(%d bytes): %v", size, err)
+				}
+				if !s.ExpiresAt.After(time.Now()) {
+					t.Errorf("ExpiresAt = %v, want in the future", s.ExpiresAt)
+				}
+				got, err := store.Get(s.ID)
+				if err != nil {
+					t.Fatalf("Get: %v", err)
+				}
+				if !bytes.Equal(got, key) {
+					t.Fatalf("Get = %x, want %x", got, key)
+				}
+			}
+
+			if _, err := store.Create(make([]byte, 20)); !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("Create(20 bytes): err = %v, want ErrInvalidKey", err)
+			}
+			if _, err := store.Get("unknown"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get(unknown): err = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestStoreExpiry(t *testing.T) {
+	tickets, err := NewTicketStore([]byte("server secret"), time.Nanosecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stores := map[string]Store{
+		"memory": NewMemoryStore(time.Nanosecond, 0),
+		"ticket": tickets,
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			s, err := store.Create(make([]byte, 16))
+			if err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(time.Millisecond)
+			if _, err := store.Get(s.ID); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get(expired): err = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreEviction(t *testing.T) {
+	store := NewMemoryStore(time.Hour, 2)
+	first, _ := store.Create(make([]byte, 16))
+	second, _ := store.Create(make([]byte, 16))
+	third, _ := store.Create(make([]byte, 16))
+
+	if _, err := store.Get(first.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("oldest session: err = %v, want ErrNotFound", err)
+	}
+	for _, s := range []*Session{second, third} {
+		if _, err := store.Get(s.ID); err != nil {
+			t.Errorf("Get(%s): %v", s.ID, err)
+		}
+	}
+
+	store.Delete(second.ID)
+	if _, err := store.Get(second.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("deleted session: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestTicketStoreTamper(t *testing.T) {
+	store, err := NewTicketStore([]byte("server secret"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := store.Create(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ticket, _ := base64.RawURLEncoding.DecodeString(s.ID)
+	for i := range ticket {
+		tampered := bytes.Clone(ticket)
+		tampered[i] ^= 1
+		if _, err := store.Get(base64.RawURLEncoding.EncodeToString(tampered)); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("bit flip at %d: err = %v, want ErrNotFound", i, err)
+		}
+	}
+
+	other, _ := NewTicketStore([]byte("other secret"), 0)
+	if _, err := other.Get(s.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("other secret: err = %v, want ErrNotFound", err)
+	}
+}
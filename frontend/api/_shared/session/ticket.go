@@ -0,0 +1,92 @@
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// ticketInfo HKDF info，同时作为 GCM 附加认证数据
+const ticketInfo = "aes-go-js session ticket v1"
+
+// TicketStore 无状态会话存储：会话 id 即用服务端密钥加密的 (过期时间, 会话密钥)
+//
+// 适用于 Vercel 等无共享内存的部署，各函数实例只需配置相同的服务端密钥。
+// 过期时间由服务端校验，但会话无法在过期前主动吊销。
+type TicketStore struct {
+	aead cipher.AEAD
+	ttl  time.Duration
+}
+
+// NewTicketStore 由服务端密钥派生票据加密密钥；ttl 非正数时使用默认值
+func NewTicketStore(secret []byte, ttl time.Duration) (*TicketStore, error) {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	key, err := hkdf.Key(sha256.New, secret, nil, ticketInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &TicketStore{aead: gcm, ttl: ttl}, nil
+}
+
+// Create 把会话密钥和过期时间加密为会话 id
+func (s *TicketStore) Create(key []byte) (*Session, error) {
+	if err := validKey(key); err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(s.ttl)
+	plain := binary.BigEndian.AppendUint64(nil, uint64(expiresAt.Unix()))
+	plain = append(plain, key...)
+
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	ticket := s.aead.Seal(nonce, nonce, plain, []byte(ticketInfo))
+
+	return &Session{
+		ID:        base64.RawURLEncoding.EncodeToString(ticket),
+		ExpiresAt: time.Unix(expiresAt.Unix(), 0),
+	}, nil
+}
+
+// Get 解密会话 id 并校验过期时间
+func (s *TicketStore) Get(id string) ([]byte, error) {
+	ticket, err := base64.RawURLEncoding.DecodeString(id)
+	if err != nil || len(ticket) < s.aead.NonceSize() {
+		return nil, ErrNotFound
+	}
+
+	nonce, sealed := ticket[:s.aead.NonceSize()], ticket[s.aead.NonceSize():]
+	plain, err := s.aead.Open(nil, nonce, sealed, []byte(ticketInfo))
+	if err != nil || len(plain) < 8 {
+		return nil, ErrNotFound
+	}
+
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(plain[:8])), 0)
+	if !time.Now().Before(expiresAt) {
+		return nil, ErrNotFound
+	}
+	key := plain[8:]
+	if err := validKey(key); err != nil {
+		return nil, ErrNotFound
+	}
+	return key, nil
+}
@@ -0,0 +1,133 @@
+package shared
+
+import (
+	"crypto"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/jws"
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/respsig"
+)
+
+var (
+	responseSigner  *respsig.Signer
+	signerOnce      sync.Once
+	signerInitError error
+
+	signingKeyring   *jws.Keyring
+	keyringOnce      sync.Once
+	keyringInitError error
+)
+
+// initResponseSigner 初始化响应签名器（一次性初始化）
+//
+// RESPONSE_SIGNING 为 ed25519 或 rsa-pss 时使用 RESPONSE_SIGNING_KEY 中对应类型的私钥（PKCS#8 PEM），
+// 该私钥不能与文档签名私钥相同；为 rsa-keyring 时以 PS256 复用当前 RSA 解密私钥；未设置时不签名。
+// 签名私钥与解密私钥相同时只记录警告
+func initResponseSigner() {
+	mode := os.Getenv("RESPONSE_SIGNING")
+	if mode == "" {
+		return
+	}
+	var key crypto.Signer
+	if mode == respsig.ModeRSAKeyring {
+		privateKey, _, err := GetRSAKeyPair()
+		if err != nil {
+			signerInitError = fmt.Errorf("RESPONSE_SIGNING=%s: %v", mode, err)
+			return
+		}
+		key = privateKey
+	} else {
+		privateKey, err := jws.ParsePrivateKeyPEM([]byte(os.Getenv("RESPONSE_SIGNING_KEY")))
+		if err != nil {
+			signerInitError = fmt.Errorf("RESPONSE_SIGNING_KEY: %v", err)
+			return
+		}
+		key = privateKey
+	}
+	signer, err := respsig.NewSignerForMode(mode, key)
+	if err != nil {
+		signerInitError = fmt.Errorf("invalid RESPONSE_SIGNING: %v", err)
+		return
+	}
+	if mode != respsig.ModeRSAKeyring {
+		if keyring, err := GetSigningKeyring(); err == nil {
+			if _, err := keyring.Lookup(signer.KeyID); err == nil {
+				signerInitError = fmt.Errorf("RESPONSE_SIGNING_KEY must differ from the document signing keys")
+				return
+			}
+		}
+	}
+	if isDecryptionKey(signer.KeyID) {
+		Logger().Warn("Response signing uses the RSA decryption key; set RESPONSE_SIGNING to ed25519 or rsa-pss with a dedicated RESPONSE_SIGNING_KEY to keep signing and decryption keys separate", "kid", signer.KeyID)
+	}
+	responseSigner = signer
+}
+
+// GetResponseSigner 获取响应签名器；未启用签名时返回 nil
+func GetResponseSigner() (*respsig.Signer, error) {
+	signerOnce.Do(initResponseSigner)
+	if signerInitError != nil {
+		return nil, signerInitError
+	}
+	return responseSigner, nil
+}
+
+// signingKeyEnv 文档签名私钥环境变量及其对应的首选算法
+var signingKeyEnv = []struct {
+	name string
+	alg  string
+}{
+	{"SIGNING_EC_PRIVATE_KEY", jws.AlgES256},
+	{"SIGNING_ED25519_PRIVATE_KEY", jws.AlgEdDSA},
+}
+
+// initSigningKeyring 初始化文档签名密钥环（一次性初始化）
+//
+// PS256 / RS256 使用 GetRSAKeyPair 加载的当前 RSA 私钥；SIGNING_EC_PRIVATE_KEY 与
+// SIGNING_ED25519_PRIVATE_KEY（PKCS#8 PEM，EC 也可为 SEC 1）分别启用 ES256 和 EdDSA，未设置时对应算法不可用
+func initSigningKeyring() {
+	rsaPrivateKey, _, err := GetRSAKeyPair()
+	if err != nil {
+		keyringInitError = fmt.Errorf("RSA key: %v", err)
+		return
+	}
+	signers := []crypto.Signer{rsaPrivateKey}
+	for _, env := range signingKeyEnv {
+		data := os.Getenv(env.name)
+		if data == "" {
+			continue
+		}
+		key, err := jws.ParsePrivateKeyPEM([]byte(data))
+		if err != nil {
+			keyringInitError = fmt.Errorf("%s: %v", env.name, err)
+			return
+		}
+		if algs := jws.Algorithms(key.Public()); algs[0] != env.alg {
+			keyringInitError = fmt.Errorf("%s: %s key required, got %T", env.name, env.alg, key)
+			return
+		}
+		signers = append(signers, key)
+	}
+	signingKeyring, keyringInitError = jws.NewKeyring(signers...)
+}
+
+// isDecryptionKey 判断 kid 是否属于 RSA 解密密钥环；RSA 密钥环不可用时返回 false
+func isDecryptionKey(kid string) bool {
+	keyring, err := GetRSAKeyring()
+	if err != nil {
+		return false
+	}
+	_, err = keyring.Lookup(kid)
+	return err == nil
+}
+
+// GetSigningKeyring 获取 /api/sign、/api/verify 使用的签名密钥环
+func GetSigningKeyring() (*jws.Keyring, error) {
+	keyringOnce.Do(initSigningKeyring)
+	if keyringInitError != nil {
+		return nil, keyringInitError
+	}
+	return signingKeyring, nil
+}
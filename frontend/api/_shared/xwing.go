@@ -0,0 +1,40 @@
+package shared
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/LeeeeeeM/aes-go-js/api/_shared/crypto/xwing"
+)
+
+var (
+	xwingKey       *xwing.PrivateKey
+	xwingOnce      sync.Once
+	xwingInitError error
+)
+
+// initXWingKey 初始化 X-Wing 私钥（一次性初始化）
+//
+// 依次从 XWING_PRIVATE_KEY 环境变量、XWING_PRIVATE_KEY_FILE 指向的文件读取 "X-WING PRIVATE KEY" PEM；
+// 各函数实例必须使用同一把私钥，因此不会自动生成
+func initXWingKey() {
+	if v := os.Getenv("XWING_PRIVATE_KEY"); v != "" {
+		xwingKey, xwingInitError = xwing.ParsePrivateKeyPEM([]byte(v))
+		return
+	}
+	if path := os.Getenv("XWING_PRIVATE_KEY_FILE"); path != "" {
+		xwingKey, xwingInitError = xwing.LoadFile(path, false)
+		return
+	}
+	xwingInitError = fmt.Errorf("XWING_PRIVATE_KEY or XWING_PRIVATE_KEY_FILE is not set")
+}
+
+// GetXWingKey 获取 X25519 + ML-KEM-768 混合 KEM 私钥
+func GetXWingKey() (*xwing.PrivateKey, error) {
+	xwingOnce.Do(initXWingKey)
+	if xwingInitError != nil {
+		return nil, xwingInitError
+	}
+	return xwingKey, nil
+}
@@ -0,0 +1,12 @@
+package handler
+
+import (
+	"net/http"
+
+	shared "github.com/LeeeeeeM/aes-go-js/api/_shared"
+)
+
+// ECDHHandler 临时 X25519 密钥协商：派生的 AES-256-GCM 密钥保存为会话，供 /api/process 使用
+func ECDHHandler(w http.ResponseWriter, r *http.Request) {
+	shared.Router().ServeHTTP(w, r)
+}
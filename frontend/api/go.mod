@@ -1,3 +1,12 @@
 module github.com/LeeeeeeM/aes-go-js/api
 
 go 1.24.3
+
+require github.com/LeeeeeeM/aes-go-js/pkg/jsaes v0.0.0
+
+require (
+	golang.org/x/crypto v0.45.0 // indirect
+	golang.org/x/sys v0.38.0 // indirect
+)
+
+replace github.com/LeeeeeeM/aes-go-js/pkg/jsaes => ../../pkg/jsaes
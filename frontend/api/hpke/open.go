@@ -0,0 +1,12 @@
+package handler
+
+import (
+	"net/http"
+
+	shared "github.com/LeeeeeeM/aes-go-js/api/_shared"
+)
+
+// HPKEOpenHandler 解密 HPKE 单次消息（base 模式，或带 senderPublicKey 的 auth 模式）
+func HPKEOpenHandler(w http.ResponseWriter, r *http.Request) {
+	shared.Router().ServeHTTP(w, r)
+}
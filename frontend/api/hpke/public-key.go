@@ -0,0 +1,12 @@
+package handler
+
+import (
+	"net/http"
+
+	shared "github.com/LeeeeeeM/aes-go-js/api/_shared"
+)
+
+// HPKEPublicKeyHandler 返回 HPKE 接收方公钥和支持的算法标识
+func HPKEPublicKeyHandler(w http.ResponseWriter, r *http.Request) {
+	shared.Router().ServeHTTP(w, r)
+}
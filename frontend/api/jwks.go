@@ -0,0 +1,12 @@
+package handler
+
+import (
+	"net/http"
+
+	shared "github.com/LeeeeeeM/aes-go-js/api/_shared"
+)
+
+// JWKSHandler 以 JWK Set（RFC 7517）格式发布 RSA 公钥，经 vercel.json 映射到 /.well-known/jwks.json
+func JWKSHandler(w http.ResponseWriter, r *http.Request) {
+	shared.Router().ServeHTTP(w, r)
+}
@@ -0,0 +1,12 @@
+package handler
+
+import (
+	"net/http"
+
+	shared "github.com/LeeeeeeM/aes-go-js/api/_shared"
+)
+
+// KEMHybridHandler X-Wing 封装密钥 + AES-GCM 加密正文的混合解密接口，与 /api/rsa/hybrid 对应
+func KEMHybridHandler(w http.ResponseWriter, r *http.Request) {
+	shared.Router().ServeHTTP(w, r)
+}
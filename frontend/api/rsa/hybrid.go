@@ -0,0 +1,12 @@
+package handler
+
+import (
+	"net/http"
+
+	shared "github.com/LeeeeeeM/aes-go-js/api/_shared"
+)
+
+// RSAHybridHandler RSA-OAEP 包裹密钥 + AES-GCM 加密正文的混合解密接口，支持任意长度数据
+func RSAHybridHandler(w http.ResponseWriter, r *http.Request) {
+	shared.Router().ServeHTTP(w, r)
+}
@@ -0,0 +1,12 @@
+package handler
+
+import (
+	"net/http"
+
+	shared "github.com/LeeeeeeM/aes-go-js/api/_shared"
+)
+
+// SessionHandler 会话密钥握手：解包客户端用 RSA 公钥包裹（或用 X-Wing 公钥封装）的会话密钥，返回会话 id
+func SessionHandler(w http.ResponseWriter, r *http.Request) {
+	shared.Router().ServeHTTP(w, r)
+}
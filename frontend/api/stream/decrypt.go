@@ -0,0 +1,14 @@
+package handler
+
+import (
+	"net/http"
+
+	shared "github.com/LeeeeeeM/aes-go-js/api/_shared"
+)
+
+// StreamDecryptHandler 流式解密接口：请求体为 /api/stream/encrypt 格式的密文流，响应体为明文，逐段认证后输出
+//
+// 密钥来自 X-Session-Id 或 X-Key 请求头，X-AAD 须与加密时一致
+func StreamDecryptHandler(w http.ResponseWriter, r *http.Request) {
+	shared.Router().ServeHTTP(w, r)
+}
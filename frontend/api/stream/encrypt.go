@@ -0,0 +1,14 @@
+package handler
+
+import (
+	"net/http"
+
+	shared "github.com/LeeeeeeM/aes-go-js/api/_shared"
+)
+
+// StreamEncryptHandler 流式加密接口：请求体为任意长度明文，响应体为分段 AEAD 密文流，内存占用与数据长度无关
+//
+// 密钥来自 X-Session-Id（/api/session 握手得到的会话）或 X-Key 请求头，X-AAD 为可选的附加认证数据
+func StreamEncryptHandler(w http.ResponseWriter, r *http.Request) {
+	shared.Router().ServeHTTP(w, r)
+}
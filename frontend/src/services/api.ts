@@ -4,7 +4,9 @@ const API_BASE_URL = '/api';
 
 export interface ProcessRequest {
   encryptedData: string; // cipherB64 + ivB64 组合
-  key: string;
+  key?: string;
+  sessionId?: string; // 握手得到的会话 id，提供时无需发送 key
+  aad?: string; // 附加认证数据，需与加密时 additionalData 一致
 }
 
 export interface ProcessResponse {
@@ -1,6 +1,10 @@
 import * as forge from 'node-forge';
 
 // 真正的AES-GCM实现，使用node-forge库
+//
+// 前端有意只使用原始密钥（补\0/截断，与 Go 端 PadKey 一致），不实现口令派生（KDF）和信封格式：
+// KDF 模式是服务端功能，供其他 Go 客户端通过 pkg/jsaes 使用。node-forge 的纯 JS PBKDF2 在
+// 默认成本参数下需要数秒，浏览器端如需 KDF 应改用 WebCrypto 实现后再接入
 export class AESCrypto {
   private key: string;
 
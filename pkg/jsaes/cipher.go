@@ -0,0 +1,168 @@
+package jsaes
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Cipher 绑定了密钥的 AEAD 加解密器（AES-GCM、AES-GCM-SIV、ChaCha20-Poly1305 或 XChaCha20-Poly1305），可并发使用
+type Cipher struct {
+	aead cipher.AEAD
+	alg  Algorithm
+}
+
+// NewCipher 使用 16/24/32 字节的原始密钥创建 Cipher
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+		return nil, fmt.Errorf("%w: 密钥长度必须是16/24/32字节，实际长度: %d", ErrInvalidKeySize, len(key))
+	}
+
+	// 创建 AES 区块和 GCM 模式
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("GCM 创建失败: %v", err)
+	}
+
+	return &Cipher{aead: gcm, alg: gcmAlgorithm(len(key))}, nil
+}
+
+// NewChaCha20Poly1305 使用 32 字节密钥创建 ChaCha20-Poly1305（RFC 8439）Cipher，nonce 为 12 字节；
+// 适合没有 AES 硬件加速的客户端
+func NewChaCha20Poly1305(key []byte) (*Cipher, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: ChaCha20-Poly1305 密钥必须是32字节，实际长度: %d", ErrInvalidKeySize, len(key))
+	}
+	return &Cipher{aead: aead, alg: C20P}, nil
+}
+
+// NewXChaCha20Poly1305 使用 32 字节密钥创建 XChaCha20-Poly1305 Cipher，nonce 为 24 字节，
+// 随机 nonce 在同一密钥下加密海量消息也无需计数
+func NewXChaCha20Poly1305(key []byte) (*Cipher, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: XChaCha20-Poly1305 密钥必须是32字节，实际长度: %d", ErrInvalidKeySize, len(key))
+	}
+	return &Cipher{aead: aead, alg: XC20P}, nil
+}
+
+// NewCipherWithAlgorithm 按算法名创建 Cipher；AES-GCM 算法要求密钥长度与算法一致
+func NewCipherWithAlgorithm(alg Algorithm, key []byte) (*Cipher, error) {
+	switch alg {
+	case A128GCM, A192GCM, A256GCM:
+		if gcmAlgorithm(len(key)) != alg {
+			return nil, fmt.Errorf("%w: %d-byte key does not match algorithm %s", ErrInvalidKeySize, len(key), alg)
+		}
+		return NewCipher(key)
+	case C20P:
+		return NewChaCha20Poly1305(key)
+	case XC20P:
+		return NewXChaCha20Poly1305(key)
+	case A128GCMSIV, A256GCMSIV:
+		c, err := NewAESGCMSIV(key)
+		if err == nil && c.alg != alg {
+			return nil, fmt.Errorf("%w: %d-byte key does not match algorithm %s", ErrInvalidKeySize, len(key), alg)
+		}
+		return c, err
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, alg)
+	}
+}
+
+// Algorithm 返回 Cipher 使用的算法
+func (c *Cipher) Algorithm() Algorithm {
+	return c.alg
+}
+
+// NewCipherFromJSKey 先按前端逻辑调整密钥长度（见 PadKey），再创建 Cipher
+func NewCipherFromJSKey(key []byte) (*Cipher, error) {
+	return NewCipher(PadKey(key))
+}
+
+// NonceSize 返回 IV 长度（GCM、GCM-SIV 与 ChaCha20-Poly1305 为 12 字节，XChaCha20-Poly1305 为 24 字节）
+func (c *Cipher) NonceSize() int {
+	return c.aead.NonceSize()
+}
+
+// Seal 使用指定 IV 加密，返回 密文 + 认证标签
+func (c *Cipher) Seal(iv, plainText []byte) ([]byte, error) {
+	return c.SealWithAAD(iv, plainText, nil)
+}
+
+// SealWithAAD 使用指定 IV 和附加认证数据加密；解密时必须提供相同的 aad
+func (c *Cipher) SealWithAAD(iv, plainText, aad []byte) ([]byte, error) {
+	if len(iv) != c.aead.NonceSize() {
+		return nil, fmt.Errorf("%w: IV长度必须是%d字节，实际是%d字节", ErrInvalidIVSize, c.aead.NonceSize(), len(iv))
+	}
+	return c.aead.Seal(nil, iv, plainText, aad), nil
+}
+
+// Open 使用指定 IV 解密 密文 + 认证标签
+func (c *Cipher) Open(iv, cipherTextWithTag []byte) ([]byte, error) {
+	return c.OpenWithAAD(iv, cipherTextWithTag, nil)
+}
+
+// OpenWithAAD 使用指定 IV 和附加认证数据解密，aad 不一致时认证失败
+func (c *Cipher) OpenWithAAD(iv, cipherTextWithTag, aad []byte) ([]byte, error) {
+	if len(iv) != c.aead.NonceSize() {
+		return nil, fmt.Errorf("%w: IV长度必须是%d字节，实际是%d字节", ErrInvalidIVSize, c.aead.NonceSize(), len(iv))
+	}
+
+	// AEAD 自动拆分密文和标签
+	plainText, err := c.aead.Open(nil, iv, cipherTextWithTag, aad)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 解密失败：%v", ErrAuthFailed, err)
+	}
+	return plainText, nil
+}
+
+// Encrypt 生成随机 IV 加密，返回 Base64 编码的 密文+标签 与 IV（与 JS 格式统一）
+func (c *Cipher) Encrypt(plainText []byte) (string, string, error) {
+	return c.EncryptWithAAD(plainText, nil)
+}
+
+// EncryptWithAAD 同 Encrypt，并绑定附加认证数据
+func (c *Cipher) EncryptWithAAD(plainText, aad []byte) (string, string, error) {
+	// 按算法生成随机 IV（GCM 为 12 字节）
+	iv := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return "", "", err
+	}
+
+	cipherText, err := c.SealWithAAD(iv, plainText, aad)
+	if err != nil {
+		return "", "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(cipherText), base64.StdEncoding.EncodeToString(iv), nil
+}
+
+// Decrypt 解密 Base64 编码的 密文+标签 与 IV
+func (c *Cipher) Decrypt(cipherB64, ivB64 string) ([]byte, error) {
+	return c.DecryptWithAAD(cipherB64, ivB64, nil)
+}
+
+// DecryptWithAAD 同 Decrypt，并校验附加认证数据
+func (c *Cipher) DecryptWithAAD(cipherB64, ivB64 string, aad []byte) ([]byte, error) {
+	cipherTextWithTag, err := base64.StdEncoding.DecodeString(cipherB64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cipher base64 decode failed: %v", ErrInvalidBase64, err)
+	}
+
+	iv, err := base64.StdEncoding.DecodeString(ivB64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: iv base64 decode failed: %v", ErrInvalidBase64, err)
+	}
+
+	return c.OpenWithAAD(iv, cipherTextWithTag, aad)
+}
@@ -0,0 +1,270 @@
+package jsaes
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// EnvelopeV2 当前信封版本，头部参与 AEAD 认证
+const EnvelopeV2 = "v2"
+
+// Algorithm 信封中的内容加密算法（命名沿用 JOSE）
+type Algorithm string
+
+const (
+	A128GCM Algorithm = "A128GCM"
+	A192GCM Algorithm = "A192GCM"
+	A256GCM Algorithm = "A256GCM"
+	C20P    Algorithm = "C20P"  // ChaCha20-Poly1305，12 字节 nonce
+	XC20P   Algorithm = "XC20P" // XChaCha20-Poly1305，24 字节 nonce
+
+	A128GCMSIV Algorithm = "A128GCMSIV" // AES-128-GCM-SIV（RFC 8452），抗 nonce 重用
+	A256GCMSIV Algorithm = "A256GCMSIV" // AES-256-GCM-SIV（RFC 8452），抗 nonce 重用
+)
+
+// gcmAlgorithm 按密钥长度返回对应的 AES-GCM 算法名
+func gcmAlgorithm(keyLen int) Algorithm {
+	switch keyLen {
+	case 16:
+		return A128GCM
+	case 24:
+		return A192GCM
+	case 32:
+		return A256GCM
+	}
+	return ""
+}
+
+// Envelope 自描述的密文信封
+//
+// v2 紧凑格式为 7 段以 "." 分隔的字符串，二进制字段均为 Base64URL（无填充）：
+//
+//	v2.<alg>.<kid>.<kdf>.<salt>.<nonce>.<ciphertext>
+//
+// 其中 kdf 为 "legacy" 或 "<算法>:<k=v,...>"（如 "pbkdf2-sha256:i=600000"）。
+// 密文之前的头部（含末尾的 "."）与调用方的 AAD 拼接后作为 AEAD 附加认证数据，
+// 篡改算法、kid 或 KDF 参数都会导致认证失败。
+//
+// 旧版 "cipherB64|ivB64[|kdfParams]" 格式（Version 为空串）仍可解密，重新加密时保持原格式。
+type Envelope struct {
+	Version    string
+	Algorithm  Algorithm
+	KeyID      string
+	KDF        *KDFParams // nil 表示旧版补齐逻辑
+	Nonce      []byte
+	Ciphertext []byte
+}
+
+// IsEnvelope 判断是否为 v2 信封（而非旧版 | 分隔格式）
+func IsEnvelope(s string) bool {
+	return strings.HasPrefix(s, EnvelopeV2+".")
+}
+
+// ParseEnvelope 解析 v2 信封或旧版 cipherB64|ivB64[|kdfParams] 字符串
+func ParseEnvelope(s string) (*Envelope, error) {
+	if !IsEnvelope(s) {
+		return parseLegacy(s)
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) != 7 {
+		return nil, fmt.Errorf("%w: expected 7 segments, got %d", ErrInvalidEnvelope, len(parts))
+	}
+
+	e := &Envelope{
+		Version:   parts[0],
+		Algorithm: Algorithm(parts[1]),
+		KeyID:     parts[2],
+	}
+	if err := validKeyID(e.KeyID); err != nil {
+		return nil, err
+	}
+
+	var err error
+	var salt []byte
+	fields := []struct {
+		name string
+		dst  *[]byte
+		src  string
+	}{
+		{"salt", &salt, parts[4]},
+		{"nonce", &e.Nonce, parts[5]},
+		{"ciphertext", &e.Ciphertext, parts[6]},
+	}
+	for _, f := range fields {
+		if *f.dst, err = base64.RawURLEncoding.DecodeString(f.src); err != nil {
+			return nil, fmt.Errorf("%w: %s base64 decode failed: %v", ErrInvalidEnvelope, f.name, err)
+		}
+	}
+
+	alg, params, _ := strings.Cut(parts[3], ":")
+	if e.KDF, err = parseKDF(KDFAlgorithm(alg), params, salt); err != nil {
+		return nil, err
+	}
+
+	switch e.Algorithm {
+	case A128GCM, A192GCM, A256GCM, C20P, XC20P, A128GCMSIV, A256GCMSIV:
+	default:
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidEnvelope, e.Algorithm)
+	}
+	return e, nil
+}
+
+// parseLegacy 解析旧版 cipherB64|ivB64[|kdfParams] 格式
+func parseLegacy(s string) (*Envelope, error) {
+	parts := strings.Split(s, "|")
+	if len(parts) != 2 && len(parts) != 3 {
+		return nil, fmt.Errorf("%w: invalid encrypted data format", ErrInvalidEnvelope)
+	}
+
+	e := &Envelope{}
+	var err error
+	if e.Ciphertext, err = base64.StdEncoding.DecodeString(parts[0]); err != nil {
+		return nil, fmt.Errorf("%w: cipher base64 decode failed: %v", ErrInvalidBase64, err)
+	}
+	if e.Nonce, err = base64.StdEncoding.DecodeString(parts[1]); err != nil {
+		return nil, fmt.Errorf("%w: iv base64 decode failed: %v", ErrInvalidBase64, err)
+	}
+	if len(parts) == 3 {
+		if e.KDF, err = ParseKDFParams(parts[2]); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// validKeyID kid 只允许 Base64URL 字符，避免与分隔符冲突
+func validKeyID(kid string) error {
+	for _, c := range kid {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return fmt.Errorf("%w: invalid character %q in kid", ErrInvalidEnvelope, c)
+		}
+	}
+	return nil
+}
+
+// String 序列化信封；旧版信封按原 | 分隔格式输出
+func (e *Envelope) String() string {
+	if e.Version == "" {
+		s := base64.StdEncoding.EncodeToString(e.Ciphertext) + "|" + base64.StdEncoding.EncodeToString(e.Nonce)
+		if e.KDF != nil {
+			s += "|" + e.KDF.String()
+		}
+		return s
+	}
+
+	return e.header() + base64.RawURLEncoding.EncodeToString(e.Ciphertext)
+}
+
+// header 返回密文之前的头部（含末尾的 "."），v2 信封将其作为 AAD 前缀参与认证
+func (e *Envelope) header() string {
+	kdf := string(KDFLegacyPadding)
+	var salt []byte
+	if e.KDF != nil {
+		kdf = string(e.KDF.Algorithm)
+		if params := e.KDF.params(); params != "" {
+			kdf += ":" + params
+		}
+		salt = e.KDF.Salt
+	}
+
+	return strings.Join([]string{
+		e.Version,
+		string(e.Algorithm),
+		e.KeyID,
+		kdf,
+		base64.RawURLEncoding.EncodeToString(salt),
+		base64.RawURLEncoding.EncodeToString(e.Nonce),
+	}, ".") + "."
+}
+
+// authData 返回实际传给 AEAD 的附加认证数据：v2 为头部 + 调用方 AAD，其余格式为调用方 AAD
+//
+// 头部段数固定且不含调用方数据，头部与 AAD 的边界无歧义。
+func (e *Envelope) authData(aad []byte) []byte {
+	if e.Version != EnvelopeV2 {
+		return aad
+	}
+	return append([]byte(e.header()), aad...)
+}
+
+// cipher 从口令派生密钥并创建 Cipher，同时校验密钥长度与信封声明的算法一致
+func (e *Envelope) cipher(password []byte) (*Cipher, error) {
+	key, err := deriveKey(password, e.KDF)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.Version == "" {
+		return NewCipher(key)
+	}
+	return NewCipherWithAlgorithm(e.Algorithm, key)
+}
+
+// Open 使用口令解密信封；aad 须与加密时一致
+func (e *Envelope) Open(password, aad []byte) ([]byte, error) {
+	c, err := e.cipher(password)
+	if err != nil {
+		return nil, err
+	}
+	return c.OpenWithAAD(e.Nonce, e.Ciphertext, e.authData(aad))
+}
+
+// Reseal 使用相同的格式、算法、kid 和 KDF 成本参数重新加密，盐和 nonce 重新随机生成
+func (e *Envelope) Reseal(plainText, password, aad []byte) (*Envelope, error) {
+	out := &Envelope{Version: e.Version, Algorithm: e.Algorithm, KeyID: e.KeyID}
+	if e.KDF != nil {
+		kdf, err := e.KDF.WithNewSalt()
+		if err != nil {
+			return nil, err
+		}
+		out.KDF = kdf
+	}
+	return out.seal(plainText, password, aad)
+}
+
+// SealEnvelope 使用口令加密并生成 v2 信封；kdf 为 nil 时使用旧版补齐逻辑，aad 可为空
+func SealEnvelope(plainText, password, aad []byte, kdf *KDFParams, kid string) (*Envelope, error) {
+	return SealEnvelopeWithAlgorithm("", plainText, password, aad, kdf, kid)
+}
+
+// SealEnvelopeWithAlgorithm 同 SealEnvelope，并指定内容加密算法；alg 为空时按密钥长度使用 AES-GCM
+func SealEnvelopeWithAlgorithm(alg Algorithm, plainText, password, aad []byte, kdf *KDFParams, kid string) (*Envelope, error) {
+	if err := validKeyID(kid); err != nil {
+		return nil, err
+	}
+	e := &Envelope{Version: EnvelopeV2, Algorithm: alg, KeyID: kid, KDF: kdf}
+	return e.seal(plainText, password, aad)
+}
+
+// seal 派生密钥、生成随机 nonce 并加密，结果写回 e
+func (e *Envelope) seal(plainText, password, aad []byte) (*Envelope, error) {
+	key, err := deriveKey(password, e.KDF)
+	if err != nil {
+		return nil, err
+	}
+
+	var c *Cipher
+	if e.Version == "" {
+		c, err = NewCipher(key)
+	} else {
+		if e.Algorithm == "" {
+			e.Algorithm = gcmAlgorithm(len(key))
+		}
+		c, err = NewCipherWithAlgorithm(e.Algorithm, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	e.Nonce = make([]byte, c.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, e.Nonce); err != nil {
+		return nil, err
+	}
+	if e.Ciphertext, err = c.SealWithAAD(e.Nonce, plainText, e.authData(aad)); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
@@ -0,0 +1,160 @@
+package jsaes
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	kdf, err := ParseKDFParams("$pbkdf2-sha256$i=1000$" + testSalt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		alg  Algorithm
+		kdf  *KDFParams
+		aad  []byte
+	}{
+		{"legacy key", "", nil, nil},
+		{"legacy key with aad", "", nil, []byte("tenant-1")},
+		{"pbkdf2", "", kdf, nil},
+		{"pbkdf2 chacha", C20P, kdf, []byte("tenant-1")},
+		{"pbkdf2 xchacha", XC20P, kdf, nil},
+		{"pbkdf2 gcm-siv", A256GCMSIV, kdf, []byte("tenant-1")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			password := []byte("password-1234567")
+			e, err := SealEnvelopeWithAlgorithm(tt.alg, []byte("hello"), password, tt.aad, tt.kdf, "user-1")
+			if err != nil {
+				t.Fatalf("SealEnvelopeWithAlgorithm: %v", err)
+			}
+			s := e.String()
+			if !strings.HasPrefix(s, "v2.") || strings.Count(s, ".") != 6 {
+				t.Fatalf("String() = %q, want 7-segment v2 envelope", s)
+			}
+
+			parsed, err := ParseEnvelope(s)
+			if err != nil {
+				t.Fatalf("ParseEnvelope: %v", err)
+			}
+			if parsed.String() != s {
+				t.Errorf("re-serialized = %q, want %q", parsed.String(), s)
+			}
+			got, err := parsed.Open(password, tt.aad)
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			if string(got) != "hello" {
+				t.Fatalf("Open = %q", got)
+			}
+
+			if _, err := parsed.Open(password, []byte("tenant-2")); !errors.Is(err, ErrAuthFailed) {
+				t.Errorf("wrong aad: err = %v, want ErrAuthFailed", err)
+			}
+		})
+	}
+}
+
+func TestEnvelopeHeaderAuthenticated(t *testing.T) {
+	password := bytes.Repeat([]byte{7}, 32)
+	e, err := SealEnvelope([]byte("hello"), password, nil, nil, "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(e.String(), ".")
+
+	tests := []struct {
+		name  string
+		index int
+		value string
+	}{
+		{"kid", 2, "user-2"},
+		{"empty kid", 2, ""},
+		{"algorithm", 1, string(A256GCMSIV)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered := append([]string(nil), parts...)
+			tampered[tt.index] = tt.value
+			parsed, err := ParseEnvelope(strings.Join(tampered, "."))
+			if err != nil {
+				t.Fatalf("ParseEnvelope: %v", err)
+			}
+			if _, err := parsed.Open(password, nil); !errors.Is(err, ErrAuthFailed) {
+				t.Errorf("tampered %s: err = %v, want ErrAuthFailed", tt.name, err)
+			}
+		})
+	}
+}
+
+func TestEnvelopeKDFParamsAuthenticated(t *testing.T) {
+	kdf, err := ParseKDFParams("$pbkdf2-sha256$i=1000$" + testSalt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	password := []byte("password")
+	e, err := SealEnvelope([]byte("hello"), password, nil, kdf, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 改动 KDF 参数后派生出的密钥不同，但即便密钥碰巧相同，头部认证也会失败
+	s := strings.Replace(e.String(), "i=1000", "i=1001", 1)
+	parsed, err := ParseEnvelope(s)
+	if err != nil {
+		t.Fatalf("ParseEnvelope: %v", err)
+	}
+	if _, err := parsed.Open(password, nil); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("tampered kdf: err = %v, want ErrAuthFailed", err)
+	}
+}
+
+func TestEnvelopeLegacyFormat(t *testing.T) {
+	key := []byte("1234567890123456")
+	cipherB64, ivB64, err := AESGCMEncryptForJS([]byte("hi"), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := ParseEnvelope(cipherB64 + "|" + ivB64)
+	if err != nil {
+		t.Fatalf("ParseEnvelope: %v", err)
+	}
+	if e.Version != "" {
+		t.Fatalf("Version = %q, want legacy", e.Version)
+	}
+	got, err := e.Open(key, nil)
+	if err != nil || string(got) != "hi" {
+		t.Fatalf("Open = %q, %v", got, err)
+	}
+
+	resealed, err := e.Reseal(got, key, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(resealed.String(), "|") != 1 {
+		t.Errorf("Reseal changed legacy format: %q", resealed.String())
+	}
+}
+
+func TestParseEnvelopeRejects(t *testing.T) {
+	tests := []string{
+		"",
+		"only-one-part",
+		"a|b|c|d",
+		"v2.A256GCM.kid.legacy....extra",
+		"v2.A512GCM.kid.legacy..AAAAAAAAAAAAAAAA.AAAA",
+		"v2.A256GCM.k!d.legacy..AAAAAAAAAAAAAAAA.AAAA",
+		"v2.A256GCM.kid.legacy..AAAAAAAAAAAAAAAA.!!!!",
+		"v2.A256GCM.kid.bcrypt:i=1..AAAAAAAAAAAAAAAA.AAAA",
+		"v1.A256GCM.kid.legacy..AAAAAAAAAAAAAAAA.AAAA",
+	}
+	for _, s := range tests {
+		if _, err := ParseEnvelope(s); err == nil {
+			t.Errorf("ParseEnvelope(%q) succeeded, want error", s)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package jsaes
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// gcmSIVNonceSize / gcmSIVTagSize AES-GCM-SIV 的 nonce 与标签长度（RFC 8452 §4）
+const (
+	gcmSIVNonceSize = 12
+	gcmSIVTagSize   = 16
+)
+
+// errGCMSIVOpen 认证失败，与 crypto/cipher 的 GCM 保持相同措辞
+var errGCMSIVOpen = errors.New("cipher: message authentication failed")
+
+// gcmSIV 实现 AES-GCM-SIV（RFC 8452）的 cipher.AEAD
+//
+// 与 AES-GCM 不同，同一密钥下重复使用 nonce 只会暴露两条消息是否完全相同，
+// 不会泄露认证密钥；代价是加密需要对正文做两遍处理。
+type gcmSIV struct {
+	keyGen cipher.Block // 密钥生成密钥，用于按 nonce 派生每条消息的认证密钥和加密密钥
+	keyLen int
+}
+
+// NewAESGCMSIV 使用 16 或 32 字节密钥创建 AES-GCM-SIV（RFC 8452）Cipher，nonce 为 12 字节；
+// 适合无法保证随机数质量的客户端
+func NewAESGCMSIV(key []byte) (*Cipher, error) {
+	var alg Algorithm
+	switch len(key) {
+	case 16:
+		alg = A128GCMSIV
+	case 32:
+		alg = A256GCMSIV
+	default:
+		return nil, fmt.Errorf("%w: AES-GCM-SIV 密钥长度必须是16/32字节，实际长度: %d", ErrInvalidKeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: &gcmSIV{keyGen: block, keyLen: len(key)}, alg: alg}, nil
+}
+
+func (g *gcmSIV) NonceSize() int { return gcmSIVNonceSize }
+
+func (g *gcmSIV) Overhead() int { return gcmSIVTagSize }
+
+// deriveKeys 按 RFC 8452 §4 由 nonce 派生消息认证密钥和消息加密密钥
+func (g *gcmSIV) deriveKeys(nonce []byte) ([]byte, cipher.Block) {
+	// 每个计数器块加密后取前 8 字节：前 2 段为认证密钥，其余为加密密钥
+	n := 2 + g.keyLen/8
+	keys := make([]byte, 0, n*8)
+	var in, out [aes.BlockSize]byte
+	copy(in[4:], nonce)
+	for i := 0; i < n; i++ {
+		binary.LittleEndian.PutUint32(in[:4], uint32(i))
+		g.keyGen.Encrypt(out[:], in[:])
+		keys = append(keys, out[:8]...)
+	}
+
+	encBlock, err := aes.NewCipher(keys[16:])
+	if err != nil {
+		panic("jsaes: invalid derived AES-GCM-SIV key: " + err.Error())
+	}
+	return keys[:16], encBlock
+}
+
+// tag 计算 POLYVAL 并加密得到认证标签
+func (g *gcmSIV) tag(authKey []byte, encBlock cipher.Block, nonce, plainText, aad []byte) [gcmSIVTagSize]byte {
+	var lengths [16]byte
+	binary.LittleEndian.PutUint64(lengths[:8], uint64(len(aad))*8)
+	binary.LittleEndian.PutUint64(lengths[8:], uint64(len(plainText))*8)
+
+	p := newPolyval(authKey)
+	p.update(aad)
+	p.update(plainText)
+	p.update(lengths[:])
+	s := p.sum()
+
+	for i := range nonce {
+		s[i] ^= nonce[i]
+	}
+	s[15] &= 0x7f
+
+	var t [gcmSIVTagSize]byte
+	encBlock.Encrypt(t[:], s[:])
+	return t
+}
+
+// ctr 以标签（最高位置 1）为初始计数器块做 AES-CTR，计数器为前 4 字节小端序
+func ctr(encBlock cipher.Block, tag []byte, dst, src []byte) {
+	var counter, keystream [aes.BlockSize]byte
+	copy(counter[:], tag)
+	counter[15] |= 0x80
+
+	for len(src) > 0 {
+		encBlock.Encrypt(keystream[:], counter[:])
+		binary.LittleEndian.PutUint32(counter[:4], binary.LittleEndian.Uint32(counter[:4])+1)
+		n := subtle.XORBytes(dst, src, keystream[:])
+		dst, src = dst[n:], src[n:]
+	}
+}
+
+func (g *gcmSIV) Seal(dst, nonce, plainText, additionalData []byte) []byte {
+	if len(nonce) != gcmSIVNonceSize {
+		panic("jsaes: incorrect nonce length given to AES-GCM-SIV")
+	}
+
+	authKey, encBlock := g.deriveKeys(nonce)
+	t := g.tag(authKey, encBlock, nonce, plainText, additionalData)
+
+	ret, out := sliceForAppend(dst, len(plainText)+gcmSIVTagSize)
+	ctr(encBlock, t[:], out, plainText)
+	copy(out[len(plainText):], t[:])
+	return ret
+}
+
+func (g *gcmSIV) Open(dst, nonce, cipherText, additionalData []byte) ([]byte, error) {
+	if len(nonce) != gcmSIVNonceSize {
+		panic("jsaes: incorrect nonce length given to AES-GCM-SIV")
+	}
+	if len(cipherText) < gcmSIVTagSize {
+		return nil, errGCMSIVOpen
+	}
+
+	body, t := cipherText[:len(cipherText)-gcmSIVTagSize], cipherText[len(cipherText)-gcmSIVTagSize:]
+	authKey, encBlock := g.deriveKeys(nonce)
+
+	ret, out := sliceForAppend(dst, len(body))
+	ctr(encBlock, t, out, body)
+
+	expected := g.tag(authKey, encBlock, nonce, out, additionalData)
+	if subtle.ConstantTimeCompare(expected[:], t) != 1 {
+		clear(out)
+		return nil, errGCMSIVOpen
+	}
+	return ret, nil
+}
+
+// sliceForAppend 扩展 in 并返回整体切片与新增部分（同 crypto/cipher 内部实现）
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	tail = head[len(in):]
+	return
+}
+
+// fieldElement GHASH 表示下的 GF(2^128) 元素（大端位序）
+type fieldElement struct {
+	hi, lo uint64
+}
+
+// mulX 乘以 x（GHASH 位序下为右移一位，溢出时用 R = 0xe1 || 0^120 约简）
+func (a fieldElement) mulX() fieldElement {
+	mask := -(a.lo & 1)
+	return fieldElement{
+		hi: a.hi>>1 ^ 0xe100000000000000&mask,
+		lo: a.lo>>1 | a.hi<<63,
+	}
+}
+
+// mul GHASH 域乘法（NIST SP 800-38D 算法 1），按位掩码实现，不依赖数据分支
+func (a fieldElement) mul(b fieldElement) fieldElement {
+	var z fieldElement
+	v := b
+	for i := 0; i < 128; i++ {
+		var bit uint64
+		if i < 64 {
+			bit = a.hi >> (63 - i) & 1
+		} else {
+			bit = a.lo >> (127 - i) & 1
+		}
+		mask := -bit
+		z.hi ^= v.hi & mask
+		z.lo ^= v.lo & mask
+		v = v.mulX()
+	}
+	return z
+}
+
+// polyval 通过 GHASH 计算 POLYVAL（RFC 8452 附录 A）：
+// POLYVAL(H, X) = ByteReverse(GHASH(mulX_GHASH(ByteReverse(H)), ByteReverse(X)))
+type polyval struct {
+	h, s fieldElement
+}
+
+// reversedElement 字节反转 16 字节块后按大端读取，等价于按小端读取两半
+func reversedElement(b []byte) fieldElement {
+	return fieldElement{
+		hi: binary.LittleEndian.Uint64(b[8:16]),
+		lo: binary.LittleEndian.Uint64(b[0:8]),
+	}
+}
+
+func newPolyval(key []byte) *polyval {
+	return &polyval{h: reversedElement(key).mulX()}
+}
+
+// update 输入数据，末尾不足 16 字节的部分补零
+func (p *polyval) update(data []byte) {
+	var block [16]byte
+	for len(data) > 0 {
+		n := copy(block[:], data)
+		clear(block[n:])
+		x := reversedElement(block[:])
+		p.s.hi ^= x.hi
+		p.s.lo ^= x.lo
+		p.s = p.s.mul(p.h)
+		data = data[n:]
+	}
+}
+
+func (p *polyval) sum() [16]byte {
+	var out [16]byte
+	binary.LittleEndian.PutUint64(out[0:8], p.s.lo)
+	binary.LittleEndian.PutUint64(out[8:16], p.s.hi)
+	return out
+}
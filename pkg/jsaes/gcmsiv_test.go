@@ -0,0 +1,368 @@
+package jsaes
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// gcmSIVVectors RFC 8452 附录 C.1（AEAD_AES_128_GCM_SIV）、C.2（AEAD_AES_256_GCM_SIV）
+// 和 C.3（计数器回绕）的测试向量，取自 Wycheproof aes_gcm_siv_test.json 中标注 RFC 8452 的用例，
+// name 中的数字为 Wycheproof tcId；result 为密文 + 16 字节标签
+var gcmSIVVectors = []struct {
+	name, key, nonce, aad, plainText, result string
+}{
+	{
+		name:      "C.1/1",
+		key:       "01000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "",
+		plainText: "",
+		result:    "dc20e2d83f25705bb49e439eca56de25",
+	},
+	{
+		name:      "C.1/2",
+		key:       "01000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "",
+		plainText: "0100000000000000",
+		result:    "b5d839330ac7b786578782fff6013b815b287c22493a364c",
+	},
+	{
+		name:      "C.1/3",
+		key:       "01000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "",
+		plainText: "010000000000000000000000",
+		result:    "7323ea61d05932260047d942a4978db357391a0bc4fdec8b0d106639",
+	},
+	{
+		name:      "C.1/4",
+		key:       "01000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "",
+		plainText: "01000000000000000000000000000000",
+		result:    "743f7c8077ab25f8624e2e948579cf77303aaf90f6fe21199c6068577437a0c4",
+	},
+	{
+		name:      "C.1/5",
+		key:       "01000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "",
+		plainText: "0100000000000000000000000000000002000000000000000000000000000000",
+		result:    "84e07e62ba83a6585417245d7ec413a9fe427d6315c09b57ce45f2e3936a94451a8e45dcd4578c667cd86847bf6155ff",
+	},
+	{
+		name:      "C.1/6",
+		key:       "01000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "",
+		plainText: "010000000000000000000000000000000200000000000000000000000000000003000000000000000000000000000000",
+		result:    "3fd24ce1f5a67b75bf2351f181a475c7b800a5b4d3dcf70106b1eea82fa1d64df42bf7226122fa92e17a40eeaac1201b5e6e311dbf395d35b0fe39c2714388f8",
+	},
+	{
+		name:      "C.1/7",
+		key:       "01000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "",
+		plainText: "01000000000000000000000000000000020000000000000000000000000000000300000000000000000000000000000004000000000000000000000000000000",
+		result:    "2433668f1058190f6d43e360f4f35cd8e475127cfca7028ea8ab5c20f7ab2af02516a2bdcbc08d521be37ff28c152bba36697f25b4cd169c6590d1dd39566d3f8a263dd317aa88d56bdf3936dba75bb8",
+	},
+	{
+		name:      "C.1/8",
+		key:       "01000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "01",
+		plainText: "0200000000000000",
+		result:    "1e6daba35669f4273b0a1a2560969cdf790d99759abd1508",
+	},
+	{
+		name:      "C.1/9",
+		key:       "01000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "01",
+		plainText: "020000000000000000000000",
+		result:    "296c7889fd99f41917f4462008299c5102745aaa3a0c469fad9e075a",
+	},
+	{
+		name:      "C.1/10",
+		key:       "01000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "01",
+		plainText: "02000000000000000000000000000000",
+		result:    "e2b0c5da79a901c1745f700525cb335b8f8936ec039e4e4bb97ebd8c4457441f",
+	},
+	{
+		name:      "C.1/11",
+		key:       "01000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "01",
+		plainText: "0200000000000000000000000000000003000000000000000000000000000000",
+		result:    "620048ef3c1e73e57e02bb8562c416a319e73e4caac8e96a1ecb2933145a1d71e6af6a7f87287da059a71684ed3498e1",
+	},
+	{
+		name:      "C.1/12",
+		key:       "01000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "01",
+		plainText: "020000000000000000000000000000000300000000000000000000000000000004000000000000000000000000000000",
+		result:    "50c8303ea93925d64090d07bd109dfd9515a5a33431019c17d93465999a8b0053201d723120a8562b838cdff25bf9d1e6a8cc3865f76897c2e4b245cf31c51f2",
+	},
+	{
+		name:      "C.1/13",
+		key:       "01000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "01",
+		plainText: "02000000000000000000000000000000030000000000000000000000000000000400000000000000000000000000000005000000000000000000000000000000",
+		result:    "2f5c64059db55ee0fb847ed513003746aca4e61c711b5de2e7a77ffd02da42feec601910d3467bb8b36ebbaebce5fba30d36c95f48a3e7980f0e7ac299332a80cdc46ae475563de037001ef84ae21744",
+	},
+	{
+		name:      "C.1/14",
+		key:       "01000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "010000000000000000000000",
+		plainText: "02000000",
+		result:    "a8fe3e8707eb1f84fb28f8cb73de8e99e2f48a14",
+	},
+	{
+		name:      "C.1/15",
+		key:       "01000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "010000000000000000000000000000000200",
+		plainText: "0300000000000000000000000000000004000000",
+		result:    "6bb0fecf5ded9b77f902c7d5da236a4391dd029724afc9805e976f451e6d87f6fe106514",
+	},
+	{
+		name:      "C.1/16",
+		key:       "01000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "0100000000000000000000000000000002000000",
+		plainText: "030000000000000000000000000000000400",
+		result:    "44d0aaf6fb2f1f34add5e8064e83e12a2adabff9b2ef00fb47920cc72a0c0f13b9fd",
+	},
+	{
+		name:      "C.2/100",
+		key:       "0100000000000000000000000000000000000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "",
+		plainText: "",
+		result:    "07f5f4169bbf55a8400cd47ea6fd400f",
+	},
+	{
+		name:      "C.2/101",
+		key:       "0100000000000000000000000000000000000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "",
+		plainText: "0100000000000000",
+		result:    "c2ef328e5c71c83b843122130f7364b761e0b97427e3df28",
+	},
+	{
+		name:      "C.2/102",
+		key:       "0100000000000000000000000000000000000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "",
+		plainText: "010000000000000000000000",
+		result:    "9aab2aeb3faa0a34aea8e2b18ca50da9ae6559e48fd10f6e5c9ca17e",
+	},
+	{
+		name:      "C.2/103",
+		key:       "0100000000000000000000000000000000000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "",
+		plainText: "01000000000000000000000000000000",
+		result:    "85a01b63025ba19b7fd3ddfc033b3e76c9eac6fa700942702e90862383c6c366",
+	},
+	{
+		name:      "C.2/104",
+		key:       "0100000000000000000000000000000000000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "",
+		plainText: "0100000000000000000000000000000002000000000000000000000000000000",
+		result:    "4a6a9db4c8c6549201b9edb53006cba821ec9cf850948a7c86c68ac7539d027fe819e63abcd020b006a976397632eb5d",
+	},
+	{
+		name:      "C.2/105",
+		key:       "0100000000000000000000000000000000000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "",
+		plainText: "010000000000000000000000000000000200000000000000000000000000000003000000000000000000000000000000",
+		result:    "c00d121893a9fa603f48ccc1ca3c57ce7499245ea0046db16c53c7c66fe717e39cf6c748837b61f6ee3adcee17534ed5790bc96880a99ba804bd12c0e6a22cc4",
+	},
+	{
+		name:      "C.2/106",
+		key:       "0100000000000000000000000000000000000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "",
+		plainText: "01000000000000000000000000000000020000000000000000000000000000000300000000000000000000000000000004000000000000000000000000000000",
+		result:    "c2d5160a1f8683834910acdafc41fbb1632d4a353e8b905ec9a5499ac34f96c7e1049eb080883891a4db8caaa1f99dd004d80487540735234e3744512c6f90ce112864c269fc0d9d88c61fa47e39aa08",
+	},
+	{
+		name:      "C.2/107",
+		key:       "0100000000000000000000000000000000000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "01",
+		plainText: "0200000000000000",
+		result:    "1de22967237a813291213f267e3b452f02d01ae33e4ec854",
+	},
+	{
+		name:      "C.2/108",
+		key:       "0100000000000000000000000000000000000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "01",
+		plainText: "020000000000000000000000",
+		result:    "163d6f9cc1b346cd453a2e4cc1a4a19ae800941ccdc57cc8413c277f",
+	},
+	{
+		name:      "C.2/109",
+		key:       "0100000000000000000000000000000000000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "01",
+		plainText: "02000000000000000000000000000000",
+		result:    "c91545823cc24f17dbb0e9e807d5ec17b292d28ff61189e8e49f3875ef91aff7",
+	},
+	{
+		name:      "C.2/110",
+		key:       "0100000000000000000000000000000000000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "01",
+		plainText: "0200000000000000000000000000000003000000000000000000000000000000",
+		result:    "07dad364bfc2b9da89116d7bef6daaaf6f255510aa654f920ac81b94e8bad365aea1bad12702e1965604374aab96dbbc",
+	},
+	{
+		name:      "C.2/111",
+		key:       "0100000000000000000000000000000000000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "01",
+		plainText: "020000000000000000000000000000000300000000000000000000000000000004000000000000000000000000000000",
+		result:    "c67a1f0f567a5198aa1fcc8e3f21314336f7f51ca8b1af61feac35a86416fa47fbca3b5f749cdf564527f2314f42fe2503332742b228c647173616cfd44c54eb",
+	},
+	{
+		name:      "C.2/112",
+		key:       "0100000000000000000000000000000000000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "01",
+		plainText: "02000000000000000000000000000000030000000000000000000000000000000400000000000000000000000000000005000000000000000000000000000000",
+		result:    "67fd45e126bfb9a79930c43aad2d36967d3f0e4d217c1e551f59727870beefc98cb933a8fce9de887b1e40799988db1fc3f91880ed405b2dd298318858467c895bde0285037c5de81e5b570a049b62a0",
+	},
+	{
+		name:      "C.2/113",
+		key:       "0100000000000000000000000000000000000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "010000000000000000000000",
+		plainText: "02000000",
+		result:    "22b3f4cd1835e517741dfddccfa07fa4661b74cf",
+	},
+	{
+		name:      "C.2/114",
+		key:       "0100000000000000000000000000000000000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "010000000000000000000000000000000200",
+		plainText: "0300000000000000000000000000000004000000",
+		result:    "43dd0163cdb48f9fe3212bf61b201976067f342bb879ad976d8242acc188ab59cabfe307",
+	},
+	{
+		name:      "C.2/115",
+		key:       "0100000000000000000000000000000000000000000000000000000000000000",
+		nonce:     "030000000000000000000000",
+		aad:       "0100000000000000000000000000000002000000",
+		plainText: "030000000000000000000000000000000400",
+		result:    "462401724b5ce6588d5a54aae5375513a075cfcdf5042112aa29685c912fc2056543",
+	},
+	{
+		name:      "C.3/124",
+		key:       "0000000000000000000000000000000000000000000000000000000000000000",
+		nonce:     "000000000000000000000000",
+		aad:       "",
+		plainText: "000000000000000000000000000000004db923dc793ee6497c76dcc03a98e108",
+		result:    "f3f80f2cf0cb2dd9c5984fcda908456cc537703b5ba70324a6793a7bf218d3eaffffffff000000000000000000000000",
+	},
+	{
+		name:      "C.3/125",
+		key:       "0000000000000000000000000000000000000000000000000000000000000000",
+		nonce:     "000000000000000000000000",
+		aad:       "",
+		plainText: "eb3640277c7ffd1303c7a542d02d3e4c0000000000000000",
+		result:    "18ce4f0b8cb4d0cac65fea8f79257b20888e53e72299e56dffffffff000000000000000000000000",
+	},
+}
+
+func decodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestGCMSIVVectors(t *testing.T) {
+	for _, v := range gcmSIVVectors {
+		t.Run(v.name, func(t *testing.T) {
+			c, err := NewAESGCMSIV(decodeHex(t, v.key))
+			if err != nil {
+				t.Fatalf("NewAESGCMSIV: %v", err)
+			}
+			nonce, aad := decodeHex(t, v.nonce), decodeHex(t, v.aad)
+			plainText, result := decodeHex(t, v.plainText), decodeHex(t, v.result)
+
+			sealed, err := c.SealWithAAD(nonce, plainText, aad)
+			if err != nil {
+				t.Fatalf("SealWithAAD: %v", err)
+			}
+			if !bytes.Equal(sealed, result) {
+				t.Errorf("SealWithAAD = %x, want %x", sealed, result)
+			}
+
+			opened, err := c.OpenWithAAD(nonce, result, aad)
+			if err != nil {
+				t.Fatalf("OpenWithAAD: %v", err)
+			}
+			if !bytes.Equal(opened, plainText) {
+				t.Errorf("OpenWithAAD = %x, want %x", opened, plainText)
+			}
+		})
+	}
+}
+
+func TestGCMSIVTamper(t *testing.T) {
+	for _, v := range gcmSIVVectors {
+		t.Run(v.name, func(t *testing.T) {
+			c, err := NewAESGCMSIV(decodeHex(t, v.key))
+			if err != nil {
+				t.Fatal(err)
+			}
+			nonce, aad, result := decodeHex(t, v.nonce), decodeHex(t, v.aad), decodeHex(t, v.result)
+
+			for _, i := range []int{0, len(result) - 1} {
+				tampered := bytes.Clone(result)
+				tampered[i] ^= 0x80
+				if _, err := c.OpenWithAAD(nonce, tampered, aad); !errors.Is(err, ErrAuthFailed) {
+					t.Errorf("flip byte %d: err = %v, want ErrAuthFailed", i, err)
+				}
+			}
+
+			otherNonce := bytes.Clone(nonce)
+			otherNonce[0] ^= 1
+			if _, err := c.OpenWithAAD(otherNonce, result, aad); !errors.Is(err, ErrAuthFailed) {
+				t.Errorf("wrong nonce: err = %v, want ErrAuthFailed", err)
+			}
+			if _, err := c.OpenWithAAD(nonce, result, append(bytes.Clone(aad), 0)); !errors.Is(err, ErrAuthFailed) {
+				t.Errorf("extra aad byte: err = %v, want ErrAuthFailed", err)
+			}
+		})
+	}
+}
+
+func TestGCMSIVRejectsShortInput(t *testing.T) {
+	c, err := NewAESGCMSIV(make([]byte, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.OpenWithAAD(make([]byte, 12), make([]byte, 15), nil); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("short ciphertext: err = %v, want ErrAuthFailed", err)
+	}
+	if _, err := NewAESGCMSIV(make([]byte, 24)); !errors.Is(err, ErrInvalidKeySize) {
+		t.Errorf("24-byte key: err = %v, want ErrInvalidKeySize", err)
+	}
+}
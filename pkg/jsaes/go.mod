@@ -0,0 +1,7 @@
+module github.com/LeeeeeeM/aes-go-js/pkg/jsaes
+
+go 1.24.3
+
+require golang.org/x/crypto v0.45.0
+
+require golang.org/x/sys v0.38.0 // indirect
@@ -0,0 +1,158 @@
+// Package jsaes 提供与前端 node-forge AES-GCM 实现互通的 Go 加解密工具。
+//
+// 密文格式与 JS 端保持一致：密文 + 16 字节认证标签整体做 Base64，
+// IV 为 12 字节随机数单独做 Base64。standalone backend 与 Vercel handler
+// 都应通过本包进行 AES-GCM 运算，避免两边实现不一致。
+//
+// # 附加认证数据（AAD）
+//
+// AAD 不会被加密，但参与认证标签计算，用于把密文绑定到用户 id、租户或请求路径等上下文。
+// 密文被复制到其他上下文（AAD 不同）时解密会以 ErrAuthFailed 失败。
+// Go 端通过 WithAAD、Cipher.SealWithAAD/OpenWithAAD 或 Envelope 的 aad 参数传入，
+// 对应 node-forge 的 additionalData 选项（需为 UTF-8 编码后的二进制字符串）：
+//
+//	const aad = forge.util.encodeUtf8('tenant-1/user-42')
+//	cipher.start({ iv, additionalData: aad })
+//	decipher.start({ iv, tag, additionalData: aad })
+//
+// # 算法
+//
+// 默认算法为 AES-GCM（密钥长度决定 A128GCM/A192GCM/A256GCM）。没有 AES 硬件加速的客户端
+// 可选择 ChaCha20-Poly1305（C20P），需要大量随机 nonce 的客户端可选择 XChaCha20-Poly1305
+// （XC20P，24 字节 nonce）；两者都要求 32 字节密钥，口令应配合 KDF 使用。
+// 无法保证随机数质量的客户端可选择 AES-GCM-SIV（A128GCMSIV/A256GCMSIV，RFC 8452），
+// 重复的 nonce 只会暴露明文是否相同，而不会像 AES-GCM 那样泄露认证密钥。
+// 通过 WithAlgorithm、NewCipherWithAlgorithm 或信封的 alg 字段选择。
+//
+// # 版本
+//
+// 本包是独立的 Go 模块 github.com/LeeeeeeM/aes-go-js/pkg/jsaes，通过 pkg/jsaes/vX.Y.Z
+// 形式的 git tag 发布语义化版本：
+//
+//	go get github.com/LeeeeeeM/aes-go-js/pkg/jsaes@v1.5.0
+package jsaes
+
+import "errors"
+
+// 哨兵错误，调用方可通过 errors.Is 判断失败原因
+var (
+	// ErrInvalidKeySize 密钥长度不是 16/24/32 字节
+	ErrInvalidKeySize = errors.New("jsaes: invalid key size")
+	// ErrInvalidIVSize IV 长度与 GCM nonce 长度不符
+	ErrInvalidIVSize = errors.New("jsaes: invalid iv size")
+	// ErrInvalidBase64 密文或 IV 不是合法的 Base64
+	ErrInvalidBase64 = errors.New("jsaes: invalid base64")
+	// ErrAuthFailed 认证标签校验失败（密钥错误或密文被篡改）
+	ErrAuthFailed = errors.New("jsaes: message authentication failed")
+	// ErrInvalidKDFParams 口令派生参数非法或超出允许范围
+	ErrInvalidKDFParams = errors.New("jsaes: invalid kdf parameters")
+	// ErrInvalidPassword 口令含 NUL 字节（KDF 模式下不允许）
+	ErrInvalidPassword = errors.New("jsaes: password must not contain NUL bytes")
+	// ErrInvalidEnvelope 密文信封格式错误或版本、算法不受支持
+	ErrInvalidEnvelope = errors.New("jsaes: invalid envelope")
+	// ErrUnsupportedAlgorithm 算法名不受支持
+	ErrUnsupportedAlgorithm = errors.New("jsaes: unsupported algorithm")
+)
+
+// Option 调整 AESGCMDecryptFromJS / AESGCMEncryptForJS 的密钥处理方式
+type Option func(*options)
+
+type options struct {
+	kdf     *KDFParams
+	kdfSpec string
+	aad     []byte
+	alg     Algorithm
+}
+
+// WithKDF 使用给定参数从口令派生密钥；nil 表示旧版补齐逻辑
+func WithKDF(p *KDFParams) Option {
+	return func(o *options) { o.kdf = p }
+}
+
+// WithKDFSpec 使用随密文传输的参数串（见 KDFParams.String）派生密钥；
+// 空串表示旧版密文，按旧版补齐逻辑处理
+func WithKDFSpec(spec string) Option {
+	return func(o *options) { o.kdfSpec = spec }
+}
+
+// WithAAD 绑定附加认证数据，加解密两端必须一致
+func WithAAD(aad []byte) Option {
+	return func(o *options) { o.aad = aad }
+}
+
+// WithAlgorithm 指定算法（如 C20P、XC20P）；未指定时按密钥长度使用 AES-GCM
+func WithAlgorithm(alg Algorithm) Option {
+	return func(o *options) { o.alg = alg }
+}
+
+// newCipher 根据选项从口令创建 Cipher
+func newCipher(key []byte, opts []Option) (*Cipher, *options, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	kdf := o.kdf
+	if o.kdfSpec != "" {
+		p, err := ParseKDFParams(o.kdfSpec)
+		if err != nil {
+			return nil, nil, err
+		}
+		kdf = p
+	}
+	if o.alg == "" {
+		c, err := NewCipherFromPassword(key, kdf)
+		return c, o, err
+	}
+	derived, err := deriveKey(key, kdf)
+	if err != nil {
+		return nil, nil, err
+	}
+	c, err := NewCipherWithAlgorithm(o.alg, derived)
+	return c, o, err
+}
+
+// PadKey 按前端逻辑调整密钥长度：
+// 不足16字节补\0到16字节，超过32字节截断到32字节，其余非标准长度补\0到32字节
+func PadKey(key []byte) []byte {
+	keyBytes := make([]byte, len(key))
+	copy(keyBytes, key)
+
+	if len(keyBytes) < 16 {
+		// 填充到16字节
+		padding := make([]byte, 16-len(keyBytes))
+		keyBytes = append(keyBytes, padding...)
+	} else if len(keyBytes) > 32 {
+		// 截断到32字节
+		keyBytes = keyBytes[:32]
+	} else if len(keyBytes) != 16 && len(keyBytes) != 24 && len(keyBytes) != 32 {
+		// 填充到32字节
+		padding := make([]byte, 32-len(keyBytes))
+		keyBytes = append(keyBytes, padding...)
+	}
+
+	return keyBytes
+}
+
+// AESGCMDecryptFromJS Go 端解密（解析 JS node-forge 加密的密文）
+//
+// 默认按旧版逻辑补齐密钥；通过 WithKDF / WithKDFSpec 指定口令派生参数，WithAAD 指定附加认证数据，
+// WithAlgorithm 指定 ChaCha20-Poly1305 等非 AES-GCM 算法
+func AESGCMDecryptFromJS(cipherB64, ivB64 string, key []byte, opts ...Option) ([]byte, error) {
+	c, o, err := newCipher(key, opts)
+	if err != nil {
+		return nil, err
+	}
+	return c.DecryptWithAAD(cipherB64, ivB64, o.aad)
+}
+
+// AESGCMEncryptForJS Go 端加密（适配 JS node-forge 的 GCM 格式）
+//
+// 使用 KDF 时调用方需保证每条消息使用新的盐（见 KDFParams.WithNewSalt），并将参数串随密文一并返回
+func AESGCMEncryptForJS(plainText []byte, key []byte, opts ...Option) (string, string, error) {
+	c, o, err := newCipher(key, opts)
+	if err != nil {
+		return "", "", err
+	}
+	return c.EncryptWithAAD(plainText, o.aad)
+}
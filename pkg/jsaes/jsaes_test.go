@@ -0,0 +1,145 @@
+package jsaes
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestCipherRoundTrip(t *testing.T) {
+	tests := []struct {
+		alg    Algorithm
+		keyLen int
+	}{
+		{A128GCM, 16},
+		{A192GCM, 24},
+		{A256GCM, 32},
+		{C20P, 32},
+		{XC20P, 32},
+		{A128GCMSIV, 16},
+		{A256GCMSIV, 32},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.alg), func(t *testing.T) {
+			key := bytes.Repeat([]byte{0x42}, tt.keyLen)
+			c, err := NewCipherWithAlgorithm(tt.alg, key)
+			if err != nil {
+				t.Fatalf("NewCipherWithAlgorithm: %v", err)
+			}
+			if c.Algorithm() != tt.alg {
+				t.Fatalf("Algorithm() = %s, want %s", c.Algorithm(), tt.alg)
+			}
+
+			plainText := []byte("hello, 世界")
+			aad := []byte("tenant-1/user-42")
+			cipherB64, ivB64, err := c.EncryptWithAAD(plainText, aad)
+			if err != nil {
+				t.Fatalf("EncryptWithAAD: %v", err)
+			}
+			got, err := c.DecryptWithAAD(cipherB64, ivB64, aad)
+			if err != nil {
+				t.Fatalf("DecryptWithAAD: %v", err)
+			}
+			if !bytes.Equal(got, plainText) {
+				t.Fatalf("DecryptWithAAD = %q, want %q", got, plainText)
+			}
+
+			if _, err := c.DecryptWithAAD(cipherB64, ivB64, []byte("tenant-2/user-42")); !errors.Is(err, ErrAuthFailed) {
+				t.Errorf("wrong aad: err = %v, want ErrAuthFailed", err)
+			}
+		})
+	}
+}
+
+func TestCipherTamper(t *testing.T) {
+	c, err := NewCipher(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	iv := make([]byte, c.NonceSize())
+	sealed, err := c.Seal(iv, []byte("attack at dawn"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range sealed {
+		tampered := bytes.Clone(sealed)
+		tampered[i] ^= 0x01
+		if _, err := c.Open(iv, tampered); !errors.Is(err, ErrAuthFailed) {
+			t.Fatalf("bit flip at %d: err = %v, want ErrAuthFailed", i, err)
+		}
+	}
+	if _, err := c.Open(iv, sealed[:len(sealed)-1]); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("truncated: err = %v, want ErrAuthFailed", err)
+	}
+
+	other, _ := NewCipher(bytes.Repeat([]byte{2}, 32))
+	if _, err := other.Open(iv, sealed); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("wrong key: err = %v, want ErrAuthFailed", err)
+	}
+	if _, err := c.Open(iv[:8], sealed); !errors.Is(err, ErrInvalidIVSize) {
+		t.Errorf("short iv: err = %v, want ErrInvalidIVSize", err)
+	}
+}
+
+func TestNewCipherKeySize(t *testing.T) {
+	if _, err := NewCipher(make([]byte, 20)); !errors.Is(err, ErrInvalidKeySize) {
+		t.Errorf("NewCipher(20 bytes): err = %v, want ErrInvalidKeySize", err)
+	}
+	if _, err := NewCipherWithAlgorithm(A256GCM, make([]byte, 16)); !errors.Is(err, ErrInvalidKeySize) {
+		t.Errorf("A256GCM with 16-byte key: err = %v, want ErrInvalidKeySize", err)
+	}
+	if _, err := NewCipherWithAlgorithm(C20P, make([]byte, 16)); !errors.Is(err, ErrInvalidKeySize) {
+		t.Errorf("C20P with 16-byte key: err = %v, want ErrInvalidKeySize", err)
+	}
+	if _, err := NewCipherWithAlgorithm("A512GCM", make([]byte, 32)); !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Errorf("unknown algorithm: err = %v, want ErrUnsupportedAlgorithm", err)
+	}
+}
+
+func TestPadKey(t *testing.T) {
+	tests := []struct {
+		in      string
+		wantLen int
+	}{
+		{"", 16},
+		{"short", 16},
+		{"0123456789abcdef", 16},
+		{"0123456789abcdef01", 32},
+		{"0123456789abcdef01234567", 24},
+		{"0123456789abcdef0123456789abcdef", 32},
+		{"0123456789abcdef0123456789abcdef-extra", 32},
+	}
+	for _, tt := range tests {
+		got := PadKey([]byte(tt.in))
+		if len(got) != tt.wantLen {
+			t.Errorf("PadKey(%q) len = %d, want %d", tt.in, len(got), tt.wantLen)
+		}
+		n := min(len(tt.in), tt.wantLen)
+		if !bytes.Equal(got[:n], []byte(tt.in)[:n]) || bytes.ContainsFunc(got[n:], func(r rune) bool { return r != 0 }) {
+			t.Errorf("PadKey(%q) = %q", tt.in, got)
+		}
+	}
+}
+
+func TestJSRoundTrip(t *testing.T) {
+	key := []byte("my-secret-key")
+	cipherB64, ivB64, err := AESGCMEncryptForJS([]byte("payload"), key, WithAAD([]byte("ctx")))
+	if err != nil {
+		t.Fatalf("AESGCMEncryptForJS: %v", err)
+	}
+	got, err := AESGCMDecryptFromJS(cipherB64, ivB64, key, WithAAD([]byte("ctx")))
+	if err != nil {
+		t.Fatalf("AESGCMDecryptFromJS: %v", err)
+	}
+	if string(got) != "payload" {
+		t.Fatalf("AESGCMDecryptFromJS = %q", got)
+	}
+
+	if _, err := AESGCMDecryptFromJS(cipherB64, ivB64, key); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("missing aad: err = %v, want ErrAuthFailed", err)
+	}
+	if _, err := AESGCMDecryptFromJS("!!!", ivB64, key); !errors.Is(err, ErrInvalidBase64) {
+		t.Errorf("bad base64: err = %v, want ErrInvalidBase64", err)
+	}
+}
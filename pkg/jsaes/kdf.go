@@ -0,0 +1,278 @@
+package jsaes
+
+import (
+	"bytes"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// KDFAlgorithm 口令派生算法
+type KDFAlgorithm string
+
+const (
+	// KDFLegacyPadding 旧版行为：口令直接补\0/截断作为密钥（见 PadKey），仅用于兼容旧密文
+	KDFLegacyPadding KDFAlgorithm = "legacy"
+	// KDFPBKDF2SHA256 PBKDF2-HMAC-SHA256
+	KDFPBKDF2SHA256 KDFAlgorithm = "pbkdf2-sha256"
+	// KDFScrypt scrypt
+	KDFScrypt KDFAlgorithm = "scrypt"
+	// KDFArgon2id Argon2id
+	KDFArgon2id KDFAlgorithm = "argon2id"
+)
+
+const (
+	// DerivedKeySize 派生密钥长度（AES-256）
+	DerivedKeySize = 32
+	// SaltSize 默认随机盐长度
+	SaltSize = 16
+
+	// 成本参数上限，防止请求方通过超大参数耗尽服务端资源；
+	// 服务端每个请求会派生两次密钥（解密一次、用新盐重新加密一次），上限按两倍开销取值
+	maxPBKDF2Iterations = 1_000_000
+	maxScryptLogN       = 17
+	maxScryptR          = 32
+	maxScryptP          = 16
+	maxScryptMemory     = 128 << 20 // 字节，scrypt 占用约 128·r·N
+	maxArgon2Memory     = 64 * 1024 // KiB
+	maxArgon2Time       = 4
+	maxArgon2Threads    = 4
+	minSaltSize         = 8
+)
+
+// kdfParamNames 各算法的成本参数名，参数串必须恰好包含这些参数
+var kdfParamNames = map[KDFAlgorithm][]string{
+	KDFPBKDF2SHA256: {"i"},
+	KDFScrypt:       {"ln", "r", "p"},
+	KDFArgon2id:     {"m", "t", "p"},
+}
+
+// KDFParams 口令派生参数，随每条消息一起传输
+//
+// 序列化格式参考 PHC 字符串：$<算法>$<k=v,...>$<盐的 Base64（无填充）>，例如
+//
+//	$pbkdf2-sha256$i=600000$c2FsdHNhbHRzYWx0c2FsdA
+//	$scrypt$ln=15,r=8,p=1$c2FsdHNhbHRzYWx0c2FsdA
+//	$argon2id$m=19456,t=2,p=1$c2FsdHNhbHRzYWx0c2FsdA
+//	$legacy$$
+type KDFParams struct {
+	Algorithm KDFAlgorithm
+	Salt      []byte
+
+	// PBKDF2 迭代次数
+	Iterations int
+
+	// scrypt 参数：N = 2^LogN
+	LogN int
+	R    int
+	P    int
+
+	// Argon2id 参数：内存（KiB）、迭代次数、并行度
+	Memory  uint32
+	Time    uint32
+	Threads uint8
+}
+
+// NewKDFParams 生成指定算法的默认成本参数和随机盐
+func NewKDFParams(alg KDFAlgorithm) (*KDFParams, error) {
+	p := &KDFParams{Algorithm: alg}
+	switch alg {
+	case KDFLegacyPadding:
+		return p, nil
+	case KDFPBKDF2SHA256:
+		p.Iterations = 600_000
+	case KDFScrypt:
+		p.LogN, p.R, p.P = 15, 8, 1
+	case KDFArgon2id:
+		p.Memory, p.Time, p.Threads = 19*1024, 2, 1
+	default:
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidKDFParams, alg)
+	}
+
+	p.Salt = make([]byte, SaltSize)
+	if _, err := io.ReadFull(rand.Reader, p.Salt); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// WithNewSalt 返回成本参数相同、盐重新随机生成的副本（每条消息使用独立的盐）
+func (p *KDFParams) WithNewSalt() (*KDFParams, error) {
+	q := *p
+	if q.Algorithm == KDFLegacyPadding {
+		return &q, nil
+	}
+	q.Salt = make([]byte, SaltSize)
+	if _, err := io.ReadFull(rand.Reader, q.Salt); err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+// ParseKDFParams 解析 KDFParams.String 生成的参数串
+func ParseKDFParams(s string) (*KDFParams, error) {
+	fields := strings.Split(s, "$")
+	if len(fields) != 4 || fields[0] != "" {
+		return nil, fmt.Errorf("%w: malformed parameter string", ErrInvalidKDFParams)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(fields[3])
+	if err != nil {
+		return nil, fmt.Errorf("%w: salt base64 decode failed: %v", ErrInvalidKDFParams, err)
+	}
+	return parseKDF(KDFAlgorithm(fields[1]), fields[2], salt)
+}
+
+// parseKDF 由算法名、k=v 参数列表和盐构造并校验 KDFParams
+func parseKDF(alg KDFAlgorithm, params string, salt []byte) (*KDFParams, error) {
+	p := &KDFParams{Algorithm: alg}
+	if p.Algorithm == KDFLegacyPadding {
+		if params != "" || len(salt) != 0 {
+			return nil, fmt.Errorf("%w: legacy mode takes no parameters", ErrInvalidKDFParams)
+		}
+		return p, nil
+	}
+
+	names, ok := kdfParamNames[p.Algorithm]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidKDFParams, p.Algorithm)
+	}
+
+	values := map[string]int{}
+	for _, kv := range strings.Split(params, ",") {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: malformed parameter %q", ErrInvalidKDFParams, kv)
+		}
+		if !slices.Contains(names, k) {
+			return nil, fmt.Errorf("%w: unknown %s parameter %q", ErrInvalidKDFParams, p.Algorithm, k)
+		}
+		if _, dup := values[k]; dup {
+			return nil, fmt.Errorf("%w: duplicate parameter %q", ErrInvalidKDFParams, k)
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: parameter %s: %v", ErrInvalidKDFParams, k, err)
+		}
+		values[k] = n
+	}
+	if len(values) != len(names) {
+		return nil, fmt.Errorf("%w: %s requires parameters %s", ErrInvalidKDFParams, p.Algorithm, strings.Join(names, ","))
+	}
+	p.Salt = salt
+
+	switch p.Algorithm {
+	case KDFPBKDF2SHA256:
+		p.Iterations = values["i"]
+	case KDFScrypt:
+		p.LogN, p.R, p.P = values["ln"], values["r"], values["p"]
+	case KDFArgon2id:
+		if values["m"] < 0 || values["m"] > maxArgon2Memory || values["t"] < 0 || values["t"] > maxArgon2Time || values["p"] < 0 || values["p"] > maxArgon2Threads {
+			return nil, fmt.Errorf("%w: argon2id parameter out of range", ErrInvalidKDFParams)
+		}
+		p.Memory, p.Time, p.Threads = uint32(values["m"]), uint32(values["t"]), uint8(values["p"])
+	}
+
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// String 序列化为 PHC 风格参数串
+func (p *KDFParams) String() string {
+	return "$" + string(p.Algorithm) + "$" + p.params() + "$" + base64.RawStdEncoding.EncodeToString(p.Salt)
+}
+
+// params 返回不含盐的 k=v 成本参数列表
+func (p *KDFParams) params() string {
+	switch p.Algorithm {
+	case KDFPBKDF2SHA256:
+		return fmt.Sprintf("i=%d", p.Iterations)
+	case KDFScrypt:
+		return fmt.Sprintf("ln=%d,r=%d,p=%d", p.LogN, p.R, p.P)
+	case KDFArgon2id:
+		return fmt.Sprintf("m=%d,t=%d,p=%d", p.Memory, p.Time, p.Threads)
+	}
+	return ""
+}
+
+// validate 校验成本参数与盐长度
+func (p *KDFParams) validate() error {
+	if p.Algorithm == KDFLegacyPadding {
+		return nil
+	}
+	if len(p.Salt) < minSaltSize {
+		return fmt.Errorf("%w: salt must be at least %d bytes", ErrInvalidKDFParams, minSaltSize)
+	}
+
+	switch p.Algorithm {
+	case KDFPBKDF2SHA256:
+		if p.Iterations < 1 || p.Iterations > maxPBKDF2Iterations {
+			return fmt.Errorf("%w: pbkdf2 iterations out of range: %d", ErrInvalidKDFParams, p.Iterations)
+		}
+	case KDFScrypt:
+		if p.LogN < 1 || p.LogN > maxScryptLogN || p.R < 1 || p.R > maxScryptR || p.P < 1 || p.P > maxScryptP {
+			return fmt.Errorf("%w: scrypt parameters out of range: ln=%d,r=%d,p=%d", ErrInvalidKDFParams, p.LogN, p.R, p.P)
+		}
+		if 128*p.R<<p.LogN > maxScryptMemory {
+			return fmt.Errorf("%w: scrypt memory exceeds %d MiB: ln=%d,r=%d", ErrInvalidKDFParams, maxScryptMemory>>20, p.LogN, p.R)
+		}
+	case KDFArgon2id:
+		if p.Memory < 8*uint32(p.Threads) || p.Memory > maxArgon2Memory || p.Time < 1 || p.Time > maxArgon2Time || p.Threads < 1 || p.Threads > maxArgon2Threads {
+			return fmt.Errorf("%w: argon2id parameters out of range: m=%d,t=%d,p=%d", ErrInvalidKDFParams, p.Memory, p.Time, p.Threads)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidKDFParams, p.Algorithm)
+	}
+	return nil
+}
+
+// DeriveKey 根据参数从口令派生 AES 密钥
+func (p *KDFParams) DeriveKey(password []byte) ([]byte, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	// HMAC 会把短密钥补\0，"abc" 与 "abc\0" 在 PBKDF2/scrypt 下等价，因此直接拒绝含 NUL 的口令
+	if p.Algorithm != KDFLegacyPadding && bytes.IndexByte(password, 0) >= 0 {
+		return nil, ErrInvalidPassword
+	}
+
+	switch p.Algorithm {
+	case KDFLegacyPadding:
+		return PadKey(password), nil
+	case KDFPBKDF2SHA256:
+		return pbkdf2.Key(sha256.New, string(password), p.Salt, p.Iterations, DerivedKeySize)
+	case KDFScrypt:
+		return scrypt.Key(password, p.Salt, 1<<p.LogN, p.R, p.P, DerivedKeySize)
+	case KDFArgon2id:
+		return argon2.IDKey(password, p.Salt, p.Time, p.Memory, p.Threads, DerivedKeySize), nil
+	}
+	return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidKDFParams, p.Algorithm)
+}
+
+// NewCipherFromPassword 使用 KDF 从口令派生密钥并创建 Cipher；p 为 nil 时退回旧版补齐逻辑
+func NewCipherFromPassword(password []byte, p *KDFParams) (*Cipher, error) {
+	key, err := deriveKey(password, p)
+	if err != nil {
+		return nil, err
+	}
+	return NewCipher(key)
+}
+
+// deriveKey 按 KDF 参数从口令派生密钥；p 为 nil 时使用旧版补齐逻辑
+func deriveKey(password []byte, p *KDFParams) ([]byte, error) {
+	if p == nil {
+		return PadKey(password), nil
+	}
+	return p.DeriveKey(password)
+}
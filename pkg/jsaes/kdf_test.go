@@ -0,0 +1,113 @@
+package jsaes
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// testSalt 16 字节盐的无填充 Base64
+const testSalt = "c2FsdHNhbHRzYWx0c2FsdA"
+
+func TestKDFRoundTrip(t *testing.T) {
+	specs := []string{
+		"$pbkdf2-sha256$i=1000$" + testSalt,
+		"$scrypt$ln=10,r=8,p=1$" + testSalt,
+		"$argon2id$m=64,t=1,p=1$" + testSalt,
+		"$legacy$$",
+	}
+	for _, spec := range specs {
+		t.Run(spec, func(t *testing.T) {
+			p, err := ParseKDFParams(spec)
+			if err != nil {
+				t.Fatalf("ParseKDFParams: %v", err)
+			}
+			if got := p.String(); got != spec {
+				t.Errorf("String() = %q, want %q", got, spec)
+			}
+
+			password := []byte("correct horse battery staple")
+			cipherB64, ivB64, err := AESGCMEncryptForJS([]byte("secret"), password, WithKDFSpec(spec))
+			if err != nil {
+				t.Fatalf("AESGCMEncryptForJS: %v", err)
+			}
+			got, err := AESGCMDecryptFromJS(cipherB64, ivB64, password, WithKDFSpec(spec))
+			if err != nil {
+				t.Fatalf("AESGCMDecryptFromJS: %v", err)
+			}
+			if string(got) != "secret" {
+				t.Fatalf("AESGCMDecryptFromJS = %q", got)
+			}
+			if _, err := AESGCMDecryptFromJS(cipherB64, ivB64, []byte("wrong password"), WithKDFSpec(spec)); !errors.Is(err, ErrAuthFailed) {
+				t.Errorf("wrong password: err = %v, want ErrAuthFailed", err)
+			}
+		})
+	}
+}
+
+func TestKDFDefaultsValid(t *testing.T) {
+	for _, alg := range []KDFAlgorithm{KDFPBKDF2SHA256, KDFScrypt, KDFArgon2id} {
+		p, err := NewKDFParams(alg)
+		if err != nil {
+			t.Fatalf("NewKDFParams(%s): %v", alg, err)
+		}
+		q, err := ParseKDFParams(p.String())
+		if err != nil {
+			t.Fatalf("ParseKDFParams(%s): %v", p, err)
+		}
+		fresh, err := q.WithNewSalt()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Equal(fresh.Salt, q.Salt) {
+			t.Errorf("WithNewSalt(%s) reused the salt", alg)
+		}
+	}
+}
+
+func TestParseKDFParamsRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+	}{
+		{"malformed", "pbkdf2-sha256$i=1000$" + testSalt},
+		{"unsupported algorithm", "$bcrypt$i=10$" + testSalt},
+		{"short salt", "$pbkdf2-sha256$i=1000$c2FsdA"},
+		{"legacy with params", "$legacy$i=1$"},
+		{"pbkdf2 zero iterations", "$pbkdf2-sha256$i=0$" + testSalt},
+		{"pbkdf2 too many iterations", "$pbkdf2-sha256$i=10000000$" + testSalt},
+		{"pbkdf2 unknown key", "$pbkdf2-sha256$i=1000,x=1$" + testSalt},
+		{"pbkdf2 duplicate key", "$pbkdf2-sha256$i=1000,i=2000$" + testSalt},
+		{"pbkdf2 empty params", "$pbkdf2-sha256$$" + testSalt},
+		{"scrypt huge r", "$scrypt$ln=17,r=1048576,p=1$" + testSalt},
+		{"scrypt r too large", "$scrypt$ln=10,r=33,p=1$" + testSalt},
+		{"scrypt p too large", "$scrypt$ln=10,r=8,p=17$" + testSalt},
+		{"scrypt memory budget", "$scrypt$ln=17,r=16,p=1$" + testSalt},
+		{"scrypt ln too large", "$scrypt$ln=18,r=1,p=1$" + testSalt},
+		{"scrypt missing key", "$scrypt$ln=10,r=8$" + testSalt},
+		{"scrypt extra key", "$scrypt$ln=10,r=8,p=1,m=1$" + testSalt},
+		{"argon2id memory too large", "$argon2id$m=262144,t=2,p=1$" + testSalt},
+		{"argon2id time too large", "$argon2id$m=64,t=5,p=1$" + testSalt},
+		{"argon2id threads too large", "$argon2id$m=64,t=1,p=5$" + testSalt},
+		{"argon2id negative", "$argon2id$m=-1,t=1,p=1$" + testSalt},
+		{"argon2id scrypt keys", "$argon2id$ln=10,r=8,p=1$" + testSalt},
+		{"non-numeric", "$argon2id$m=lots,t=1,p=1$" + testSalt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseKDFParams(tt.spec); !errors.Is(err, ErrInvalidKDFParams) {
+				t.Errorf("ParseKDFParams(%q): err = %v, want ErrInvalidKDFParams", tt.spec, err)
+			}
+		})
+	}
+}
+
+func TestDeriveKeyRejectsNUL(t *testing.T) {
+	p, err := ParseKDFParams("$pbkdf2-sha256$i=1000$" + testSalt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.DeriveKey([]byte("abc\x00")); !errors.Is(err, ErrInvalidPassword) {
+		t.Errorf("DeriveKey with NUL: err = %v, want ErrInvalidPassword", err)
+	}
+}